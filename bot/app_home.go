@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"log"
+	"sync"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+)
+
+// EnableAppHome publishes an overview of deploys in all channels a user is a member of to the app home tab.
+// The tab is published via api once the user opens it and refreshed whenever a deploy in one of their channels
+// is started, finished, aborted or handed over.
+func (b *Bot) EnableAppHome(api slack.WebAPIClients) {
+	b.appHome = newAppHome(b, api)
+	b.AddDeployEventHandler(b.appHome)
+}
+
+// appHomeMember is a user who has opened the app home
+type appHomeMember struct {
+	TeamID string
+	// Channels is the set of IDs of channels the user is a member of
+	Channels map[string]struct{}
+}
+
+// appHome keeps the app home tab up to date for users who have opened it since the service start.
+type appHome struct {
+	bot     *Bot
+	clients *workspaceClients
+
+	mu      sync.Mutex
+	members map[string]appHomeMember
+}
+
+func newAppHome(b *Bot, api slack.WebAPIClients) *appHome {
+	return &appHome{
+		bot:     b,
+		clients: newWorkspaceClients(api),
+		members: make(map[string]appHomeMember),
+	}
+}
+
+// Open refreshes the list of channels the user is a member of and publishes their app home tab.
+func (h *appHome) Open(teamID, userID string) {
+	api, err := h.clients.WebAPI(teamID)
+	if err != nil {
+		log.Printf("app-home: failed to open the app home for %s: %s", userID, err)
+		return
+	}
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	channelIDs, err := api.ListUserChannelsContext(ctx, userID)
+	if err != nil {
+		log.Printf("app-home: failed to list channels of %s: %s", userID, err)
+		return
+	}
+
+	member := appHomeMember{
+		TeamID:   teamID,
+		Channels: make(map[string]struct{}, len(channelIDs)),
+	}
+	for _, id := range channelIDs {
+		member.Channels[id] = struct{}{}
+	}
+
+	h.mu.Lock()
+	h.members[userID] = member
+	h.mu.Unlock()
+
+	h.publish(api, userID, member)
+}
+
+func (h *appHome) DeployStarted(channelID string, d deploy.Deploy) {
+	h.refresh(d.TeamID, channelID)
+}
+
+func (h *appHome) DeployCompleted(channelID string, d deploy.Deploy) {
+	h.refreshIfIdle(channelID, d)
+}
+
+func (h *appHome) DeployAborted(channelID string, d deploy.Deploy) {
+	h.refreshIfIdle(channelID, d)
+}
+
+func (h *appHome) DeployHandedOver(channelID string, d deploy.Deploy) {
+	h.refresh(d.TeamID, channelID)
+}
+
+// refreshIfIdle publishes the app home tab for members of channel once the deploy d is over, unless the next
+// deploy has already been started, in which case the tab is refreshed on its start.
+func (h *appHome) refreshIfIdle(channelID string, d deploy.Deploy) {
+	if _, ok := h.bot.deploys.Current(deploy.Channel{TeamID: d.TeamID, ID: channelID, Environment: d.Environment}); ok {
+		return
+	}
+
+	h.refresh(d.TeamID, channelID)
+}
+
+// refresh publishes the app home tab for all known members of channel.
+func (h *appHome) refresh(teamID, channelID string) {
+	api, err := h.clients.WebAPI(teamID)
+	if err != nil {
+		log.Printf("app-home: failed to refresh the app home for members of %s: %s", channelID, err)
+		return
+	}
+
+	members := make(map[string]appHomeMember)
+
+	h.mu.Lock()
+	for userID, member := range h.members {
+		if _, ok := member.Channels[channelID]; ok && member.TeamID == teamID {
+			members[userID] = member
+		}
+	}
+	h.mu.Unlock()
+
+	for userID, member := range members {
+		h.publish(api, userID, member)
+	}
+}
+
+func (h *appHome) publish(api *slack.WebAPI, userID string, member appHomeMember) {
+	queues := make(map[deploy.Channel][]deploy.Deploy)
+	for _, ch := range h.bot.deploys.Channels() {
+		if _, ok := member.Channels[ch.ID]; ok && ch.TeamID == member.TeamID {
+			queues[ch] = h.bot.deploys.All(ch)
+		}
+	}
+
+	// the app home does not belong to a channel, so it is shown in the user or workspace language
+	responses := h.bot.userResponses(deploy.Channel{TeamID: member.TeamID}, slack.User{ID: userID})
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	if err := api.PublishViewContext(ctx, userID, responses.AppHomeView(userID, queues)); err != nil {
+		log.Printf("app-home: failed to publish the app home for %s: %s", userID, err)
+	}
+}
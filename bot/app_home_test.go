@@ -0,0 +1,121 @@
+package bot_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot_AppHome(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu    sync.Mutex
+		views = make(map[string][]string)
+	)
+
+	mux.HandleFunc("/users.conversations", func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("user") {
+		case "U3":
+			fmt.Fprint(w, `{"ok":true,"channels":[{"id":"C2"}]}`)
+		default:
+			fmt.Fprint(w, `{"ok":true,"channels":[{"id":"C1"},{"id":"C2"}]}`)
+		}
+	})
+	mux.HandleFunc("/views.publish", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			UserID string     `json:"user_id"`
+			View   slack.View `json:"view"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "home", payload.View.Type)
+
+		var sections []string
+		for _, block := range payload.View.Blocks {
+			if block.Text != nil {
+				sections = append(sections, block.Text.Text)
+			}
+		}
+
+		mu.Lock()
+		views[payload.UserID] = append(views[payload.UserID], strings.Join(sections, "\n"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	b := bot.New(slackToken, "", deploy.NewInMemoryStore())
+	b.EnableAppHome(api)
+
+	command := func(userID, text string) {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	openHome := func(userID string) {
+		body := fmt.Sprintf(`{"token":%q,"type":"event_callback","event_id":"Ev%s","event":{"type":"app_home_opened","user":%q,"tab":"home"}}`, slackToken, userID, userID)
+
+		rec := httptest.NewRecorder()
+		b.ServeEvents(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	openHome("U3")
+	command("U1", "first deploy")
+	command("U2", "second deploy")
+
+	openHome("U2")
+	command("U1", "done")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if assert.Len(t, views["U2"], 2) {
+		assert.Contains(t, views["U2"][0], "*<#C1>*\n<@U1|u1> is deploying first deploy since")
+		assert.Contains(t, views["U2"][0], "1 deploy is waiting in the queue\nYour deploy is at position 1 in the queue")
+
+		assert.Contains(t, views["U2"][1], "*<#C1>*\n<@U2|u2> is deploying second deploy since")
+		assert.Contains(t, views["U2"][1], "0 deploys are waiting in the queue\nYou are deploying now")
+	}
+
+	// U3 is not a member of the channel with deploys
+	if assert.Len(t, views["U3"], 1) {
+		assert.Contains(t, views["U3"][0], "There are no deploys in channels you are a member of")
+	}
+}
@@ -4,13 +4,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/adjust/michaelbot/auth"
 	"github.com/adjust/michaelbot/deploy"
 	"github.com/adjust/michaelbot/github"
+	"github.com/adjust/michaelbot/scheduler"
 	"github.com/adjust/michaelbot/slack"
 )
 
@@ -20,22 +24,56 @@ type DeployEventHandler interface {
 	DeployAborted(channelID string, d deploy.Deploy)
 }
 
+// ChannelLockEventHandler is an optional interface that can be implemented by DeployEventHandler
+// to get notified when deploys in channel are locked and unlocked.
+type ChannelLockEventHandler interface {
+	ChannelLocked(channelID string, l deploy.Lock)
+	ChannelUnlocked(channelID string, l deploy.Lock, deployInProgress bool)
+}
+
+// DeployHandoverEventHandler is an optional interface that can be implemented by DeployEventHandler
+// to get notified when the running deploy is handed over to another user.
+type DeployHandoverEventHandler interface {
+	DeployHandedOver(channelID string, d deploy.Deploy)
+}
+
 type Bot struct {
 	slackToken    string
 	deploys       *deploy.ChannelDeploys
 	responses     *ResponseBuilder
+	locales       *Localizer
 	dashboardAuth auth.TokenIssuer
+	environments  map[string]struct{}
+	users         *workspaceClients
+	expirer       *deployExpirer
+	userSettings  deploy.UserSettingsStore
+	api           *workspaceClients
+	threadsAPI    *workspaceClients
+	modalAPI      *workspaceClients
+	appHome       *appHome
+	events        receivedEvents
+	// separateWorkspaces is set if deploys in each workspace are kept apart
+	separateWorkspaces bool
 
 	deployEventHandlers []DeployEventHandler
 }
 
+// New returns a bot that handles /deploy slash commands. The slackToken is the legacy verification token that is
+// compared with the one sent within each request. Pass an empty string if requests are verified by other means,
+// i.e. wrapped with slack.RequestVerificationMiddleware.
 func New(slackToken, githubToken string, store deploy.Store) *Bot {
-	return &Bot{
+	b := &Bot{
 		slackToken:    slackToken,
 		deploys:       deploy.NewChannelDeploys(store),
 		responses:     NewResponseBuilder(github.NewClient(githubToken, nil)),
+		locales:       NewLocalizer(store),
 		dashboardAuth: auth.None,
 	}
+
+	// personal settings are only available if the store is able to keep them
+	b.userSettings, _ = store.(deploy.UserSettingsStore)
+
+	return b
 }
 
 func (b *Bot) AddDeployEventHandler(h DeployEventHandler) {
@@ -50,13 +88,44 @@ func (b *Bot) SetDashboardAuth(issuer auth.TokenIssuer) {
 	b.dashboardAuth = issuer
 }
 
+// EnableDeployExpiry enforces channel expiry policies for deploys that have been running for too long. The expiry
+// steps are scheduled with sched, while notifications are sent via api.
+func (b *Bot) EnableDeployExpiry(sched *scheduler.Scheduler, api slack.WebAPIClients) {
+	b.expirer = newDeployExpirer(b, sched, api)
+	b.AddDeployEventHandler(b.expirer)
+}
+
+// EnableTeamDirectory makes the bot look up users mentioned by name in commands via api, i.e. in
+// `/deploy handover @user` if Slack does not escape user mentions for the command.
+func (b *Bot) EnableTeamDirectory(api slack.WebAPIClients) {
+	b.users = newWorkspaceClients(api)
+}
+
+// SeparateWorkspaces keeps deploy queues, history and settings of each Slack workspace apart. This is required
+// if the app is installed into multiple workspaces, since the Web API clients are then picked by the team ID of
+// the deploy channel. Note that deploys recorded before enabling this are not shown anymore.
+func (b *Bot) SeparateWorkspaces() {
+	b.separateWorkspaces = true
+}
+
+// SetEnvironments configures the list of environments that can be deployed separately within one channel.
+// Once set, the first word of a command is treated as an environment name if it matches one of envs.
+func (b *Bot) SetEnvironments(envs ...string) {
+	b.environments = make(map[string]struct{}, len(envs))
+	for _, env := range envs {
+		if env = strings.ToLower(strings.TrimSpace(env)); env != "" {
+			b.environments[env] = struct{}{}
+		}
+	}
+}
+
 func (b *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != "POST" {
 		http.Error(w, "Only POST requests are supported", http.StatusBadRequest)
 		return
 	}
 
-	if r.PostFormValue("token") != b.slackToken {
+	if b.slackToken != "" && r.PostFormValue("token") != b.slackToken {
 		http.Error(w, "Invalid token", http.StatusForbidden)
 		return
 	}
@@ -66,120 +135,615 @@ func (b *Bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	channelID := r.PostFormValue("channel_id")
+	ch := b.channel(r.PostFormValue("team_id"), r.PostFormValue("channel_id"))
 	user := slack.User{
 		ID:   r.PostFormValue("user_id"),
 		Name: r.PostFormValue("user_name"),
 	}
 
+	text := strings.TrimSpace(r.PostFormValue("text"))
+
+	// `/deploy` without subject opens the deploy modal if possible, otherwise the help message is shown
+	if ch, subject := b.parseEnvironment(ch, text); subject == "" && b.modalAPI != nil {
+		if triggerID := r.PostFormValue("trigger_id"); triggerID != "" {
+			err := b.openDeployModal(r.Context(), triggerID, ch, user)
+			if err == nil {
+				w.Write(nil)
+				return
+			}
+
+			log.Printf("failed to open deploy modal for %s: %s", user.Name, err)
+		}
+	}
+
+	b.handleCommand(ch, user, text, r.Host, commandResponder{w, r})
+}
+
+// handleCommand executes the deploy command issued by user in channel. The host is used to build links
+// to the deploy history dashboard.
+func (b *Bot) handleCommand(channel deploy.Channel, user slack.User, text, host string, resp responder) {
 	// TODO: make commands case-insensitive
-	subject := strings.TrimSpace(r.PostFormValue("text"))
+	ch, subject := b.parseEnvironment(channel, text)
+
+	// messages addressed to the user follow their language, while announcements are posted in the channel one
+	responses, announcements := b.userResponses(ch, user), b.channelResponses(ch)
 
 	switch {
 	case subject == "help" || subject == "":
-		sendImmediateResponse(w, b.responses.HelpMessage())
+		resp.Respond(responses.HelpMessage())
 	case subject == "status":
-		deploys := b.deploys.All(channelID)
+		deploys := b.deploys.All(ch)
+		freezeRules := b.deploys.FreezeRules(ch)
 
 		if len(deploys) == 0 {
-			sendImmediateResponse(w, b.responses.NoRunningDeploysMessage())
+			resp.Respond(responses.WithFreezeRules(responses.NoRunningDeploysMessage(), freezeRules, time.Now()))
 			return
 		}
 
-		sendImmediateResponse(w, b.responses.DeployStatusMessage(deploys))
+		estimates, _ := b.deploys.EstimateStartTimes(ch)
+		resp.Respond(responses.WithFreezeRules(responses.DeployStatusMessage(deploys, estimates), freezeRules, time.Now()))
 	case subject == "done":
-		d, ok := b.deploys.Finish(channelID)
+		b.finishDeploy(ch, user, resp)
+	case subject == "abort" || strings.HasPrefix(subject, "abort "):
+		var reason string
+		if strings.HasPrefix(subject, "abort ") && len(subject) > len("abort ") {
+			reason = subject[len("abort "):]
+		}
 
+		d, ok := b.deploys.Current(ch)
 		if !ok {
-			sendImmediateResponse(w, b.responses.NoRunningDeploysMessage())
+			resp.Respond(responses.NoRunningDeploysMessage())
 			return
 		}
 
 		if d.User.ID == user.ID {
-			go sendDelayedResponse(w, r, b.responses.DeployDoneAnnouncement(user))
+			b.abortDeploy(ch, reason, user, resp)
 		} else {
-			go sendDelayedResponse(w, r, b.responses.DeployInterruptedAnnouncement(d, user))
+			userLeftQueue := b.deploys.LeaveQueue(ch, user)
+			if userLeftQueue {
+				resp.Respond(responses.UserLeftTheQueueMessage())
+			} else {
+				resp.Respond(responses.NotInTheQueueMessage())
+			}
 		}
+	case subject == "lock" || strings.HasPrefix(subject, "lock:"):
+		// the reason follows a colon to let deploy subjects start with "lock", i.e. /deploy lock service update
+		reason := strings.TrimSpace(strings.TrimPrefix(subject, "lock:"))
 
-		nextDeploy, nextDeployStarted := b.deploys.Current(channelID)
-		if nextDeployStarted {
-			go sendDelayedResponse(w, r, b.responses.DeployAnnouncement(nextDeploy))
+		l, err := b.deploys.Lock(ch, user, reason)
+		if errors.Is(err, deploy.ChannelLockedError) {
+			resp.Respond(responses.ChannelLockedMessage(l))
+			return
+		}
 
-			for _, h := range b.deployEventHandlers {
-				go h.DeployStarted(channelID, nextDeploy)
+		resp.Announce(announcements.ChannelLockedAnnouncement(l))
+		for _, h := range b.deployEventHandlers {
+			if h, ok := h.(ChannelLockEventHandler); ok {
+				go h.ChannelLocked(ch.ID, l)
 			}
-		} else {
-			for _, h := range b.deployEventHandlers {
-				go h.DeployCompleted(channelID, d)
+		}
+	case subject == "unlock":
+		l, ok := b.deploys.Unlock(ch)
+		if !ok {
+			resp.Respond(responses.ChannelNotLockedMessage())
+			return
+		}
+
+		resp.Announce(announcements.ChannelUnlockedAnnouncement(user))
+
+		_, deployInProgress := b.deploys.Current(ch)
+		for _, h := range b.deployEventHandlers {
+			if h, ok := h.(ChannelLockEventHandler); ok {
+				go h.ChannelUnlocked(ch.ID, l, deployInProgress)
 			}
 		}
-	case subject == "abort" || strings.HasPrefix(subject, "abort "):
-		var reason string
-		if strings.HasPrefix(subject, "abort ") && len(subject) > len("abort ") {
-			reason = subject[len("abort "):]
+	case subject == "history":
+		dashboardToken, err := b.dashboardAuth.IssueToken(auth.DefaultTokenLength)
+		if err != nil {
+			resp.Respond(responses.ErrorMessage("history", err))
+			return
+		}
+
+		resp.Respond(responses.DeployHistoryLink(host, ch, dashboardToken))
+	case subject == "freeze" || subject == "freeze list":
+		resp.Respond(responses.FreezeRulesMessage(b.deploys.FreezeRules(ch), time.Now()))
+	case strings.HasPrefix(subject, "freeze add "):
+		rule, err := deploy.ParseFreezeRule(subject[len("freeze add "):])
+		if err != nil {
+			resp.Respond(responses.ErrorMessage("/deploy freeze add", err))
+			return
+		}
+
+		b.deploys.AddFreezeRule(ch, rule)
+
+		resp.Announce(announcements.FreezeRuleAddedAnnouncement(rule, user))
+	case strings.HasPrefix(subject, "freeze remove "):
+		n, err := strconv.Atoi(strings.TrimSpace(subject[len("freeze remove "):]))
+		if err != nil {
+			resp.Respond(responses.NoSuchFreezeRuleMessage(subject[len("freeze remove "):]))
+			return
 		}
 
-		d, ok := b.deploys.Current(channelID)
+		rule, ok := b.deploys.RemoveFreezeRule(ch, n)
 		if !ok {
-			sendImmediateResponse(w, b.responses.NoRunningDeploysMessage())
+			resp.Respond(responses.NoSuchFreezeRuleMessage(strconv.Itoa(n)))
 			return
 		}
 
-		if d.User.ID == user.ID {
-			d, _ := b.deploys.Abort(channelID, reason)
+		resp.Announce(announcements.FreezeRuleRemovedAnnouncement(rule, user))
+	case strings.HasPrefix(subject, "edit "):
+		d, err := b.deploys.Edit(ch, user, slack.EscapeMessage(strings.TrimSpace(subject[len("edit "):])))
+		if errors.Is(err, deploy.NotInQueueError) {
+			resp.Respond(responses.NotInTheQueueMessage())
+			return
+		}
 
-			go sendDelayedResponse(w, r, b.responses.DeployAbortedAnnouncement(reason, user))
+		go func() {
+			b.announceDeployEvent(ch, d, announcements.DeployUpdatedAnnouncement(d), resp)
+			b.updateDeployAnnouncement(ch, d)
+		}()
+	case strings.HasPrefix(subject, "urgent "):
+		d := deploy.New(user, slack.EscapeMessage(strings.TrimSpace(subject[len("urgent "):])))
+		d.Priority = true
+
+		b.startDeploy(ch, d, b.deploys.Start, resp)
+	case strings.HasPrefix(subject, "move "):
+		var refs []deploy.UserReference
+
+		fields := strings.Fields(subject[len("move "):])
+		if len(fields) == 2 {
+			refs = deploy.FindUserReferences(fields[0])
+		}
 
-			nextDeploy, nextDeployStarted := b.deploys.Current(channelID)
-			if nextDeployStarted {
-				go sendDelayedResponse(w, r, b.responses.DeployAnnouncement(nextDeploy))
+		pos, err := strconv.Atoi(fields[len(fields)-1])
+		if len(refs) != 1 || err != nil || pos < 1 {
+			resp.Respond(responses.ErrorMessage("/deploy move", errors.New("usage: `/deploy move @user <position>`")))
+			return
+		}
 
-				for _, h := range b.deployEventHandlers {
-					go h.DeployStarted(channelID, nextDeploy)
-				}
-			} else {
-				for _, h := range b.deployEventHandlers {
-					go h.DeployAborted(channelID, d)
+		d, pos, err := b.deploys.Move(ch, user, refs[0], pos)
+		if errors.Is(err, deploy.NotInQueueError) {
+			resp.Respond(responses.UserHasNoQueuedDeploysMessage(fields[0]))
+			return
+		} else if errors.Is(err, deploy.MoveNotAllowedError) {
+			resp.Respond(responses.MoveNotAllowedMessage(d))
+			return
+		}
+
+		resp.Announce(announcements.DeployMovedAnnouncement(d, pos, user))
+	case strings.HasPrefix(subject, "handover "):
+		refs := deploy.FindUserReferences(strings.TrimSpace(subject[len("handover "):]))
+		if len(refs) != 1 {
+			resp.Respond(responses.ErrorMessage("/deploy handover", errors.New("usage: `/deploy handover @user`")))
+			return
+		}
+
+		newOwner, err := b.lookupUser(ch.TeamID, refs[0])
+		if err != nil {
+			resp.Respond(responses.ErrorMessage("/deploy handover", err))
+			return
+		}
+
+		d, err := b.deploys.HandOver(ch, newOwner)
+		if errors.Is(err, deploy.NoDeployInProgressError) {
+			resp.Respond(responses.NoRunningDeploysMessage())
+			return
+		} else if errors.Is(err, deploy.AlreadyInQueueError) {
+			resp.Respond(responses.UserIsInQeueueMessage(newOwner))
+			return
+		}
+
+		go func() {
+			b.announceDeployEvent(ch, d, announcements.DeployHandedOverAnnouncement(d, user), resp)
+			b.updateDeployAnnouncement(ch, d)
+		}()
+		for _, h := range b.deployEventHandlers {
+			if h, ok := h.(DeployHandoverEventHandler); ok {
+				go h.DeployHandedOver(ch.ID, d)
+			}
+		}
+	case subject == "notifications on" || subject == "notifications off":
+		if b.userSettings == nil {
+			resp.Respond(responses.ErrorMessage("/deploy notifications", errors.New("not supported")))
+			return
+		}
+
+		settings := b.userSettings.GetUserSettings(user.ID)
+		settings.MuteTurnNotifications = subject == "notifications off"
+		b.userSettings.SetUserSettings(user.ID, settings)
+
+		resp.Respond(responses.TurnNotificationsMessage(!settings.MuteTurnNotifications))
+	case subject == "notify" || strings.HasPrefix(subject, "notify "):
+		if b.userSettings == nil {
+			resp.Respond(responses.ErrorMessage("/deploy notify", errors.New("not supported")))
+			return
+		}
+
+		settings := b.userSettings.GetUserSettings(user.ID)
+		if subject == "notify" {
+			resp.Respond(responses.NotifyOnMessage(settings.NotifyOn(ch)))
+			return
+		}
+
+		notifyOn, err := deploy.ParseNotifyOn(subject[len("notify "):])
+		if err != nil {
+			resp.Respond(responses.ErrorMessage("/deploy notify", err))
+			return
+		}
+
+		settings.SetNotifyOn(ch, notifyOn)
+		b.userSettings.SetUserSettings(user.ID, settings)
+
+		resp.Respond(responses.NotifyOnMessage(notifyOn))
+	case subject == "subscribe" || strings.HasPrefix(subject, "subscribe "),
+		subject == "unsubscribe" || strings.HasPrefix(subject, "unsubscribe "):
+		fields := strings.SplitN(subject, " ", 2)
+
+		// users may subscribe to a channel they are not a member of, i.e. /deploy subscribe #payments
+		target := deploy.Channel{TeamID: ch.TeamID, ID: ch.ID}
+		if len(fields) > 1 {
+			refs := deploy.FindChannelReferences(strings.TrimSpace(fields[1]))
+			if len(refs) != 1 {
+				resp.Respond(responses.ErrorMessage("/deploy "+fields[0], fmt.Errorf("usage: `/deploy %s [#channel]`", fields[0])))
+				return
+			}
+
+			target.ID = refs[0].ID
+		}
+
+		subscribed := fields[0] == "subscribe"
+		if subscribed {
+			if target.ID != ch.ID {
+				if err := b.checkChannelAccess(target, user); err != nil {
+					resp.Respond(responses.ErrorMessage("/deploy subscribe", err))
+					return
 				}
 			}
 
+			b.deploys.Subscribe(target, user)
 		} else {
-			userLeftQueue := b.deploys.LeaveQueue(channelID, user)
-			if userLeftQueue {
-				sendImmediateResponse(w, b.responses.UserLeftTheQueueMessage())
-			} else {
-				sendImmediateResponse(w, b.responses.NotInTheQueueMessage())
-			}
+			b.deploys.Unsubscribe(target, user)
 		}
-	case subject == "history":
-		dashboardToken, err := b.dashboardAuth.IssueToken(auth.DefaultTokenLength)
+
+		resp.Respond(responses.SubscriptionMessage(target.ID, subscribed))
+	case subject == "locale":
+		resp.Respond(responses.LocaleMessage(b.locales.ChannelLocale(ch)))
+	case strings.HasPrefix(subject, "locale workspace "):
+		locale, err := ParseLocale(subject[len("locale workspace "):])
+		if err == nil {
+			err = b.locales.SetWorkspaceLocale(ch.TeamID, locale)
+		}
+
 		if err != nil {
-			sendImmediateResponse(w, b.responses.ErrorMessage("history", err))
+			resp.Respond(responses.ErrorMessage("/deploy locale workspace", err))
 			return
 		}
 
-		sendImmediateResponse(w, b.responses.DeployHistoryLink(r.Host, channelID, dashboardToken))
-	default:
-		d, err := b.deploys.Start(channelID, deploy.New(user, slack.EscapeMessage(subject)))
-		if errors.Is(err, deploy.DeployInProgressError) {
-			sendImmediateResponse(w, b.responses.DeployInProgressMessage(d))
+		resp.Respond(b.responses.WithLocale(locale).WorkspaceLocaleChangedMessage(locale))
+	case strings.HasPrefix(subject, "locale "):
+		var locale string
+		if arg := strings.TrimSpace(subject[len("locale "):]); arg != "default" {
+			var err error
+			if locale, err = ParseLocale(arg); err != nil {
+				resp.Respond(responses.ErrorMessage("/deploy locale", err))
+				return
+			}
+		}
+
+		b.locales.SetChannelLocale(ch, locale)
+
+		// announce the change in the new channel language
+		locale = b.locales.ChannelLocale(ch)
+		resp.Announce(b.responses.WithLocale(locale).LocaleChangedAnnouncement(locale, user))
+	case subject == "expiry":
+		policy, ok := b.deploys.ExpiryPolicy(ch)
+		if !ok {
+			resp.Respond(responses.NoExpiryPolicyMessage())
 			return
-		} else if errors.Is(err, deploy.AlreadyInQueueError) {
-			sendImmediateResponse(w, b.responses.UserIsInQeueueMessage(d.User))
+		}
+
+		resp.Respond(responses.ExpiryPolicyMessage(policy))
+	case strings.HasPrefix(subject, "expiry "):
+		var policy *deploy.ExpiryPolicy
+		if arg := strings.TrimSpace(subject[len("expiry "):]); arg != "off" {
+			p, err := deploy.ParseExpiryPolicy(arg)
+			if err != nil {
+				resp.Respond(responses.ErrorMessage("/deploy expiry", err))
+				return
+			}
+
+			policy = &p
+		}
+
+		b.deploys.SetExpiryPolicy(ch, policy)
+
+		resp.Announce(announcements.ExpiryPolicyChangedAnnouncement(policy, user))
+
+		if b.expirer != nil {
+			for _, ch := range b.channelEnvironments(channel) {
+				b.expirer.Reschedule(ch)
+			}
+		}
+	case strings.HasPrefix(subject, "force "):
+		// overriding a deploy freeze requires a reason, i.e. /deploy force payments are down: hotfix
+		arg := strings.TrimSpace(subject[len("force "):])
+
+		i := strings.Index(arg, ": ")
+		if i <= 0 || strings.TrimSpace(arg[i+1:]) == "" {
+			resp.Respond(responses.ErrorMessage("/deploy force", errors.New("usage: `/deploy force <reason>: <subject>`")))
 			return
-		} else if err != nil {
-			log.Printf("failed to start a deploy: (%s)", err)
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+
+		reason, forcedSubject := strings.TrimSpace(arg[:i]), strings.TrimSpace(arg[i+1:])
+		b.startDeploy(ch, deploy.New(user, slack.EscapeMessage(forcedSubject)), func(ch deploy.Channel, d deploy.Deploy) (deploy.Deploy, error) {
+			return b.deploys.ForceStart(ch, d, reason)
+		}, resp)
+	default:
+		b.startDeploy(ch, deploy.New(user, slack.EscapeMessage(subject)), b.deploys.Start, resp)
+	}
+}
+
+func (b *Bot) startDeploy(ch deploy.Channel, newDeploy deploy.Deploy, start func(deploy.Channel, deploy.Deploy) (deploy.Deploy, error), resp responder) {
+	responses, announcements := b.userResponses(ch, newDeploy.User), b.channelResponses(ch)
+
+	d, err := start(ch, newDeploy)
+	if errors.Is(err, deploy.DeployInProgressError) {
+		if newDeploy.Priority {
+			resp.Announce(announcements.UrgentDeployQueuedAnnouncement(newDeploy, d))
 			return
 		}
 
-		w.Write(nil)
+		var (
+			pos      int
+			estimate time.Time
+		)
 
-		go sendDelayedResponse(w, r, b.responses.DeployAnnouncement(d))
-		for _, h := range b.deployEventHandlers {
-			go h.DeployStarted(channelID, d)
+		for i, queued := range b.deploys.All(ch) {
+			if queued.User.ID == newDeploy.User.ID {
+				pos = i
+				break
+			}
+		}
+
+		if estimates, ok := b.deploys.EstimateStartTimes(ch); ok && pos < len(estimates) {
+			estimate = estimates[pos]
+		}
+
+		resp.Respond(responses.DeployInProgressMessage(d, pos, estimate))
+		return
+	} else if errors.Is(err, deploy.AlreadyInQueueError) {
+		resp.Respond(responses.DeployAlreadyScheduledMessage())
+		return
+	} else if errors.Is(err, deploy.ChannelLockedError) {
+		l, _ := b.deploys.Locked(ch)
+		resp.Respond(responses.ChannelLockedMessage(l))
+		return
+	} else if errors.Is(err, deploy.DeployFrozenError) {
+		rule, _ := b.deploys.ActiveFreeze(ch, time.Now())
+		resp.Respond(responses.DeployFrozenMessage(rule))
+		return
+	} else if err != nil {
+		log.Printf("failed to start a deploy: (%s)", err)
+		resp.Respond(responses.ErrorMessage("/deploy", errors.New("internal server error")))
+		return
+	}
+
+	go b.announceDeployStart(ch, d, resp)
+	for _, h := range b.deployEventHandlers {
+		go h.DeployStarted(ch.ID, d)
+	}
+}
+
+// finishDeploy completes the running deploy on behalf of user and starts the next one in the queue.
+func (b *Bot) finishDeploy(ch deploy.Channel, user slack.User, resp responder) {
+	responses, announcements := b.userResponses(ch, user), b.channelResponses(ch)
+
+	d, ok := b.deploys.Finish(ch)
+	if !ok {
+		resp.Respond(responses.NoRunningDeploysMessage())
+		return
+	}
+
+	// announcements are posted in the background to acknowledge the command in time, but in the order of events
+	go func() {
+		if d.User.ID == user.ID {
+			b.announceDeployEvent(ch, d, announcements.DeployDoneAnnouncement(user), resp)
+		} else {
+			b.announceDeployEvent(ch, d, announcements.DeployInterruptedAnnouncement(d, user), resp)
+		}
+		b.updateDeployAnnouncement(ch, d)
+		b.startNextDeploy(ch, d, resp)
+	}()
+}
+
+// abortDeploy aborts the running deploy on behalf of user and starts the next one in the queue.
+func (b *Bot) abortDeploy(ch deploy.Channel, reason string, user slack.User, resp responder) {
+	responses, announcements := b.userResponses(ch, user), b.channelResponses(ch)
+
+	d, ok := b.deploys.Abort(ch, reason)
+	if !ok {
+		resp.Respond(responses.NoRunningDeploysMessage())
+		return
+	}
+
+	go func() {
+		b.announceDeployEvent(ch, d, announcements.DeployAbortedAnnouncement(reason, user), resp)
+		b.updateDeployAnnouncement(ch, d)
+		b.startNextDeploy(ch, d, resp)
+	}()
+}
+
+// startNextDeploy lets event handlers know that the deploy d is over and announces the next deploy in the queue,
+// if there is one. Each handler receives the end of d before the start of the next deploy, so that the state
+// kept for d, such as reminders, is cleaned up even if the queue is not empty.
+func (b *Bot) startNextDeploy(ch deploy.Channel, d deploy.Deploy, resp responder) {
+	nextDeploy, nextDeployStarted := b.deploys.Current(ch)
+	if nextDeployStarted {
+		b.announceDeployStart(ch, nextDeploy, resp)
+
+		// queued deploys start one after another, so the next one may have been waiting since before the freeze
+		if rule, frozen := b.deploys.ActiveFreeze(ch, time.Now()); frozen && nextDeploy.FreezeOverride == nil {
+			if d, ok := b.deploys.Current(ch); ok && d.User.ID == nextDeploy.User.ID && d.StartedAt.Equal(nextDeploy.StartedAt) {
+				nextDeploy = d
+			}
+
+			b.announceDeployEvent(ch, nextDeploy, b.channelResponses(ch).QueuedDeployFrozenAnnouncement(nextDeploy, rule), resp)
+		}
+	}
+
+	for _, h := range b.deployEventHandlers {
+		go func(h DeployEventHandler) {
+			if d.Aborted {
+				h.DeployAborted(ch.ID, d)
+			} else {
+				h.DeployCompleted(ch.ID, d)
+			}
+
+			if nextDeployStarted {
+				h.DeployStarted(ch.ID, nextDeploy)
+			}
+		}(h)
+	}
+}
+
+// checkChannelAccess returns an error unless user is allowed to see deploys in channel, i.e. the channel is public
+// or the user is one of its members. Access cannot be checked if the team directory has not been enabled.
+func (b *Bot) checkChannelAccess(ch deploy.Channel, user slack.User) error {
+	if b.users == nil {
+		return errors.New("cannot check access to other channels, please run this command in the channel itself")
+	}
+
+	api, err := b.users.WebAPI(ch.TeamID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	private, err := api.IsPrivateChannelContext(ctx, ch.ID)
+	if err != nil {
+		return fmt.Errorf("cannot access <#%s>: %s", ch.ID, err)
+	}
+
+	if !private {
+		return nil
+	}
+
+	channelIDs, err := api.ListUserChannelsContext(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range channelIDs {
+		if id == ch.ID {
+			return nil
 		}
 	}
+
+	return fmt.Errorf("<#%s> is a private channel you are not a member of", ch.ID)
+}
+
+// lookupUser returns the Slack user the reference points to. References without user ID are resolved using
+// the directory of team if it has been enabled.
+func (b *Bot) lookupUser(teamID string, ref deploy.UserReference) (slack.User, error) {
+	if ref.IsGroup() {
+		return slack.User{}, fmt.Errorf("@%s is a user group, please mention a single user", ref.Name)
+	}
+
+	if ref.ID != "" {
+		return slack.User{ID: ref.ID, Name: ref.Name}, nil
+	}
+
+	if b.users == nil {
+		return slack.User{}, fmt.Errorf("cannot find @%s, please make sure that the command escapes user mentions", ref.Name)
+	}
+
+	dir, err := b.users.TeamDirectory(teamID)
+	if err != nil {
+		return slack.User{}, err
+	}
+
+	return dir.Fetch(ref.Name)
+}
+
+// channelResponses returns the response builder for messages posted in channel.
+func (b *Bot) channelResponses(ch deploy.Channel) *ResponseBuilder {
+	return b.responses.WithLocale(b.locales.ChannelLocale(ch))
+}
+
+// userResponses returns the response builder for messages addressed to user in channel. The language the user
+// has chosen in Slack is looked up in the team directory if it has been enabled.
+func (b *Bot) userResponses(ch deploy.Channel, user slack.User) *ResponseBuilder {
+	return b.responses.WithLocale(b.locales.UserLocale(ch, withSlackLocale(b.users, ch.TeamID, user)))
+}
+
+// channel returns the default environment of a Slack channel.
+func (b *Bot) channel(teamID, channelID string) deploy.Channel {
+	return deploy.Channel{TeamID: b.workspace(teamID), ID: channelID}
+}
+
+// workspace returns the team ID if workspaces are separated and an empty string otherwise.
+func (b *Bot) workspace(teamID string) string {
+	if !b.separateWorkspaces {
+		return ""
+	}
+
+	return teamID
+}
+
+// channelEnvironments returns the list of all deploy queues in channel.
+func (b *Bot) channelEnvironments(channel deploy.Channel) []deploy.Channel {
+	chs := []deploy.Channel{channel}
+	for env := range b.environments {
+		ch := channel
+		ch.Environment = env
+
+		chs = append(chs, ch)
+	}
+
+	return chs
+}
+
+// parseEnvironment splits the environment name off the command text if there is one.
+func (b *Bot) parseEnvironment(channel deploy.Channel, text string) (deploy.Channel, string) {
+	ch := channel
+
+	fields := strings.SplitN(text, " ", 2)
+	if _, ok := b.environments[strings.ToLower(fields[0])]; !ok {
+		return ch, text
+	}
+
+	ch.Environment = strings.ToLower(fields[0])
+	if len(fields) == 1 {
+		return ch, ""
+	}
+
+	return ch, strings.TrimSpace(fields[1])
+}
+
+// responder sends the outcome of a command back to Slack.
+type responder interface {
+	// Respond sends a message visible only to the user who has issued the command
+	Respond(response *slack.Response)
+	// Announce posts a message in channel
+	Announce(response *slack.Response)
+}
+
+// commandResponder responds to a slash command. User messages are sent within the HTTP response, while announcements
+// are posted to the command response_url.
+type commandResponder struct {
+	w http.ResponseWriter
+	r *http.Request
+}
+
+func (resp commandResponder) Respond(response *slack.Response) {
+	sendImmediateResponse(resp.w, response)
+}
+
+// Announce may be called after the command has been acknowledged, so the response writer is not used here.
+func (resp commandResponder) Announce(response *slack.Response) {
+	go postResponse(resp.r.PostFormValue("response_url"), response)
 }
 
 func sendImmediateResponse(w http.ResponseWriter, response *slack.Response) {
@@ -194,8 +758,7 @@ func sendImmediateResponse(w http.ResponseWriter, response *slack.Response) {
 	w.Write(body)
 }
 
-func sendDelayedResponse(w http.ResponseWriter, req *http.Request, response *slack.Response) {
-	responseURL := req.PostFormValue("response_url")
+func postResponse(responseURL string, response *slack.Response) {
 	if responseURL == "" {
 		log.Printf("cannot send delayed response to a without without response_url")
 		return
@@ -0,0 +1,188 @@
+package bot_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot_ForceDeploy(t *testing.T) {
+	const slackToken = "slack-token"
+
+	b := bot.New(slackToken, "", deploy.NewInMemoryStore())
+
+	command := func(userID, text string) string {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp slack.Response
+		if rec.Body.Len() > 0 {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		}
+
+		return resp.Text
+	}
+
+	now := time.Now().UTC()
+	command("U1", "freeze add "+now.AddDate(0, 0, -1).Format("2006-01-02")+" - "+now.AddDate(0, 0, 2).Format("2006-01-02"))
+
+	assert.Contains(t, command("U1", "hotfix"), "/deploy force <reason>: <subject>")
+
+	// the reason is required to override the freeze
+	assert.Contains(t, command("U1", "force hotfix"), "usage")
+	assert.Contains(t, command("U1", "force : hotfix"), "usage")
+	assert.Contains(t, command("U1", "force payments are down:"), "usage")
+
+	assert.Empty(t, command("U1", "force payments are <down>: hotfix https://github.com/adjust/michaelbot/pull/1"))
+
+	status := command("U2", "status")
+	assert.Contains(t, status, "hotfix https://github.com/adjust/michaelbot/pull/1 :warning: ignoring the deploy freeze (")
+	assert.Contains(t, status, "), reason: payments are &lt;down&gt;")
+}
+
+func TestBot_Lock(t *testing.T) {
+	const slackToken = "slack-token"
+
+	b := bot.New(slackToken, "", deploy.NewInMemoryStore())
+
+	command := func(userID, text string) string {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp slack.Response
+		if rec.Body.Len() > 0 {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		}
+
+		return resp.Text
+	}
+
+	// subjects starting with "lock" are deploys
+	command("U1", "lock service update")
+	assert.Contains(t, command("U2", "status"), "<@U1|u1> is deploying lock service update")
+	command("U1", "done")
+
+	command("U1", "lock: release in progress")
+	assert.Contains(t, command("U2", "hotfix"), "(release in progress)")
+
+	command("U1", "unlock")
+	command("U1", "lock")
+	assert.Contains(t, command("U2", "hotfix"), "Deploys in this channel have been locked by <@U1|u1>")
+}
+
+func TestBot_FreezeQueuedDeploy(t *testing.T) {
+	const slackToken = "slack-token"
+
+	var (
+		mu            sync.Mutex
+		announcements []string
+	)
+
+	responseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp struct {
+			Text string `json:"text"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&resp))
+
+		mu.Lock()
+		announcements = append(announcements, resp.Text)
+		mu.Unlock()
+	}))
+	defer responseServer.Close()
+
+	b := bot.New(slackToken, "", deploy.NewInMemoryStore())
+
+	command := func(userID, text string) {
+		form := url.Values{
+			"token":        {slackToken},
+			"command":      {"/deploy"},
+			"channel_id":   {"C1"},
+			"user_id":      {userID},
+			"user_name":    {strings.ToLower(userID)},
+			"text":         {text},
+			"response_url": {responseServer.URL},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	flushAnnouncements := func() []string {
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		as := announcements
+		announcements = nil
+
+		return as
+	}
+
+	command("U1", "first deploy")
+	command("U2", "second deploy")
+
+	now := time.Now().UTC()
+	command("U1", "freeze add "+now.AddDate(0, 0, -1).Format("2006-01-02")+" - "+now.AddDate(0, 0, 2).Format("2006-01-02"))
+	command("U3", "force hotfix needed: third deploy")
+	flushAnnouncements()
+
+	// the owner of a deploy queued before the freeze is warned once it starts
+	command("U1", "done")
+	if as := flushAnnouncements(); assert.Len(t, as, 3) {
+		sort.Strings(as)
+		assert.Contains(t, as[0], ":warning: <@U2|u2>, your deploy has been started from the queue during the deploy freeze")
+		assert.Equal(t, "<@U1|u1> done deploying", as[1])
+		assert.Equal(t, "<@U2|u2> is about to deploy second deploy", as[2])
+	}
+
+	// forced deploys start without a warning
+	command("U2", "done")
+	if as := flushAnnouncements(); assert.Len(t, as, 2) {
+		sort.Strings(as)
+		assert.Equal(t, "<@U2|u2> done deploying", as[0])
+		assert.Contains(t, as[1], "<@U3|u3> is about to deploy third deploy")
+	}
+}
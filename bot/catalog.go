@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is the language bot messages are written in. It is used unless another supported language
+// has been chosen for the workspace or channel, or by the user in Slack.
+const DefaultLocale = "en"
+
+// messageCatalog holds the translations of bot messages into a language. Messages are looked up by their English
+// format string, so that the source text stays next to the code that uses it. Translations may reorder the
+// arguments with explicit indexes, i.e. %[2]s. Messages without a translation are sent in English.
+type messageCatalog struct {
+	// Name is the name of the language in this language, i.e. Deutsch
+	Name     string
+	Messages map[string]string
+	// Plurals maps the English singular form of a message to its translated plural forms
+	Plurals map[string][]string
+	// PluralForm returns the index of the plural form to use for n
+	PluralForm func(n int) int
+}
+
+var catalogs = map[string]messageCatalog{
+	DefaultLocale: {Name: "English", PluralForm: oneOtherPluralForm},
+	"de":          germanCatalog,
+	"es":          spanishCatalog,
+}
+
+// Locales returns the list of supported locales.
+func Locales() []string {
+	locales := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// ParseLocale returns the supported locale matching the language tag, i.e. de for de-DE as reported by Slack.
+func ParseLocale(s string) (string, error) {
+	locale := strings.ToLower(strings.TrimSpace(s))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+
+	if _, ok := catalogs[locale]; !ok {
+		return "", fmt.Errorf("unsupported language %q, expected one of %s", s, strings.Join(Locales(), ", "))
+	}
+
+	return locale, nil
+}
+
+// localeName returns the name of the language to be shown to users.
+func localeName(locale string) string {
+	if c, ok := catalogs[locale]; ok {
+		return c.Name
+	}
+
+	return locale
+}
+
+// translate returns the translation of an English message into locale.
+func translate(locale, msg string) string {
+	if s, ok := catalogs[locale].Messages[msg]; ok {
+		return s
+	}
+
+	return msg
+}
+
+// translatePlural returns the form of message that matches n in locale. The English forms are used
+// if there is no translation.
+func translatePlural(locale string, n int, one, other string) string {
+	if c, ok := catalogs[locale]; ok && len(c.Plurals[one]) > 0 {
+		forms := c.Plurals[one]
+		if i := c.PluralForm(n); i < len(forms) {
+			return forms[i]
+		}
+
+		return forms[len(forms)-1]
+	}
+
+	if oneOtherPluralForm(n) == 0 {
+		return one
+	}
+
+	return other
+}
+
+// oneOtherPluralForm is the plural rule for languages that only distinguish between one and other
+// quantities, such as English, German and Spanish.
+func oneOtherPluralForm(n int) int {
+	if n == 1 {
+		return 0
+	}
+
+	return 1
+}
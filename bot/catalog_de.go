@@ -0,0 +1,167 @@
+package bot
+
+var germanCatalog = messageCatalog{
+	Name:       "Deutsch",
+	PluralForm: oneOtherPluralForm,
+	Messages: map[string]string{
+		helpMessage: `Verfügbare Befehle:
+
+/deploy help — Hilfe anzeigen (diese Nachricht)
+/deploy — einen Dialog zum Starten eines Deploys öffnen, falls verfügbar
+/deploy <subject> — den Deploy von <subject> im Channel ankündigen
+/deploy edit <subject> — den Betreff deines laufenden oder geplanten Deploys ändern
+/deploy status — den Deploy-Status im Channel anzeigen
+/deploy done — den Deploy beenden
+/deploy abort [<reason>] — den aktuellen Deploy abbrechen, optional mit Begründung
+/deploy history — einen Link zum Deploy-Verlauf dieses Channels erhalten
+/deploy lock[: <reason>] — neue Deploys in diesem Channel verhindern, optional mit Begründung, z. B. /deploy lock: Release läuft
+/deploy unlock — Deploys in diesem Channel wieder erlauben
+/deploy freeze — wiederkehrende Deploy-Sperrzeiten in diesem Channel auflisten
+/deploy freeze add <rule> — eine Deploy-Sperrzeit hinzufügen, z. B. Fri 15:00 - Mon 08:00 Europe/Berlin oder 2016-12-24 - 2017-01-02 Europe/Berlin
+/deploy freeze remove <number> — eine Deploy-Sperrzeit entfernen
+/deploy urgent <subject> — den Deploy von <subject> im Channel ankündigen und direkt nach dem laufenden einreihen
+/deploy move @user <position> — die Position des Deploys eines Nutzers in der Warteschlange ändern, erlaubt für den Besitzer und den, der gerade deployt
+/deploy handover @user — einen anderen Nutzer zum Verantwortlichen des laufenden Deploys machen
+/deploy notifications on|off — Direktnachrichten beim Start deines wartenden Deploys ein- oder ausschalten
+/deploy notify done|abort|both — wählen, ob du eine Direktnachricht erhältst, wenn ein Deploy, in dem du erwähnt wirst, beendet, abgebrochen oder beides wird
+/deploy subscribe [#channel] — eine Direktnachricht erhalten, sobald in diesem oder einem anderen Channel ein Deploy gestartet, beendet oder abgebrochen wird
+/deploy unsubscribe [#channel] — keine Direktnachrichten mehr über Deploys im Channel erhalten
+/deploy locale — die Sprache der Bot-Nachrichten in diesem Channel anzeigen
+/deploy locale <language>|default — die Sprache der Bot-Nachrichten in diesem Channel ändern, z. B. de, oder auf die des Workspaces zurücksetzen
+/deploy locale workspace <language> — die Sprache der Bot-Nachrichten in allen Channels dieses Workspaces ändern
+/deploy expiry — anzeigen, was mit zu lange laufenden Deploys passiert
+/deploy expiry <warn> <announce> <abort> — den Verantwortlichen erinnern, im Channel posten und Deploys nach der angegebenen Zeit abbrechen, z. B. 2h 4h 8h, mit - wird ein Schritt übersprungen
+/deploy expiry off — den Ablauf von Deploys deaktivieren
+/deploy force <reason>: <subject> — den Deploy von <subject> trotz aktiver Deploy-Sperrzeit im Channel ankündigen, mit Begründung
+
+Sind mehrere Umgebungen konfiguriert, stelle einem Befehl den Namen der Umgebung voran, um sie getrennt zu deployen,
+z. B. /deploy staging <subject> oder /deploy staging done
+
+Subjects, die mit abort, edit, urgent, move, handover, freeze, notify, subscribe, unsubscribe, locale, expiry oder force beginnen,
+werden als Befehle verstanden, daher startet /deploy move to k8s keinen Deploy. Formuliere solche Subjects um, z. B. /deploy k8s move`,
+		errorMessage:                   "`%s` hat einen Fehler zurückgegeben: %s",
+		noRunningDeploysMessage:        "Zurzeit deployt niemand",
+		singleDeployStatusMessage:      "%s deployt %s seit %s. Es sind noch keine weiteren Deploys geplant.",
+		deployQueueStatusMessage:       "%s deployt %s seit %s. Die Warteschlange:\n %s",
+		environmentDeployMessage:       "%s nach %s",
+		alreadyInQueueMessage:          "%s ist bereits in der Warteschlange",
+		alreadyScheduledMessage:        "Du hast bereits einen Deploy in diesem Channel. Gib `/deploy edit <subject>` ein, wenn du seinen Betreff ändern möchtest.",
+		deployConflictMessage:          "%s deployt seit %s, dein PR wurde zur Warteschlange hinzugefügt%s. Gib `/deploy done` ein, wenn du denkst, dass der aktuelle Deploy beendet ist, oder `/deploy status`, um die Warteschlange anzuzeigen.",
+		queuePositionMessage:           " an Position %d",
+		queueEstimateMessage:           " — beginnt voraussichtlich in etwa %s",
+		queueEstimateNowMessage:        " — beginnt voraussichtlich jeden Moment",
+		deployDoneMessage:              "%s ist mit dem Deploy fertig",
+		deployInterruptedMessage:       "%s hat den von %s gestarteten Deploy beendet",
+		deployAnnouncementMessage:      "%s deployt gleich %s",
+		deployHistoryLinkMessage:       "Klicke <http://%s/%s|hier>, um den Deploy-Verlauf dieses Channels zu sehen",
+		deployAbortedMessage:           "%s hat den Deploy abgebrochen",
+		deployAbortedWithReasonMessage: "%s hat den Deploy abgebrochen (%s)",
+		userLeftQueueMessage:           "Dein geplanter Deploy wurde abgesagt",
+		userIsNotInQueueMessage:        "Du bist nicht in der Warteschlange",
+		channelLockedMessage:           "Deploys in diesem Channel wurden von %s seit %s gesperrt. Führe `/deploy unlock` aus, sobald wieder sicher deployt werden kann.",
+		channelLockedWithReasonMessage: "Deploys in diesem Channel wurden von %s seit %s gesperrt (%s). Führe `/deploy unlock` aus, sobald wieder sicher deployt werden kann.",
+		channelNotLockedMessage:        "Deploys in diesem Channel sind nicht gesperrt",
+		channelLockedAnnouncement:      "%s hat Deploys in diesem Channel gesperrt",
+		channelLockedWithReason:        "%s hat Deploys in diesem Channel gesperrt (%s)",
+		channelUnlockedAnnouncement:    "%s hat Deploys in diesem Channel entsperrt",
+		deployFrozenMessage:            "Deploys in diesem Channel sind eingefroren (%s). Gib `/deploy force <reason>: <subject>` ein, wenn du wirklich jetzt deployen musst.",
+		freezeOverrideMessage:          " :warning: die Deploy-Sperrzeit wird ignoriert (%s)",
+		freezeRulesMessage:             "Deploy-Sperrzeiten in diesem Channel:\n%s",
+		noFreezeRulesMessage:           "In diesem Channel gibt es keine Deploy-Sperrzeiten",
+		freezeRuleMessage:              "%d. %s",
+		activeFreezeRuleMessage:        "%d. %s (aktiv)",
+		freezeRuleAddedMessage:         "%s hat die Deploy-Sperrzeit %s hinzugefügt",
+		freezeRuleRemovedMessage:       "%s hat die Deploy-Sperrzeit %s entfernt",
+		noSuchFreezeRuleMessage:        "Es gibt keine Deploy-Sperrzeit #%s, gib `/deploy freeze` ein, um die Liste zu sehen",
+		urgentDeployStatusMarker:       " :rotating_light: dringend",
+		urgentDeployQueuedMessage:      "%s hat einen dringenden Deploy von %s direkt nach dem aktuellen Deploy von %s eingereiht",
+		deployMovedMessage:             "%s hat den Deploy von %s an Position %d der Warteschlange verschoben",
+		userHasNoQueuedDeploysMessage:  "%s hat keine wartenden Deploys in der Warteschlange",
+		deployUpdatedMessage:           "%s hat den Betreff des Deploys geändert: %s",
+		scheduledDeployUpdatedMessage:  "%s hat den Betreff des geplanten Deploys geändert: %s",
+		turnNotificationsOnMessage:     "Du erhältst eine Direktnachricht, wenn dein wartender Deploy beginnt",
+		turnNotificationsOffMessage:    "Du erhältst keine Direktnachrichten mehr, wenn dein wartender Deploy beginnt",
+		notifyOnDoneMessage:            "Du erhältst eine Direktnachricht, wenn ein Deploy in diesem Channel, in dem du erwähnt wirst, beendet wird",
+		notifyOnAbortMessage:           "Du erhältst eine Direktnachricht, wenn ein Deploy in diesem Channel, in dem du erwähnt wirst, abgebrochen wird",
+		notifyOnBothMessage:            "Du erhältst eine Direktnachricht, wenn ein Deploy in diesem Channel, in dem du erwähnt wirst, beendet oder abgebrochen wird",
+		subscribedMessage:              "Du erhältst eine Direktnachricht, sobald in <#%s> ein Deploy gestartet, beendet oder abgebrochen wird",
+		unsubscribedMessage:            "Du erhältst keine Direktnachrichten mehr über Deploys in <#%s>",
+		expiryPolicyMessage:            "Ablaufregel für Deploys in diesem Channel: %s",
+		noExpiryPolicyMessage:          "Deploys in diesem Channel laufen nie ab. Gib `/deploy expiry <warn> <announce> <abort>` ein, z. B. `/deploy expiry 2h 4h 8h`, um das zu ändern.",
+		expiryPolicyChangedMessage:     "%s hat die Ablaufregel für Deploys geändert: %s",
+		expiryPolicyDisabledMessage:    "%s hat den Ablauf von Deploys deaktiviert",
+		deployExpiryWarningMessage:     "Dein Deploy %s in <#%s> wurde vor %s gestartet. Deployst du noch?",
+		deployExpiryAnnouncement:       "%s deployt %s bereits seit %s. Gib `/deploy done` ein, wenn der Deploy beendet ist.",
+		deployAutoAbortMessage:         " Der Deploy wird in %s automatisch abgebrochen.",
+		deployExpiredMessage:           "Der Deploy von %s durch %s ist abgelaufen und wurde abgebrochen",
+		deployHandedOverMessage:        "%s hat den Deploy von %s an %s übergeben",
+		deployTakenOverMessage:         "%[1]s hat den von %[3]s gestarteten Deploy von %[2]s an %[4]s übergeben",
+		deployFinishedSummary:          ":white_check_mark: %s hat %s in %s deployt",
+		deployAbortedSummary:           ":x: Der Deploy von %s durch %s wurde nach %s abgebrochen",
+		deployAbortedWithReasonSummary: ":x: Der Deploy von %s durch %s wurde nach %s abgebrochen (%s)",
+		expectedDurationMessage:        " (dauert voraussichtlich %s)",
+		deployIsOverMessage:            "Dieser Deploy ist bereits vorbei. Gib `/deploy status` ein, um zu sehen, wer gerade deployt.",
+		notDeployOwnerMessage:          "Nur %s kann diesen Deploy per Button beenden. Gib `/deploy done` ein, wenn du denkst, dass der Deploy beendet ist.",
+		joinQueueMessage:               "Gib `/deploy <subject>` im Channel ein, um dich in die Warteschlange einzureihen",
+		appHomeHeader:                  "*Deploys in deinen Channels*",
+		appHomeNoChannelsMessage:       "In Channels, in denen du Mitglied bist, gibt es keine Deploys. Gib `/deploy <subject>` in einem Channel ein, um einen zu starten.",
+		appHomeCurrentDeployMessage:    "%s deployt %s seit %s",
+		appHomeOwnDeployMessage:        "Du deployst gerade",
+		appHomeOwnPositionMessage:      "Dein Deploy ist an Position %d der Warteschlange",
+		crossPostedMessage:             "%s (aus <#%s>)",
+		localeMessage:                  "Bot-Nachrichten in diesem Channel sind auf %s. Gib `/deploy locale <language>` ein, um das zu ändern, verfügbare Sprachen: %s",
+		localeChangedAnnouncement:      "%s hat die Sprache der Bot-Nachrichten in diesem Channel auf %s geändert",
+		workspaceLocaleChangedMessage:  "Bot-Nachrichten in diesem Workspace werden auf %s sein, sofern für den Channel keine andere Sprache gewählt wurde",
+
+		freezeOverrideWithReasonMessage: " :warning: die Deploy-Sperrzeit wird ignoriert (%s), Grund: %s",
+
+		deployModalTitle:                   "Deploy starten",
+		deployModalSubmit:                  "Deployen",
+		deployModalSubjectLabel:            "Was deployst du?",
+		deployModalSubjectPlaceholder:      "z. B. Neue Anmeldeseite",
+		deployModalEnvironmentLabel:        "Umgebung",
+		deployModalDefaultEnvironment:      "Standard",
+		deployModalPullRequestsLabel:       "Pull Requests",
+		deployModalPullRequestsPlaceholder: "GitHub-Pull-Request-URLs, eine pro Zeile",
+		deployModalNotifyLabel:             "Benachrichtigen, wenn fertig",
+		deployModalNotifyPlaceholder:       "Personen auswählen",
+		deployModalDurationLabel:           "Erwartete Dauer",
+		deployModalDurationPlaceholder:     "z. B. 30m",
+
+		moveNotAllowedMessage: "Nur %s oder wer gerade deployt kann diesen Deploy verschieben",
+
+		queuedDeployFrozenMessage: ":warning: %s, dein Deploy wurde während der Deploy-Sperrzeit (%s) aus der Warteschlange gestartet. Gib `/deploy abort` ein, wenn er bis zum Ende der Sperrzeit warten kann.",
+
+		deployDoneButton:  "Fertig",
+		deployAbortButton: "Abbrechen",
+		deployJoinButton:  "Anstellen",
+
+		expiryWarnStep:      "den Besitzer nach %s warnen",
+		expiryAnnounceStep:  "nach %s im Channel posten",
+		expiryAbortStep:     "nach %s abbrechen",
+		deployExpiredReason: "abgelaufen",
+
+		appHomeChannelTitle:     "*<#%s>*",
+		appHomeEnvironmentTitle: "*<#%s>* %s",
+
+		deployReminderMessage:              "Dein Deploy %q wurde vor %s gestartet. Deployst du noch?",
+		handedOverDeployReminderMessage:    "Der Deploy %q, den du von %s übernommen hast, wurde vor %s gestartet. Deployst du noch?",
+		subscriberDoneMessage:              "%s hat gerade %s deployt",
+		subscriberAbortedMessage:           "Der Deploy von %s durch %s wurde abgebrochen",
+		subscriberAbortedWithReasonMessage: "Der Deploy von %s durch %s wurde abgebrochen (%s)",
+
+		turnNotificationMessage: "Du bist dran, %s in <#%s> zu deployen. Gib `/deploy done` im Channel ein, sobald du fertig bist, " +
+			"oder `/deploy notifications off`, wenn du diese Nachrichten nicht mehr erhalten möchtest.",
+
+		subscriptionStartedMessage:           "%s hat begonnen, %s in <#%s> zu deployen",
+		subscriptionCompletedMessage:         "%s hat den Deploy von %s in <#%s> beendet",
+		subscriptionAbortedMessage:           "Der Deploy von %s durch %s in <#%s> wurde abgebrochen",
+		subscriptionAbortedWithReasonMessage: "Der Deploy von %s durch %s in <#%s> wurde abgebrochen (%s)",
+	},
+	Plurals: map[string][]string{
+		appHomeQueueLengthMessage: {
+			"%d Deploy wartet in der Warteschlange",
+			"%d Deploys warten in der Warteschlange",
+		},
+	},
+}
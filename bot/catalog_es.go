@@ -0,0 +1,167 @@
+package bot
+
+var spanishCatalog = messageCatalog{
+	Name:       "Español",
+	PluralForm: oneOtherPluralForm,
+	Messages: map[string]string{
+		helpMessage: `Comandos disponibles:
+
+/deploy help — mostrar la ayuda (este mensaje)
+/deploy — abrir un diálogo para iniciar un deploy, si está disponible
+/deploy <subject> — anunciar el deploy de <subject> en el canal
+/deploy edit <subject> — cambiar el asunto de tu deploy en curso o programado
+/deploy status — mostrar el estado de los deploys en el canal
+/deploy done — terminar el deploy
+/deploy abort [<reason>] — cancelar el deploy actual, opcionalmente indicando un motivo
+/deploy history — obtener un enlace al historial de deploys de este canal
+/deploy lock[: <reason>] — impedir que se inicien nuevos deploys en este canal, opcionalmente indicando un motivo, p. ej. /deploy lock: release en curso
+/deploy unlock — volver a permitir deploys en este canal
+/deploy freeze — listar los periodos de congelación de deploys recurrentes en este canal
+/deploy freeze add <rule> — añadir un periodo de congelación, p. ej. Fri 15:00 - Mon 08:00 Europe/Berlin o 2016-12-24 - 2017-01-02 Europe/Berlin
+/deploy freeze remove <number> — eliminar un periodo de congelación
+/deploy urgent <subject> — anunciar el deploy de <subject> en el canal y ponerlo justo después del deploy en curso
+/deploy move @user <position> — cambiar la posición del deploy de un usuario en la cola, permitido a su responsable y a quien está desplegando ahora
+/deploy handover @user — hacer a otro usuario responsable del deploy en curso
+/deploy notifications on|off — activar o desactivar los mensajes directos cuando empieza tu deploy en cola
+/deploy notify done|abort|both — elegir si recibes un mensaje directo cuando un deploy en el que se te menciona termina, se cancela o ambos
+/deploy subscribe [#channel] — recibir un mensaje directo cada vez que se inicia, termina o cancela un deploy en este u otro canal
+/deploy unsubscribe [#channel] — dejar de recibir mensajes directos sobre los deploys del canal
+/deploy locale — mostrar el idioma de los mensajes del bot en este canal
+/deploy locale <language>|default — cambiar el idioma de los mensajes del bot en este canal, p. ej. es, o volver al del espacio de trabajo
+/deploy locale workspace <language> — cambiar el idioma de los mensajes del bot en todos los canales de este espacio de trabajo
+/deploy expiry — mostrar qué ocurre con los deploys que llevan demasiado tiempo en curso
+/deploy expiry <warn> <announce> <abort> — avisar al responsable, publicar en el canal y cancelar los deploys tras el tiempo indicado, p. ej. 2h 4h 8h, usa - para omitir un paso
+/deploy expiry off — desactivar la caducidad de los deploys
+/deploy force <reason>: <subject> — anunciar el deploy de <subject> en el canal a pesar de un periodo de congelación activo, indicando un motivo
+
+Si hay varios entornos configurados, antepón el nombre del entorno a cualquier comando para desplegarlos por separado,
+p. ej. /deploy staging <subject> o /deploy staging done
+
+Los subjects que empiezan por abort, edit, urgent, move, handover, freeze, notify, subscribe, unsubscribe, locale, expiry o force
+se interpretan como comandos, así que /deploy move to k8s no inicia un deploy. Reformula esos subjects, p. ej. /deploy k8s move`,
+		errorMessage:                   "`%s` devolvió un error %s",
+		noRunningDeploysMessage:        "Nadie está desplegando en este momento",
+		singleDeployStatusMessage:      "%s está desplegando %s desde %s. Todavía no hay otros deploys programados.",
+		deployQueueStatusMessage:       "%s está desplegando %s desde %s. La cola:\n %s",
+		environmentDeployMessage:       "%s en %s",
+		alreadyInQueueMessage:          "%s ya está en la cola",
+		alreadyScheduledMessage:        "Ya tienes un deploy en este canal. Escribe `/deploy edit <subject>` si quieres cambiar su asunto.",
+		deployConflictMessage:          "%s está desplegando desde %s, tu PR se ha añadido a la cola%s. Puedes escribir `/deploy done` si crees que el deploy actual ha terminado o `/deploy status` para mostrar la cola.",
+		queuePositionMessage:           " en la posición %d",
+		queueEstimateMessage:           " — empezará en aproximadamente %s",
+		queueEstimateNowMessage:        " — empezará en cualquier momento",
+		deployDoneMessage:              "%s ha terminado de desplegar",
+		deployInterruptedMessage:       "%s ha terminado el deploy iniciado por %s",
+		deployAnnouncementMessage:      "%s está a punto de desplegar %s",
+		deployHistoryLinkMessage:       "Haz clic <http://%s/%s|aquí> para ver el historial de deploys de este canal",
+		deployAbortedMessage:           "%s ha cancelado el deploy",
+		deployAbortedWithReasonMessage: "%s ha cancelado el deploy (%s)",
+		userLeftQueueMessage:           "Tu deploy programado se ha cancelado",
+		userIsNotInQueueMessage:        "No estás en la cola",
+		channelLockedMessage:           "%s ha bloqueado los deploys en este canal desde %s. Ejecuta `/deploy unlock` cuando vuelva a ser seguro desplegar.",
+		channelLockedWithReasonMessage: "%s ha bloqueado los deploys en este canal desde %s (%s). Ejecuta `/deploy unlock` cuando vuelva a ser seguro desplegar.",
+		channelNotLockedMessage:        "Los deploys en este canal no están bloqueados",
+		channelLockedAnnouncement:      "%s ha bloqueado los deploys en este canal",
+		channelLockedWithReason:        "%s ha bloqueado los deploys en este canal (%s)",
+		channelUnlockedAnnouncement:    "%s ha desbloqueado los deploys en este canal",
+		deployFrozenMessage:            "Los deploys en este canal están congelados (%s). Escribe `/deploy force <reason>: <subject>` si realmente necesitas desplegar ahora.",
+		freezeOverrideMessage:          " :warning: ignorando la congelación de deploys (%s)",
+		freezeRulesMessage:             "Periodos de congelación de deploys en este canal:\n%s",
+		noFreezeRulesMessage:           "No hay periodos de congelación de deploys en este canal",
+		freezeRuleMessage:              "%d. %s",
+		activeFreezeRuleMessage:        "%d. %s (activo)",
+		freezeRuleAddedMessage:         "%s ha añadido el periodo de congelación %s",
+		freezeRuleRemovedMessage:       "%s ha eliminado el periodo de congelación %s",
+		noSuchFreezeRuleMessage:        "No existe el periodo de congelación #%s, escribe `/deploy freeze` para ver la lista",
+		urgentDeployStatusMarker:       " :rotating_light: urgente",
+		urgentDeployQueuedMessage:      "%s ha puesto un deploy urgente de %s justo después del deploy actual de %s",
+		deployMovedMessage:             "%s ha movido el deploy de %s a la posición %d de la cola",
+		userHasNoQueuedDeploysMessage:  "%s no tiene deploys esperando en la cola",
+		deployUpdatedMessage:           "%s ha actualizado el asunto del deploy: %s",
+		scheduledDeployUpdatedMessage:  "%s ha actualizado el asunto del deploy programado: %s",
+		turnNotificationsOnMessage:     "Recibirás un mensaje directo cuando empiece tu deploy en cola",
+		turnNotificationsOffMessage:    "Ya no recibirás mensajes directos cuando empiece tu deploy en cola",
+		notifyOnDoneMessage:            "Recibirás un mensaje directo cuando termine un deploy en el que se te menciona en este canal",
+		notifyOnAbortMessage:           "Recibirás un mensaje directo cuando se cancele un deploy en el que se te menciona en este canal",
+		notifyOnBothMessage:            "Recibirás un mensaje directo cuando termine o se cancele un deploy en el que se te menciona en este canal",
+		subscribedMessage:              "Recibirás un mensaje directo cada vez que se inicie, termine o cancele un deploy en <#%s>",
+		unsubscribedMessage:            "Ya no recibirás mensajes directos sobre los deploys en <#%s>",
+		expiryPolicyMessage:            "Política de caducidad de deploys en este canal: %s",
+		noExpiryPolicyMessage:          "Los deploys en este canal nunca caducan. Escribe `/deploy expiry <warn> <announce> <abort>`, p. ej. `/deploy expiry 2h 4h 8h`, para cambiarlo.",
+		expiryPolicyChangedMessage:     "%s ha cambiado la política de caducidad de deploys: %s",
+		expiryPolicyDisabledMessage:    "%s ha desactivado la caducidad de los deploys",
+		deployExpiryWarningMessage:     "Tu deploy %s en <#%s> empezó hace %s. ¿Sigues desplegando?",
+		deployExpiryAnnouncement:       "%[1]s lleva %[3]s desplegando %[2]s. Escribe `/deploy done` si el deploy ha terminado.",
+		deployAutoAbortMessage:         " El deploy se cancelará automáticamente en %s.",
+		deployExpiredMessage:           "El deploy de %s por %s ha caducado y se ha cancelado",
+		deployHandedOverMessage:        "%s ha pasado el deploy de %s a %s",
+		deployTakenOverMessage:         "%s ha pasado el deploy de %s iniciado por %s a %s",
+		deployFinishedSummary:          ":white_check_mark: %s ha desplegado %s en %s",
+		deployAbortedSummary:           ":x: El deploy de %s por %s se ha cancelado tras %s",
+		deployAbortedWithReasonSummary: ":x: El deploy de %s por %s se ha cancelado tras %s (%s)",
+		expectedDurationMessage:        " (se espera que dure %s)",
+		deployIsOverMessage:            "Este deploy ya ha terminado. Escribe `/deploy status` para ver quién está desplegando ahora.",
+		notDeployOwnerMessage:          "Solo %s puede terminar este deploy con un botón. Escribe `/deploy done` si crees que el deploy ha terminado.",
+		joinQueueMessage:               "Escribe `/deploy <subject>` en el canal para unirte a la cola de deploys",
+		appHomeHeader:                  "*Deploys en tus canales*",
+		appHomeNoChannelsMessage:       "No hay deploys en los canales de los que eres miembro. Escribe `/deploy <subject>` en un canal para iniciar uno.",
+		appHomeCurrentDeployMessage:    "%s está desplegando %s desde %s",
+		appHomeOwnDeployMessage:        "Estás desplegando ahora",
+		appHomeOwnPositionMessage:      "Tu deploy está en la posición %d de la cola",
+		crossPostedMessage:             "%s (desde <#%s>)",
+		localeMessage:                  "Los mensajes del bot en este canal están en %s. Escribe `/deploy locale <language>` para cambiarlo, idiomas disponibles: %s",
+		localeChangedAnnouncement:      "%s ha cambiado el idioma de los mensajes del bot en este canal a %s",
+		workspaceLocaleChangedMessage:  "Los mensajes del bot en este espacio de trabajo estarán en %s, salvo que se haya elegido otro idioma para el canal",
+
+		freezeOverrideWithReasonMessage: " :warning: ignorando la congelación de deploys (%s), motivo: %s",
+
+		deployModalTitle:                   "Iniciar un deploy",
+		deployModalSubmit:                  "Desplegar",
+		deployModalSubjectLabel:            "¿Qué estás desplegando?",
+		deployModalSubjectPlaceholder:      "p. ej. Nueva página de registro",
+		deployModalEnvironmentLabel:        "Entorno",
+		deployModalDefaultEnvironment:      "predeterminado",
+		deployModalPullRequestsLabel:       "Pull requests",
+		deployModalPullRequestsPlaceholder: "URLs de pull requests de GitHub, una por línea",
+		deployModalNotifyLabel:             "Avisar al terminar",
+		deployModalNotifyPlaceholder:       "Selecciona personas",
+		deployModalDurationLabel:           "Duración prevista",
+		deployModalDurationPlaceholder:     "p. ej. 30m",
+
+		moveNotAllowedMessage: "Solo %s o quien está desplegando ahora puede mover este deploy",
+
+		queuedDeployFrozenMessage: ":warning: %s, tu deploy se ha iniciado desde la cola durante el periodo de congelación (%s). Escribe `/deploy abort` si puede esperar hasta que termine.",
+
+		deployDoneButton:  "Hecho",
+		deployAbortButton: "Cancelar",
+		deployJoinButton:  "Unirse a la cola",
+
+		expiryWarnStep:      "avisar al responsable tras %s",
+		expiryAnnounceStep:  "publicar en el canal tras %s",
+		expiryAbortStep:     "cancelar tras %s",
+		deployExpiredReason: "caducado",
+
+		appHomeChannelTitle:     "*<#%s>*",
+		appHomeEnvironmentTitle: "*<#%s>* %s",
+
+		deployReminderMessage:              "Tu deploy %q empezó hace %s. ¿Sigues desplegando?",
+		handedOverDeployReminderMessage:    "El deploy %q que recibiste de %s empezó hace %s. ¿Sigues desplegando?",
+		subscriberDoneMessage:              "%s acaba de desplegar %s",
+		subscriberAbortedMessage:           "El deploy de %s por %s se ha cancelado",
+		subscriberAbortedWithReasonMessage: "El deploy de %s por %s se ha cancelado (%s)",
+
+		turnNotificationMessage: "Es tu turno de desplegar %s en <#%s>. Escribe `/deploy done` en el canal cuando termines " +
+			"o `/deploy notifications off` si no quieres recibir estos mensajes.",
+
+		subscriptionStartedMessage:           "%s ha empezado a desplegar %s en <#%s>",
+		subscriptionCompletedMessage:         "%s ha terminado de desplegar %s en <#%s>",
+		subscriptionAbortedMessage:           "El deploy de %s por %s en <#%s> se ha cancelado",
+		subscriptionAbortedWithReasonMessage: "El deploy de %s por %s en <#%s> se ha cancelado (%s)",
+	},
+	Plurals: map[string][]string{
+		appHomeQueueLengthMessage: {
+			"%d deploy está esperando en la cola",
+			"%d deploys están esperando en la cola",
+		},
+	},
+}
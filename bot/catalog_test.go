@@ -0,0 +1,33 @@
+package bot_test
+
+import (
+	"testing"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocales(t *testing.T) {
+	assert.Equal(t, []string{"de", "en", "es"}, bot.Locales())
+}
+
+func TestParseLocale(t *testing.T) {
+	for s, expected := range map[string]string{
+		"en":    "en",
+		"de":    "de",
+		"de-DE": "de",
+		"es_ES": "es",
+		"ES-mx": "es",
+		" en ":  "en",
+	} {
+		locale, err := bot.ParseLocale(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, expected, locale, s)
+		}
+	}
+
+	for _, s := range []string{"", "fr", "fr-FR", "deutsch"} {
+		_, err := bot.ParseLocale(s)
+		assert.Error(t, err, s)
+	}
+}
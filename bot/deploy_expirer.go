@@ -0,0 +1,193 @@
+package bot
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/scheduler"
+	"github.com/adjust/michaelbot/slack"
+)
+
+const (
+	deployExpiryTimerKind = "deploy.expiry"
+	deployExpiredReason   = "expired"
+)
+
+const (
+	expiryStepWarn     = "warn"
+	expiryStepAnnounce = "announce"
+	expiryStepAbort    = "abort"
+)
+
+type deployExpiryTimer struct {
+	TeamID      string `json:",omitempty"`
+	ChannelID   string
+	Environment string
+	Step        string
+	// StartedAt is used to make sure that the timer fires for the same deploy it has been scheduled for
+	StartedAt time.Time
+}
+
+// deployExpirer enforces the channel expiry policy for deploys that have been running for too long. The policy
+// steps are scheduled as persistent timers once a deploy is started and cancelled when it is over.
+type deployExpirer struct {
+	bot     *Bot
+	sched   *scheduler.Scheduler
+	clients *workspaceClients
+}
+
+func newDeployExpirer(b *Bot, sched *scheduler.Scheduler, api slack.WebAPIClients) *deployExpirer {
+	e := &deployExpirer{
+		bot:     b,
+		sched:   sched,
+		clients: newWorkspaceClients(api),
+	}
+	sched.Handle(deployExpiryTimerKind, e.handleTimer)
+
+	return e
+}
+
+func (e *deployExpirer) DeployStarted(channelID string, d deploy.Deploy) {
+	e.schedule(deploy.Channel{TeamID: d.TeamID, ID: channelID, Environment: d.Environment}, d)
+}
+
+func (e *deployExpirer) DeployCompleted(channelID string, d deploy.Deploy) {
+	e.cancel(deploy.Channel{TeamID: d.TeamID, ID: channelID, Environment: d.Environment})
+}
+
+func (e *deployExpirer) DeployAborted(channelID string, d deploy.Deploy) {
+	e.cancel(deploy.Channel{TeamID: d.TeamID, ID: channelID, Environment: d.Environment})
+}
+
+// Reschedule applies the current channel expiry policy to the running deploy.
+func (e *deployExpirer) Reschedule(ch deploy.Channel) {
+	d, ok := e.bot.deploys.Current(ch)
+	if !ok {
+		e.cancel(ch)
+		return
+	}
+
+	e.schedule(ch, d)
+}
+
+func (e *deployExpirer) schedule(ch deploy.Channel, d deploy.Deploy) {
+	e.cancel(ch)
+
+	policy, ok := e.bot.deploys.ExpiryPolicy(ch)
+	if !ok {
+		return
+	}
+
+	steps := []struct {
+		Name  string
+		After time.Duration
+	}{
+		{expiryStepAbort, policy.Abort},
+		{expiryStepAnnounce, policy.Announce},
+		{expiryStepWarn, policy.Warn},
+	}
+
+	overdue := false
+	for _, step := range steps {
+		if step.After <= 0 {
+			continue
+		}
+
+		fireAt := d.StartedAt.Add(step.After)
+		if fireAt.Before(time.Now()) {
+			// the policy has been changed after the deploy start, only the latest overdue step is executed
+			if overdue {
+				continue
+			}
+
+			overdue = true
+		}
+
+		data, err := json.Marshal(deployExpiryTimer{
+			TeamID:      ch.TeamID,
+			ChannelID:   ch.ID,
+			Environment: ch.Environment,
+			Step:        step.Name,
+			StartedAt:   d.StartedAt,
+		})
+		if err != nil {
+			log.Printf("deploy-expirer: failed to marshal %s timer for %s: %s", step.Name, ch.Key(), err)
+			continue
+		}
+
+		e.sched.Schedule(scheduler.Timer{
+			ID:     deployExpiryTimerID(ch, step.Name),
+			Kind:   deployExpiryTimerKind,
+			FireAt: fireAt,
+			Data:   data,
+		})
+	}
+}
+
+func (e *deployExpirer) cancel(ch deploy.Channel) {
+	for _, step := range []string{expiryStepWarn, expiryStepAnnounce, expiryStepAbort} {
+		e.sched.Cancel(deployExpiryTimerID(ch, step))
+	}
+}
+
+func (e *deployExpirer) handleTimer(t scheduler.Timer) {
+	var data deployExpiryTimer
+	if err := json.Unmarshal(t.Data, &data); err != nil {
+		log.Printf("deploy-expirer: malformed timer %s: %s", t.ID, err)
+		return
+	}
+
+	ch := deploy.Channel{TeamID: data.TeamID, ID: data.ChannelID, Environment: data.Environment}
+
+	d, ok := e.bot.deploys.Current(ch)
+	if !ok || !d.StartedAt.Equal(data.StartedAt) {
+		// the deploy is over
+		return
+	}
+
+	api, err := e.clients.WebAPI(ch.TeamID)
+	if err != nil {
+		log.Printf("deploy-expirer: skipping timer %s: %s", t.ID, err)
+		return
+	}
+
+	policy, _ := e.bot.deploys.ExpiryPolicy(ch)
+
+	switch data.Step {
+	case expiryStepWarn:
+		im, err := e.clients.InstantMessenger(ch.TeamID)
+		if err == nil {
+			ctx, cancel := webAPIContext()
+			err = im.SendMessageContext(ctx, d.User, e.bot.userResponses(ch, d.User).DeployExpiryWarning(ch, d, policy).Message)
+			cancel()
+		}
+
+		if err != nil {
+			log.Printf("deploy-expirer: failed to send an instant message to %s: %s", d.User.Name, err)
+		}
+	case expiryStepAnnounce:
+		e.bot.announceDeployEvent(ch, d, e.bot.channelResponses(ch).DeployExpiryAnnouncement(d, policy), channelResponder{api, ch.ID})
+	case expiryStepAbort:
+		e.expire(ch, channelResponder{api, ch.ID})
+	default:
+		log.Printf("deploy-expirer: unknown step %q in timer %s", data.Step, t.ID)
+	}
+}
+
+// expire aborts the running deploy and starts the next one in the queue
+func (e *deployExpirer) expire(ch deploy.Channel, resp responder) {
+	d, ok := e.bot.deploys.Abort(ch, deployExpiredReason)
+	if !ok {
+		return
+	}
+
+	e.bot.announceDeployEvent(ch, d, e.bot.channelResponses(ch).DeployExpiredAnnouncement(d), resp)
+	e.bot.updateDeployAnnouncement(ch, d)
+	e.bot.startNextDeploy(ch, d, resp)
+}
+
+func deployExpiryTimerID(ch deploy.Channel, step string) string {
+	return deployExpiryTimerKind + "/" + step + "/" + ch.Key()
+}
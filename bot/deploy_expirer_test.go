@@ -0,0 +1,101 @@
+package bot_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/scheduler"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot_DeployExpiry(t *testing.T) {
+	const (
+		slackToken  = "slack-token"
+		webAPIToken = "xxxxx-token1"
+	)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages = append(messages, r.FormValue("channel")+": "+r.FormValue("text"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI(webAPIToken, nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	b := bot.New(slackToken, "", store)
+	b.EnableDeployExpiry(scheduler.New(store), api)
+
+	command := func(userID, text string) {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	command("U1", "expiry 30ms 60ms 90ms")
+	command("U1", "first deploy")
+	command("U2", "second deploy")
+
+	time.Sleep(110 * time.Millisecond)
+	command("U1", "expiry off")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if assert.True(t, len(messages) >= 4) {
+		assert.True(t, strings.HasPrefix(messages[0], "DMU1: Your deploy first deploy in <#C1>"), messages[0])
+		assert.True(t, strings.HasPrefix(messages[1], "C1: <@U1|u1> has been deploying first deploy"), messages[1])
+		assert.Equal(t, "C1: The deploy of first deploy by <@U1|u1> has expired and been aborted", messages[2])
+		assert.Equal(t, "C1: <@U2|u2> is about to deploy second deploy", messages[3])
+	}
+
+	if history := store.All("C1"); assert.Len(t, history, 1) {
+		assert.True(t, history[0].Aborted)
+		assert.Equal(t, "expired", history[0].AbortReason)
+	}
+
+	if d, ok := deploy.NewChannelDeploys(store).Current(deploy.Channel{ID: "C1"}); assert.True(t, ok) {
+		assert.Equal(t, "U2", d.User.ID)
+	}
+
+	// disabling the expiry cancels timers scheduled for the running deploy
+	assert.Empty(t, store.GetTimers())
+}
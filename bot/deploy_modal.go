@@ -0,0 +1,196 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+)
+
+const (
+	deployModalCallbackID = "deploy.start"
+
+	deployModalSubjectBlock      = "subject"
+	deployModalEnvironmentBlock  = "environment"
+	deployModalPullRequestsBlock = "pull_requests"
+	deployModalNotifyBlock       = "notify"
+	deployModalDurationBlock     = "duration"
+
+	// the block and action IDs are the same for all modal inputs
+	deployModalInputAction = "value"
+
+	// select options can't have an empty value, so the default environment has a placeholder one
+	defaultEnvironmentValue = "_"
+
+	// triggerIDTimeout is the time after which the trigger ID of an interaction expires
+	triggerIDTimeout = 3 * time.Second
+)
+
+// deployModalMetadata is passed as a private metadata of the deploy modal
+type deployModalMetadata struct {
+	ChannelID string
+}
+
+// EnableDeployModal makes `/deploy` without arguments and the "Join queue" button open a modal to start a deploy.
+// The modal is opened via api.
+func (b *Bot) EnableDeployModal(api slack.WebAPIClients) {
+	b.modalAPI = newWorkspaceClients(api)
+}
+
+// openDeployModal shows the modal to start a deploy in channel to the user who has triggered an interaction.
+// The ctx is expected to expire along with the trigger ID, see triggerIDTimeout.
+func (b *Bot) openDeployModal(ctx context.Context, triggerID string, ch deploy.Channel, user slack.User) error {
+	metadata, err := json.Marshal(deployModalMetadata{ChannelID: ch.ID})
+	if err != nil {
+		return err
+	}
+
+	api, err := b.modalAPI.WebAPI(ch.TeamID)
+	if err != nil {
+		return err
+	}
+
+	view := b.userResponses(ch, user).DeployModal(ch.Environment, b.environmentNames())
+	view.PrivateMetadata = string(metadata)
+
+	return api.OpenViewContext(ctx, triggerID, view)
+}
+
+// handleDeployModalSubmission starts a deploy described by the modal input. If any of the fields is invalid, the errors
+// are shown in the modal.
+func (b *Bot) handleDeployModalSubmission(w http.ResponseWriter, interaction slack.Interaction) {
+	var metadata deployModalMetadata
+	if err := json.Unmarshal([]byte(interaction.View.PrivateMetadata), &metadata); err != nil || metadata.ChannelID == "" {
+		http.Error(w, "Malformed view metadata", http.StatusBadRequest)
+		return
+	}
+
+	ch, d, errs := b.parseDeployModal(b.channel(interaction.TeamID, metadata.ChannelID), interaction.User, interaction.View.State)
+	if len(errs) > 0 {
+		body, err := json.Marshal(slack.NewViewSubmissionErrors(errs))
+		if err != nil {
+			log.Printf("failed to encode deploy modal errors: %s", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+		return
+	}
+
+	api, err := b.modalAPI.WebAPI(ch.TeamID)
+	if err != nil {
+		log.Printf("failed to start a deploy from the modal: %s", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// close the modal
+	w.Write(nil)
+
+	go b.startDeploy(ch, d, b.deploys.Start, modalResponder{
+		api:       api,
+		channelID: ch.ID,
+		user:      interaction.User,
+	})
+}
+
+// parseDeployModal builds a deploy from the deploy modal input. The returned map contains validation errors
+// for input blocks.
+func (b *Bot) parseDeployModal(ch deploy.Channel, user slack.User, state *slack.ViewState) (deploy.Channel, deploy.Deploy, map[string]string) {
+	var (
+		d    = deploy.Deploy{User: user}
+		errs = make(map[string]string)
+	)
+
+	if v, ok := state.Value(deployModalSubjectBlock, deployModalInputAction); ok {
+		d.Subject = slack.EscapeMessage(strings.TrimSpace(v.Value))
+	}
+
+	if d.Subject == "" {
+		errs[deployModalSubjectBlock] = "Please describe what you are going to deploy"
+	}
+
+	if v, ok := state.Value(deployModalEnvironmentBlock, deployModalInputAction); ok && v.SelectedOption != nil {
+		if env := v.SelectedOption.Value; env != defaultEnvironmentValue {
+			if _, ok := b.environments[env]; !ok {
+				errs[deployModalEnvironmentBlock] = "Unknown environment " + env
+			}
+
+			ch.Environment = env
+		}
+	}
+
+	if v, ok := state.Value(deployModalPullRequestsBlock, deployModalInputAction); ok {
+		for _, line := range strings.Fields(v.Value) {
+			ref, err := deploy.ParsePullRequestURL(line)
+			if err != nil {
+				errs[deployModalPullRequestsBlock] = err.Error()
+				break
+			}
+
+			d.PullRequests = append(d.PullRequests, ref)
+		}
+	}
+
+	if v, ok := state.Value(deployModalNotifyBlock, deployModalInputAction); ok {
+		for _, userID := range v.SelectedUsers {
+			d.Subscribers = append(d.Subscribers, deploy.UserReference{ID: userID})
+		}
+	}
+
+	if v, ok := state.Value(deployModalDurationBlock, deployModalInputAction); ok && strings.TrimSpace(v.Value) != "" {
+		duration, err := time.ParseDuration(strings.TrimSpace(v.Value))
+		if err != nil || duration <= 0 {
+			errs[deployModalDurationBlock] = "Please provide a duration like 30m or 1h30m"
+		}
+
+		d.ExpectedDuration = duration
+	}
+
+	return ch, d, errs
+}
+
+// environmentNames returns the sorted list of configured environments.
+func (b *Bot) environmentNames() []string {
+	envs := make([]string, 0, len(b.environments))
+	for env := range b.environments {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+
+	return envs
+}
+
+// modalResponder responds to the user who has submitted a modal with ephemeral messages in channel, while announcements
+// are posted in channel.
+type modalResponder struct {
+	api       *slack.WebAPI
+	channelID string
+	user      slack.User
+}
+
+func (resp modalResponder) Respond(response *slack.Response) {
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	if err := resp.api.PostEphemeralMessageContext(ctx, resp.channelID, resp.user, response.Message); err != nil {
+		log.Printf("failed to respond to %s in %s: %s", resp.user.Name, resp.channelID, err)
+	}
+}
+
+func (resp modalResponder) Announce(response *slack.Response) {
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	if err := resp.api.PostMessageContext(ctx, resp.channelID, response.Message); err != nil {
+		log.Printf("failed to post message to %s: %s", resp.channelID, err)
+	}
+}
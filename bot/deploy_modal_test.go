@@ -0,0 +1,294 @@
+package bot_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/github"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot_DeployModal(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu       sync.Mutex
+		views    []slack.View
+		messages []string
+	)
+
+	mux.HandleFunc("/views.open", func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			View slack.View `json:"view"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		mu.Lock()
+		views = append(views, payload.View)
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages = append(messages, r.FormValue("channel")+": "+r.FormValue("text"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+	mux.HandleFunc("/chat.postEphemeral", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages = append(messages, r.FormValue("channel")+"/"+r.FormValue("user")+": "+r.FormValue("text"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	b := bot.New(slackToken, "", store)
+	b.SetEnvironments("staging", "production")
+	b.EnableDeployModal(api)
+
+	form := url.Values{
+		"token":      {slackToken},
+		"command":    {"/deploy"},
+		"channel_id": {"C1"},
+		"user_id":    {"U1"},
+		"user_name":  {"u1"},
+		"text":       {"staging"},
+		"trigger_id": {"trigger1"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+	b.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	mu.Lock()
+	require.Len(t, views, 1)
+	view := views[0]
+	mu.Unlock()
+
+	assert.Equal(t, "deploy.start", view.CallbackID)
+
+	var blockIDs []string
+	for _, block := range view.Blocks {
+		blockIDs = append(blockIDs, block.BlockID)
+
+		if block.BlockID == "environment" && assert.NotNil(t, block.Element.InitialOption) {
+			assert.Equal(t, "staging", block.Element.InitialOption.Value)
+			assert.Len(t, block.Element.Options, 3)
+		}
+	}
+	assert.Equal(t, []string{"subject", "environment", "pull_requests", "notify", "duration"}, blockIDs)
+
+	submit := func(userID string, values string) *httptest.ResponseRecorder {
+		payload := fmt.Sprintf(
+			`{"type":"view_submission","token":%q,"user":{"id":%q,"username":%q},"view":{"callback_id":"deploy.start","private_metadata":%q,"state":{"values":%s}}}`,
+			slackToken, userID, strings.ToLower(userID), view.PrivateMetadata, values,
+		)
+
+		req := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(url.Values{"payload": {payload}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeInteraction(rec, req)
+		time.Sleep(20 * time.Millisecond)
+
+		return rec
+	}
+
+	// invalid input
+	rec = submit("U1", `{
+		"subject": {"value": {"type": "plain_text_input", "value": "new feature"}},
+		"pull_requests": {"value": {"type": "plain_text_input", "value": "https://github.com/user/project/issues/1"}},
+		"duration": {"value": {"type": "plain_text_input", "value": "half an hour"}}
+	}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var validationErrors slack.ViewSubmissionErrors
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &validationErrors), rec.Body.String())
+	assert.Equal(t, "errors", validationErrors.ResponseAction)
+	assert.Contains(t, validationErrors.Errors, "pull_requests")
+	assert.Contains(t, validationErrors.Errors, "duration")
+	assert.NotContains(t, validationErrors.Errors, "subject")
+
+	_, ok := deploy.NewChannelDeploys(store).Current(deploy.Channel{ID: "C1", Environment: "staging"})
+	assert.False(t, ok)
+
+	// valid input
+	rec = submit("U1", `{
+		"subject": {"value": {"type": "plain_text_input", "value": "new feature"}},
+		"environment": {"value": {"type": "static_select", "selected_option": {"value": "staging"}}},
+		"pull_requests": {"value": {"type": "plain_text_input"}},
+		"notify": {"value": {"type": "multi_users_select", "selected_users": ["U3"]}},
+		"duration": {"value": {"type": "plain_text_input", "value": "30m"}}
+	}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	if d, ok := deploy.NewChannelDeploys(store).Current(deploy.Channel{ID: "C1", Environment: "staging"}); assert.True(t, ok) {
+		assert.Equal(t, "new feature", d.Subject)
+		assert.Equal(t, "U1", d.User.ID)
+		assert.Equal(t, []deploy.UserReference{{ID: "U3"}}, d.Subscribers)
+		assert.Equal(t, 30*time.Minute, d.ExpectedDuration)
+	}
+
+	// another deploy is in progress
+	rec = submit("U2", `{
+		"subject": {"value": {"type": "plain_text_input", "value": "hotfix"}},
+		"environment": {"value": {"type": "static_select", "selected_option": {"value": "staging"}}}
+	}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "C1: <@U1|u1> is about to deploy new feature to staging (expected to take 30m0s)", messages[0])
+		assert.True(t, strings.HasPrefix(messages[1], "C1/U2: <@U1|u1> is deploying since"), messages[1])
+	}
+}
+
+func TestBot_DeployModal_JoinQueue_OpenError(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/views.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":false,"error":"expired_trigger_id"}`)
+	})
+
+	var (
+		mu        sync.Mutex
+		responses []string
+	)
+
+	responseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp struct {
+			Text         string `json:"text"`
+			ResponseType string `json:"response_type"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&resp))
+
+		mu.Lock()
+		responses = append(responses, resp.ResponseType+": "+resp.Text)
+		mu.Unlock()
+	}))
+	defer responseServer.Close()
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+	deploys := deploy.NewChannelDeploys(store)
+
+	d, err := deploys.Start(deploy.Channel{ID: "C1"}, deploy.New(slack.User{ID: "U1", Name: "u1"}, "first deploy"))
+	require.NoError(t, err)
+
+	b := bot.New(slackToken, "", store)
+	b.EnableDeployModal(api)
+
+	value := bot.NewResponseBuilder(github.NewClient("", nil)).DeployAnnouncement(d).Blocks[1].Elements[0].Value
+	payload := fmt.Sprintf(
+		`{"type":"block_actions","token":%q,"trigger_id":"trigger1","user":{"id":"U2","username":"u2"},"channel":{"id":"C1"},"response_url":%q,"actions":[{"action_id":"deploy.join","value":%q}]}`,
+		slackToken, responseServer.URL, value,
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(url.Values{"payload": {payload}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	rec := httptest.NewRecorder()
+	b.ServeInteraction(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// no deploy without subject is queued if the modal could not be opened
+	assert.Equal(t, []string{": Type `/deploy <subject>` in the channel to join the deploy queue"}, responses)
+	assert.Len(t, deploys.All(deploy.Channel{ID: "C1"}), 1)
+}
+
+func TestBot_DeployModal_JoinQueue_SlowWebAPI(t *testing.T) {
+	const slackToken = "slack-token"
+
+	var (
+		release = make(chan struct{})
+		opened  = make(chan string, 1)
+	)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	mux.HandleFunc("/views.open", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+
+		var payload struct {
+			TriggerID string `json:"trigger_id"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		opened <- payload.TriggerID
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	d, err := deploy.NewChannelDeploys(store).Start(deploy.Channel{ID: "C1"}, deploy.New(slack.User{ID: "U1", Name: "u1"}, "first deploy"))
+	require.NoError(t, err)
+
+	b := bot.New(slackToken, "", store)
+	b.EnableDeployModal(api)
+
+	value := bot.NewResponseBuilder(github.NewClient("", nil)).DeployAnnouncement(d).Blocks[1].Elements[0].Value
+	payload := fmt.Sprintf(
+		`{"type":"block_actions","token":%q,"trigger_id":"trigger1","user":{"id":"U2","username":"u2"},"channel":{"id":"C1"},"response_url":"http://example.com","actions":[{"action_id":"deploy.join","value":%q}]}`,
+		slackToken, value,
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(url.Values{"payload": {payload}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// the interaction is acknowledged before the modal is opened
+	rec := httptest.NewRecorder()
+	b.ServeInteraction(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	close(release)
+
+	select {
+	case triggerID := <-opened:
+		assert.Equal(t, "trigger1", triggerID)
+	case <-time.After(time.Second):
+		t.Error("the deploy modal has not been opened")
+	}
+}
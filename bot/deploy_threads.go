@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"log"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+)
+
+// EnableDeployThreads makes the bot post deploy announcements via api instead of the command response_url.
+// Once a deploy is announced, later events such as completion, abort or handover are posted as replies in
+// the announcement thread, and the announcement itself is updated to reflect the deploy state.
+func (b *Bot) EnableDeployThreads(api slack.WebAPIClients) {
+	b.threadsAPI = newWorkspaceClients(api)
+}
+
+// announceDeployStart posts the announcement of a started deploy and saves its timestamp. If deploy threads are
+// disabled or the message could not be posted, the announcement is sent with resp. Since this may take a while,
+// command handlers call it, as well as the other deploy thread functions, after the command has been acknowledged.
+func (b *Bot) announceDeployStart(ch deploy.Channel, d deploy.Deploy, resp responder) {
+	response := b.channelResponses(ch).DeployAnnouncement(d)
+	if b.threadsAPI == nil {
+		resp.Announce(response)
+		return
+	}
+
+	api, err := b.threadsAPI.WebAPI(ch.TeamID)
+	if err != nil {
+		log.Printf("failed to post deploy announcement in %s: %s", ch.ID, err)
+		resp.Announce(response)
+		return
+	}
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	ts, err := api.PublishMessageContext(ctx, ch.ID, response.Message)
+	if err != nil {
+		log.Printf("failed to post deploy announcement in %s: %s", ch.ID, err)
+		resp.Announce(response)
+		return
+	}
+
+	b.deploys.SetMessageTS(ch, d, ts)
+}
+
+// announceDeployEvent posts response as a reply in the thread of deploy d announcement. If the announcement
+// has not been posted via Web API the response is sent with resp.
+func (b *Bot) announceDeployEvent(ch deploy.Channel, d deploy.Deploy, response *slack.Response, resp responder) {
+	if b.threadsAPI == nil || d.MessageTS == "" {
+		resp.Announce(response)
+		return
+	}
+
+	api, err := b.threadsAPI.WebAPI(ch.TeamID)
+	if err != nil {
+		log.Printf("failed to post in the thread of %s deploy announcement in %s: %s", d.Subject, ch.ID, err)
+		resp.Announce(response)
+		return
+	}
+
+	message := response.Message
+	message.ThreadTS = d.MessageTS
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	if err := api.PostMessageContext(ctx, ch.ID, message); err != nil {
+		log.Printf("failed to post in the thread of %s deploy announcement in %s: %s", d.Subject, ch.ID, err)
+		resp.Announce(response)
+	}
+}
+
+// updateDeployAnnouncement rewrites the announcement of deploy d to show its current state.
+func (b *Bot) updateDeployAnnouncement(ch deploy.Channel, d deploy.Deploy) {
+	if b.threadsAPI == nil || d.MessageTS == "" {
+		return
+	}
+
+	responses := b.channelResponses(ch)
+
+	response := responses.DeployAnnouncement(d)
+	if d.Finished() {
+		response = responses.DeploySummary(d)
+	}
+
+	api, err := b.threadsAPI.WebAPI(ch.TeamID)
+	if err != nil {
+		log.Printf("failed to update %s deploy announcement in %s: %s", d.Subject, ch.ID, err)
+		return
+	}
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	if err := api.UpdateMessageContext(ctx, ch.ID, d.MessageTS, response.Message); err != nil {
+		log.Printf("failed to update %s deploy announcement in %s: %s", d.Subject, ch.ID, err)
+	}
+}
+
+// channelResponder posts all responses in channel via Web API. It is used to announce changes that are not
+// triggered by users.
+type channelResponder struct {
+	api       *slack.WebAPI
+	channelID string
+}
+
+func (resp channelResponder) Respond(response *slack.Response) {
+	resp.Announce(response)
+}
+
+func (resp channelResponder) Announce(response *slack.Response) {
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	if err := resp.api.PostMessageContext(ctx, resp.channelID, response.Message); err != nil {
+		log.Printf("failed to post message to %s: %s", resp.channelID, err)
+	}
+}
@@ -0,0 +1,164 @@
+package bot_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot_DeployThreads(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu       sync.Mutex
+		messages []string
+		updates  []string
+		lastTS   int
+	)
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		lastTS++
+		messages = append(messages, r.FormValue("thread_ts")+": "+r.FormValue("text"))
+
+		fmt.Fprintf(w, `{"ok":true,"channel":%q,"ts":"%d.1"}`, r.FormValue("channel"), lastTS)
+	})
+	mux.HandleFunc("/chat.update", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		updates = append(updates, r.FormValue("ts")+": "+r.FormValue("text"))
+		assert.NotContains(t, r.FormValue("blocks"), "deploy.done")
+
+		fmt.Fprintf(w, `{"ok":true,"channel":%q,"ts":%q}`, r.FormValue("channel"), r.FormValue("ts"))
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	b := bot.New(slackToken, "", store)
+	b.EnableDeployThreads(api)
+
+	command := func(userID, text string) {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// the announcement is posted after the command has been acknowledged
+	command("U1", "first deploy")
+	time.Sleep(20 * time.Millisecond)
+
+	command("U2", "second deploy")
+
+	if d, ok := deploy.NewChannelDeploys(store).Current(deploy.Channel{ID: "C1"}); assert.True(t, ok) {
+		assert.Equal(t, "1.1", d.MessageTS)
+	}
+
+	command("U1", "done")
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{
+		": <@U1|u1> is about to deploy first deploy",
+		"1.1: <@U1|u1> done deploying",
+		": <@U2|u2> is about to deploy second deploy",
+	}, messages)
+
+	if assert.Len(t, updates, 1) {
+		assert.True(t, strings.HasPrefix(updates[0], "1.1: :white_check_mark: <@U1|u1> has deployed first deploy in "), updates[0])
+	}
+
+	if history := store.All("C1"); assert.Len(t, history, 1) {
+		assert.Equal(t, "1.1", history[0].MessageTS)
+	}
+
+	if d, ok := deploy.NewChannelDeploys(store).Current(deploy.Channel{ID: "C1"}); assert.True(t, ok) {
+		assert.Equal(t, "3.1", d.MessageTS)
+	}
+}
+
+func TestBot_DeployThreads_SlowWebAPI(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		release = make(chan struct{})
+		posted  = make(chan string, 1)
+	)
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+
+		posted <- r.FormValue("text")
+		fmt.Fprintf(w, `{"ok":true,"channel":%q,"ts":"1.1"}`, r.FormValue("channel"))
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	b := bot.New(slackToken, "", deploy.NewInMemoryStore())
+	b.EnableDeployThreads(api)
+
+	form := url.Values{
+		"token":      {slackToken},
+		"command":    {"/deploy"},
+		"channel_id": {"C1"},
+		"user_id":    {"U1"},
+		"user_name":  {"u1"},
+		"text":       {"first deploy"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// the command is acknowledged while the announcement is still being posted
+	rec := httptest.NewRecorder()
+	b.ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	close(release)
+
+	select {
+	case text := <-posted:
+		assert.Equal(t, "<@U1|u1> is about to deploy first deploy", text)
+	case <-time.After(time.Second):
+		t.Error("the deploy announcement has not been posted")
+	}
+}
@@ -0,0 +1,189 @@
+package bot
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+)
+
+// eventRetryPeriod is the time during which Slack retries to deliver an event that has not been acknowledged
+const eventRetryPeriod = 10 * time.Minute
+
+// leadingMention matches the app mention that precedes a command sent in message, i.e. <@U123456> status
+var leadingMention = regexp.MustCompile(`^\s*<@[A-Z0-9]+(\|[^>]*)?>\s*`)
+
+// receivedEvents keeps the IDs of events received within eventRetryPeriod to tell retries of handled events apart
+// from the ones that have not reached the bot before.
+type receivedEvents struct {
+	mu  sync.Mutex
+	ids map[string]time.Time
+}
+
+// Add records the event ID and returns false if the event has already been received.
+func (evs *receivedEvents) Add(id string, now time.Time) bool {
+	evs.mu.Lock()
+	defer evs.mu.Unlock()
+
+	for k, t := range evs.ids {
+		if now.Sub(t) >= eventRetryPeriod {
+			delete(evs.ids, k)
+		}
+	}
+
+	if _, ok := evs.ids[id]; ok {
+		return false
+	}
+
+	if evs.ids == nil {
+		evs.ids = make(map[string]time.Time)
+	}
+	evs.ids[id] = now
+
+	return true
+}
+
+// EnableMentions lets users issue deploy commands by mentioning the app in channels and threads, e.g. `@michael status`.
+// Replies are posted via api.
+func (b *Bot) EnableMentions(api slack.WebAPIClients) {
+	b.api = newWorkspaceClients(api)
+}
+
+// ServeEvents handles requests sent by Slack to the Events API endpoint.
+func (b *Bot) ServeEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Only POST requests are supported", http.StatusBadRequest)
+		return
+	}
+
+	callback, err := slack.ParseEventCallback(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if b.slackToken != "" && callback.Token != b.slackToken {
+		http.Error(w, "Invalid token", http.StatusForbidden)
+		return
+	}
+
+	switch callback.Type {
+	case slack.EventTypeURLVerification:
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(callback.Challenge))
+		return
+	case slack.EventTypeCallback:
+		// Slack expects the event to be acknowledged within 3 seconds, so the command is handled asynchronously
+		w.Write(nil)
+	default:
+		http.Error(w, "Unsupported callback type", http.StatusBadRequest)
+		return
+	}
+
+	if callback.EventID != "" && !b.events.Add(callback.EventID, time.Now()) {
+		// the event has already been received, but not acknowledged in time
+		log.Printf("skipping retry #%s of event %s", r.Header.Get("X-Slack-Retry-Num"), callback.EventID)
+		return
+	}
+
+	switch callback.EventType() {
+	case slack.EventTypeAppMention:
+		b.handleMentionEvent(callback, r.Host)
+	case slack.EventTypeAppHomeOpened:
+		b.handleAppHomeOpenedEvent(callback)
+	}
+}
+
+func (b *Bot) handleMentionEvent(callback slack.EventCallback, host string) {
+	var event slack.AppMentionEvent
+	if err := json.Unmarshal(callback.Event, &event); err != nil {
+		log.Printf("malformed %s event %s: %s", slack.EventTypeAppMention, callback.EventID, err)
+		return
+	}
+
+	if b.api == nil {
+		log.Printf("mentions are not enabled, ignoring event %s", callback.EventID)
+		return
+	}
+
+	go b.handleMention(b.channel(callback.TeamID, event.Channel), event, host)
+}
+
+func (b *Bot) handleAppHomeOpenedEvent(callback slack.EventCallback) {
+	var event slack.AppHomeOpenedEvent
+	if err := json.Unmarshal(callback.Event, &event); err != nil {
+		log.Printf("malformed %s event %s: %s", slack.EventTypeAppHomeOpened, callback.EventID, err)
+		return
+	}
+
+	if b.appHome == nil || event.Tab != "home" {
+		return
+	}
+
+	go b.appHome.Open(b.workspace(callback.TeamID), event.User)
+}
+
+func (b *Bot) handleMention(ch deploy.Channel, event slack.AppMentionEvent, host string) {
+	api, err := b.api.WebAPI(ch.TeamID)
+	if err != nil {
+		log.Printf("failed to reply to mention in %s: %s", event.Channel, err)
+		return
+	}
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	user, err := api.GetUserContext(ctx, event.User)
+	if err != nil {
+		log.Printf("failed to get user info for %s: %s", event.User, err)
+		user = slack.User{ID: event.User}
+	}
+
+	// the message text comes escaped by Slack, while the command parser expects it as typed by the user
+	text := strings.TrimSpace(leadingMention.ReplaceAllString(slack.UnescapeMessage(event.Text), ""))
+	b.handleCommand(ch, user, text, host, mentionResponder{
+		api:       api,
+		channelID: event.Channel,
+		user:      user,
+		threadTS:  event.ThreadTS,
+	})
+}
+
+// mentionResponder replies to commands sent by mentioning the app. User messages are only shown to the user who has
+// mentioned the app, while announcements are posted in channel. Both are kept in the thread the app has been mentioned in.
+type mentionResponder struct {
+	api       *slack.WebAPI
+	channelID string
+	user      slack.User
+	threadTS  string
+}
+
+func (resp mentionResponder) Respond(response *slack.Response) {
+	message := response.Message
+	message.ThreadTS = resp.threadTS
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	if err := resp.api.PostEphemeralMessageContext(ctx, resp.channelID, resp.user, message); err != nil {
+		log.Printf("failed to respond to %s in %s: %s", resp.user.Name, resp.channelID, err)
+	}
+}
+
+func (resp mentionResponder) Announce(response *slack.Response) {
+	message := response.Message
+	message.ThreadTS = resp.threadTS
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	if err := resp.api.PostMessageContext(ctx, resp.channelID, message); err != nil {
+		log.Printf("failed to reply to mention in %s: %s", resp.channelID, err)
+	}
+}
@@ -0,0 +1,157 @@
+package bot_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot_ServeEvents_URLVerification(t *testing.T) {
+	b := bot.New("slack-token", "", deploy.NewInMemoryStore())
+
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"token":"slack-token","challenge":"challenge1","type":"url_verification"}`))
+	rec := httptest.NewRecorder()
+	b.ServeEvents(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "challenge1", rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"token":"another-token","challenge":"challenge1","type":"url_verification"}`))
+	rec = httptest.NewRecorder()
+	b.ServeEvents(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestBot_ServeEvents_AppMention(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+
+	mux.HandleFunc("/users.info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"user":{"id":%q,"name":%q}}`, r.FormValue("user"), strings.ToLower(r.FormValue("user")))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages = append(messages, r.FormValue("channel")+"/"+r.FormValue("thread_ts")+": "+r.FormValue("text"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+	mux.HandleFunc("/chat.postEphemeral", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages = append(messages, r.FormValue("channel")+"/"+r.FormValue("thread_ts")+" to "+r.FormValue("user")+": "+r.FormValue("text"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	b := bot.New(slackToken, "", store)
+	b.EnableMentions(api)
+
+	mention := func(userID, text, ts, threadTS string) {
+		body := fmt.Sprintf(
+			`{"token":%q,"type":"event_callback","event_id":"Ev%s","event":{"type":"app_mention","user":%q,"text":%q,"channel":"C1","ts":%q,"thread_ts":%q}}`,
+			slackToken, ts, userID, text, ts, threadTS,
+		)
+
+		rec := httptest.NewRecorder()
+		b.ServeEvents(rec, httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Slack escapes control characters in the message text
+	mention("U1", "<@U0BOT> first &amp; &lt;only&gt; deploy", "1.1", "")
+	mention("U2", "<@U0BOT> status", "2.1", "1.1")
+	mention("U2", "<@U0BOT> status", "3.1", "")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if assert.Len(t, messages, 3) {
+		assert.Equal(t, "C1/: <@U1|u1> is about to deploy first &amp; &lt;only&gt; deploy", messages[0])
+		// replies to the user are only visible to them
+		assert.True(t, strings.HasPrefix(messages[1], "C1/1.1 to U2: <@U1|u1> is deploying first "), messages[1])
+		assert.True(t, strings.HasPrefix(messages[2], "C1/ to U2: <@U1|u1> is deploying"), messages[2])
+	}
+
+	if d, ok := deploy.NewChannelDeploys(store).Current(deploy.Channel{ID: "C1"}); assert.True(t, ok) {
+		assert.Equal(t, slack.User{ID: "U1", Name: "u1"}, d.User)
+		assert.Equal(t, "first &amp; &lt;only&gt; deploy", d.Subject)
+	}
+}
+
+func TestBot_ServeEvents_Retry(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ioutil.ReadAll(r.Body)
+
+		mu.Lock()
+		requests++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	b := bot.New("", "", deploy.NewInMemoryStore())
+	b.EnableMentions(api)
+
+	deliver := func(eventID, retry string) int {
+		req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"type":"event_callback","event_id":"`+eventID+`","event":{"type":"app_mention","user":"U1","text":"<@U0BOT> done","channel":"C1","ts":"1.1"}}`))
+		if retry != "" {
+			req.Header.Set("X-Slack-Retry-Num", retry)
+		}
+
+		rec := httptest.NewRecorder()
+		b.ServeEvents(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		n := requests
+		requests = 0
+
+		return n
+	}
+
+	assert.NotZero(t, deliver("Ev1", ""))
+
+	// the event has been handled already
+	assert.Zero(t, deliver("Ev1", "1"))
+
+	// the original delivery has not reached the bot
+	assert.NotZero(t, deliver("Ev2", "1"))
+	assert.Zero(t, deliver("Ev2", "2"))
+}
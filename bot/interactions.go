@@ -0,0 +1,144 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+)
+
+const (
+	deployActionsBlockID = "deploy.actions"
+	deployDoneAction     = "deploy.done"
+	deployAbortAction    = "deploy.abort"
+	deployJoinAction     = "deploy.join"
+)
+
+// deployActionValue is passed as a value of the deploy announcement buttons
+type deployActionValue struct {
+	Environment string
+	// StartedAt is used to make sure that the button is clicked for the deploy it has been shown for
+	StartedAt time.Time
+}
+
+// ServeInteraction handles interaction payloads sent by Slack when users click buttons in deploy announcements.
+func (b *Bot) ServeInteraction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, "Only POST requests are supported", http.StatusBadRequest)
+		return
+	}
+
+	interaction, err := slack.ParseInteraction(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if b.slackToken != "" && interaction.Token != b.slackToken {
+		http.Error(w, "Invalid token", http.StatusForbidden)
+		return
+	}
+
+	switch interaction.Type {
+	case slack.InteractionTypeBlockActions:
+		w.Write(nil)
+
+		// Slack gets the acknowledgement only after ServeInteraction returns, so actions are handled in background
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), triggerIDTimeout)
+			defer cancel()
+
+			b.handleBlockActions(ctx, interaction)
+		}()
+	case slack.InteractionTypeViewSubmission:
+		if interaction.View.CallbackID != deployModalCallbackID {
+			w.Write(nil)
+			return
+		}
+
+		b.handleDeployModalSubmission(w, interaction)
+	default:
+		w.Write(nil)
+	}
+}
+
+func (b *Bot) handleBlockActions(ctx context.Context, interaction slack.Interaction) {
+	resp := interactionResponder{interaction.ResponseURL}
+	for _, action := range interaction.Actions {
+		var v deployActionValue
+		if err := json.Unmarshal([]byte(action.Value), &v); err != nil {
+			log.Printf("malformed value of %s action in %s: %s", action.ActionID, interaction.ChannelID, err)
+			continue
+		}
+
+		ch := b.channel(interaction.TeamID, interaction.ChannelID)
+		ch.Environment = v.Environment
+
+		switch action.ActionID {
+		case deployDoneAction, deployAbortAction:
+			d, ok := b.deploys.Current(ch)
+			if !ok || !d.StartedAt.Equal(v.StartedAt) {
+				resp.Respond(b.userResponses(ch, interaction.User).DeployIsOverMessage())
+				continue
+			}
+
+			if d.User.ID != interaction.User.ID {
+				resp.Respond(b.userResponses(ch, interaction.User).NotDeployOwnerMessage(d))
+				continue
+			}
+
+			if action.ActionID == deployDoneAction {
+				b.finishDeploy(ch, interaction.User, resp)
+			} else {
+				b.abortDeploy(ch, "", interaction.User, resp)
+			}
+		case deployJoinAction:
+			if b.modalAPI != nil && interaction.TriggerID != "" {
+				err := b.openDeployModal(ctx, interaction.TriggerID, ch, interaction.User)
+				if err == nil {
+					continue
+				}
+
+				log.Printf("failed to open deploy modal for %s: %s", interaction.User.Name, err)
+			}
+
+			// a deploy can't be scheduled without knowing what is going to be deployed
+			resp.Respond(b.userResponses(ch, interaction.User).JoinQueueMessage())
+		default:
+			log.Printf("unknown action %s in %s", action.ActionID, interaction.ChannelID)
+		}
+	}
+}
+
+// interactionResponder sends all responses to the interaction response_url.
+type interactionResponder struct {
+	responseURL string
+}
+
+func (resp interactionResponder) Respond(response *slack.Response) {
+	go postResponse(resp.responseURL, response)
+}
+
+func (resp interactionResponder) Announce(response *slack.Response) {
+	go postResponse(resp.responseURL, response)
+}
+
+// deployActionsBlock returns the block with buttons to finish, abort or join the queue after the deploy d. Button
+// labels are translated into locale.
+func deployActionsBlock(locale string, d deploy.Deploy) slack.Block {
+	value, err := json.Marshal(deployActionValue{Environment: d.Environment, StartedAt: d.StartedAt})
+	if err != nil {
+		log.Printf("failed to marshal deploy action value: %s", err)
+	}
+
+	return slack.NewActionsBlock(
+		deployActionsBlockID,
+		slack.NewButton(deployDoneAction, translate(locale, deployDoneButton), string(value), slack.ButtonStylePrimary),
+		slack.NewButton(deployAbortAction, translate(locale, deployAbortButton), string(value), slack.ButtonStyleDanger),
+		slack.NewButton(deployJoinAction, translate(locale, deployJoinButton), string(value), ""),
+	)
+}
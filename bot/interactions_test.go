@@ -0,0 +1,132 @@
+package bot_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/github"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot_ServeInteraction(t *testing.T) {
+	const slackToken = "slack-token"
+
+	type response struct {
+		Text         string `json:"text"`
+		ResponseType string `json:"response_type"`
+	}
+
+	var (
+		mu        sync.Mutex
+		responses []response
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp response
+		body, _ := ioutil.ReadAll(r.Body)
+		require.NoError(t, json.Unmarshal(body, &resp), string(body))
+
+		mu.Lock()
+		responses = append(responses, resp)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	flushResponses := func() []response {
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		rs := responses
+		responses = nil
+
+		return rs
+	}
+
+	store := deploy.NewInMemoryStore()
+	deploys := deploy.NewChannelDeploys(store)
+
+	d, err := deploys.Start(deploy.Channel{ID: "C1"}, deploy.New(slack.User{ID: "U1", Name: "user1"}, "first deploy"))
+	require.NoError(t, err)
+
+	value := bot.NewResponseBuilder(github.NewClient("", nil)).DeployAnnouncement(d).Blocks[1].Elements[0].Value
+
+	b := bot.New(slackToken, "", store)
+
+	click := func(token, userID, actionID string) int {
+		payload := fmt.Sprintf(
+			`{"type":"block_actions","token":%q,"user":{"id":%q,"username":%q},"channel":{"id":"C1"},"response_url":%q,"actions":[{"action_id":%q,"value":%q}]}`,
+			token, userID, strings.ToLower(userID), server.URL, actionID, value,
+		)
+
+		req := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(url.Values{"payload": {payload}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeInteraction(rec, req)
+
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, click("invalid-token", "U1", "deploy.done"))
+
+	// other users are not allowed to finish the deploy
+	require.Equal(t, http.StatusOK, click(slackToken, "U2", "deploy.done"))
+	if rs := flushResponses(); assert.Len(t, rs, 1) {
+		assert.Empty(t, rs[0].ResponseType)
+		assert.Contains(t, rs[0].Text, "Only <@U1|user1> can finish this deploy")
+	}
+
+	_, ok := deploys.Current(deploy.Channel{ID: "C1"})
+	assert.True(t, ok)
+
+	// joining the queue requires a subject, which is asked for in the deploy modal only
+	require.Equal(t, http.StatusOK, click(slackToken, "U2", "deploy.join"))
+	if rs := flushResponses(); assert.Len(t, rs, 1) {
+		assert.Empty(t, rs[0].ResponseType)
+		assert.Contains(t, rs[0].Text, "Type `/deploy <subject>` in the channel to join the deploy queue")
+	}
+
+	_, err = deploys.Start(deploy.Channel{ID: "C1"}, deploy.New(slack.User{ID: "U2", Name: "u2"}, "second deploy"))
+	require.Error(t, err)
+
+	// the deploy owner finishes the deploy
+	require.Equal(t, http.StatusOK, click(slackToken, "U1", "deploy.done"))
+	if rs := flushResponses(); assert.Len(t, rs, 2) {
+		var texts []string
+		for _, r := range rs {
+			assert.Equal(t, "in_channel", r.ResponseType)
+			texts = append(texts, r.Text)
+		}
+
+		assert.Contains(t, texts, "<@U1|u1> done deploying")
+		assert.Contains(t, texts, "<@U2|u2> is about to deploy second deploy")
+	}
+
+	if current, ok := deploys.Current(deploy.Channel{ID: "C1"}); assert.True(t, ok) {
+		assert.Equal(t, "U2", current.User.ID)
+	}
+
+	// the button of a finished deploy does nothing
+	require.Equal(t, http.StatusOK, click(slackToken, "U2", "deploy.abort"))
+	if rs := flushResponses(); assert.Len(t, rs, 1) {
+		assert.Contains(t, rs[0].Text, "This deploy is already over")
+	}
+
+	if current, ok := deploys.Current(deploy.Channel{ID: "C1"}); assert.True(t, ok) {
+		assert.Equal(t, "U2", current.User.ID)
+	}
+}
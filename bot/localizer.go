@@ -0,0 +1,130 @@
+package bot
+
+import (
+	"errors"
+	"time"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+)
+
+// Localizer picks the language of bot messages. Messages posted in a channel use the locale chosen for
+// the channel, falling back to the one of its workspace. Messages addressed to a single user follow the
+// language the user has chosen in Slack if it is supported.
+type Localizer struct {
+	deploys    *deploy.ChannelDeploys
+	workspaces deploy.WorkspaceSettingsStore
+}
+
+func NewLocalizer(store deploy.Store) *Localizer {
+	l := &Localizer{deploys: deploy.NewChannelDeploys(store)}
+
+	// workspace settings are only available if the store is able to keep them
+	l.workspaces, _ = store.(deploy.WorkspaceSettingsStore)
+
+	return l
+}
+
+// ChannelLocale returns the language of messages posted in channel. The workspace locale is returned
+// for messages that do not belong to any channel, such as the app home, if the channel ID is empty.
+func (l *Localizer) ChannelLocale(ch deploy.Channel) string {
+	if ch.ID == "" {
+		return l.WorkspaceLocale(ch.TeamID)
+	}
+
+	if locale := l.deploys.Locale(ch); locale != "" {
+		return locale
+	}
+
+	return l.WorkspaceLocale(ch.TeamID)
+}
+
+// UserLocale returns the language of messages sent to user in channel. The user locale is only known for users
+// returned by the team directory.
+func (l *Localizer) UserLocale(ch deploy.Channel, user slack.User) string {
+	if locale, err := ParseLocale(user.Locale); err == nil {
+		return locale
+	}
+
+	return l.ChannelLocale(ch)
+}
+
+// WorkspaceLocale returns the language of messages in channels of the workspace that have no locale of their own.
+func (l *Localizer) WorkspaceLocale(teamID string) string {
+	if l.workspaces == nil {
+		return DefaultLocale
+	}
+
+	if locale := l.workspaces.GetWorkspaceSettings(teamID).Locale; locale != "" {
+		return locale
+	}
+
+	return DefaultLocale
+}
+
+// SetChannelLocale changes the language of messages in all environments of the channel. Passing an empty string
+// resets it to the workspace locale.
+func (l *Localizer) SetChannelLocale(ch deploy.Channel, locale string) {
+	l.deploys.SetLocale(ch, locale)
+}
+
+// SetWorkspaceLocale changes the language of messages in all channels of the workspace that have no locale
+// of their own.
+func (l *Localizer) SetWorkspaceLocale(teamID, locale string) error {
+	if l.workspaces == nil {
+		return errors.New("not supported")
+	}
+
+	settings := l.workspaces.GetWorkspaceSettings(teamID)
+	settings.Locale = locale
+	l.workspaces.SetWorkspaceSettings(teamID, settings)
+
+	return nil
+}
+
+// userLocaleTimeout limits the time spent on looking up the user locale in the team directory. Messages are sent
+// in the channel language if the lookup takes longer.
+const userLocaleTimeout = 500 * time.Millisecond
+
+// withSlackLocale returns user along with the language they have chosen in Slack, as known to the team directory.
+// The user is returned as is if the locale is already known, e.g. from an interaction payload, or if the directory
+// is not available or does not respond in time.
+func withSlackLocale(clients *workspaceClients, teamID string, user slack.User) slack.User {
+	if clients == nil || user.Locale != "" {
+		return user
+	}
+
+	users, err := clients.TeamDirectory(teamID)
+	if err != nil {
+		return user
+	}
+
+	found := make(chan slack.User, 1)
+	go func() {
+		// the lookup keeps running after the timeout to have the directory ready for the next call
+		if u, err := users.FetchByID(user.ID); err == nil {
+			found <- u
+		}
+		close(found)
+	}()
+
+	select {
+	case u, ok := <-found:
+		if ok {
+			return u
+		}
+	case <-time.After(userLocaleTimeout):
+	}
+
+	return user
+}
+
+// recipientLocale returns the language of direct messages sent to user about deploys in channel. The default
+// locale is used if locales are not provided.
+func recipientLocale(locales *Localizer, clients *workspaceClients, ch deploy.Channel, user slack.User) string {
+	if locales == nil {
+		return DefaultLocale
+	}
+
+	return locales.UserLocale(ch, withSlackLocale(clients, ch.TeamID, user))
+}
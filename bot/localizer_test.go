@@ -0,0 +1,215 @@
+package bot_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/github"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalizer(t *testing.T) {
+	store := deploy.NewInMemoryStore()
+	l := bot.NewLocalizer(store)
+
+	ch := deploy.Channel{TeamID: "T1", ID: "C1", Environment: "staging"}
+
+	assert.Equal(t, bot.DefaultLocale, l.WorkspaceLocale("T1"))
+	assert.Equal(t, bot.DefaultLocale, l.ChannelLocale(ch))
+
+	require.NoError(t, l.SetWorkspaceLocale("T1", "es"))
+	assert.Equal(t, "es", l.WorkspaceLocale("T1"))
+	assert.Equal(t, bot.DefaultLocale, l.WorkspaceLocale("T2"))
+	assert.Equal(t, "es", l.ChannelLocale(ch))
+	assert.Equal(t, "es", l.ChannelLocale(deploy.Channel{TeamID: "T1"}))
+
+	// the channel locale applies to all environments
+	l.SetChannelLocale(deploy.Channel{TeamID: "T1", ID: "C1"}, "de")
+	assert.Equal(t, "de", l.ChannelLocale(ch))
+	assert.Equal(t, "es", l.ChannelLocale(deploy.Channel{TeamID: "T1", ID: "C2"}))
+
+	// the Slack locale of the user takes precedence if it is supported
+	assert.Equal(t, "en", l.UserLocale(ch, slack.User{ID: "U1", Locale: "en-US"}))
+	assert.Equal(t, "de", l.UserLocale(ch, slack.User{ID: "U1", Locale: "fr-FR"}))
+	assert.Equal(t, "de", l.UserLocale(ch, slack.User{ID: "U1"}))
+
+	l.SetChannelLocale(ch, "")
+	assert.Equal(t, "es", l.ChannelLocale(ch))
+}
+
+func TestBot_Locale(t *testing.T) {
+	const slackToken = "slack-token"
+
+	var (
+		mu            sync.Mutex
+		announcements []string
+	)
+
+	responseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp struct {
+			Text string `json:"text"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&resp))
+
+		mu.Lock()
+		announcements = append(announcements, resp.Text)
+		mu.Unlock()
+	}))
+	defer responseServer.Close()
+
+	store := deploy.NewInMemoryStore()
+	b := bot.New(slackToken, "", store)
+
+	command := func(channelID, text string) string {
+		form := url.Values{
+			"token":        {slackToken},
+			"command":      {"/deploy"},
+			"channel_id":   {channelID},
+			"user_id":      {"U1"},
+			"user_name":    {"u1"},
+			"text":         {text},
+			"response_url": {responseServer.URL},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp slack.Response
+		if rec.Body.Len() > 0 {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		}
+
+		return resp.Text
+	}
+
+	assert.Contains(t, command("C1", "locale"), "Bot messages in this channel are in English")
+	assert.Contains(t, command("C1", "locale fr"), "unsupported language")
+
+	assert.Equal(t, "Los mensajes del bot en este espacio de trabajo estarán en Español, salvo que se haya elegido otro idioma para el canal", command("C1", "locale workspace es"))
+	assert.Equal(t, "Nadie está desplegando en este momento", command("C2", "status"))
+
+	command("C1", "locale de-DE")
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Equal(t, "Zurzeit deployt niemand", command("C1", "status"))
+	assert.Equal(t, "Nadie está desplegando en este momento", command("C2", "status"))
+
+	command("C1", "locale default")
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Equal(t, "Nadie está desplegando en este momento", command("C1", "status"))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{
+		"<@U1|u1> hat die Sprache der Bot-Nachrichten in diesem Channel auf Deutsch geändert",
+		"<@U1|u1> ha cambiado el idioma de los mensajes del bot en este canal a Español",
+	}, announcements)
+}
+
+func TestBot_Locale_SlackUserLocale(t *testing.T) {
+	const slackToken = "slack-token"
+
+	release := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"U1","name":"u1","locale":"de-DE"}]}`)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	api := slack.NewWebAPI("xoxb-token", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	d, err := deploy.NewChannelDeploys(store).Start(deploy.Channel{ID: "C1"}, deploy.New(slack.User{ID: "U3", Name: "u3"}, "first deploy"))
+	require.NoError(t, err)
+
+	value := bot.NewResponseBuilder(github.NewClient("", nil)).DeployAnnouncement(d).Blocks[1].Elements[0].Value
+
+	b := bot.New(slackToken, "", store)
+	b.EnableTeamDirectory(api)
+
+	command := func(userID, text string) string {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp slack.Response
+		if rec.Body.Len() > 0 {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		}
+
+		return resp.Text
+	}
+
+	responses := make(chan string, 1)
+	responseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp slack.Response
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&resp))
+
+		responses <- resp.Text
+	}))
+	defer responseServer.Close()
+
+	// the locale sent in the interaction payload is used as is
+	payload := fmt.Sprintf(
+		`{"type":"block_actions","token":%q,"user":{"id":"U2","username":"u2","locale":"es-ES"},"channel":{"id":"C1"},"response_url":%q,"actions":[{"action_id":"deploy.join","value":%q}]}`,
+		slackToken, responseServer.URL, value,
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(url.Values{"payload": {payload}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	b.ServeInteraction(httptest.NewRecorder(), req)
+
+	select {
+	case text := <-responses:
+		assert.Equal(t, "Escribe `/deploy <subject>` en el canal para unirte a la cola de deploys", text)
+	case <-time.After(time.Second):
+		t.Error("no response to the interaction has been sent")
+	}
+
+	// the channel locale is used while the team directory is busy
+	assert.Contains(t, command("U1", "status"), "is deploying first deploy")
+
+	close(release)
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Contains(t, command("U1", "status"), "deployt first deploy")
+}
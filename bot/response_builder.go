@@ -3,6 +3,7 @@ package bot
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,60 +16,199 @@ const (
 	helpMessage = `Available commands:
 
 /deploy help — print help (this message)
+/deploy — open a dialog to start a deploy, if available
 /deploy <subject> — announce deploy of <subject> in channel
+/deploy edit <subject> — change the subject of your running or scheduled deploy
 /deploy status — show deploy status in channel
 /deploy done — finish deploy
 /deploy abort [<reason>] — abort current deploy, optionally providing a reason
-/deploy history — get a link to history of deploys in this channel`
-	errorMessage                   = "`%s` returned an error %s"
-	noRunningDeploysMessage        = "No one is deploying at the moment"
-	singleDeployStatusMessage      = "%s is deploying %s since %s. There are no other deploys scheduled yet."
-	deployQueueStatusMessage       = "%s is deploying %s since %s. The queue:\n %s"
-	alreadyInQueueMessage          = "%s is already in queue"
-	deployConflictMessage          = "%s is deploying since %s, your PR has been added to the queue. You can type `/deploy done` if you think the current deploy is finished or type `/deploy status` to print the queue."
-	deployDoneMessage              = "%s done deploying"
-	deployInterruptedMessage       = "%s has finished the deploy started by %s"
-	deployAnnouncementMessage      = "%s is about to deploy %s"
-	deployHistoryLinkMessage       = "Click <http://%s/%s|here> to see deploy history in this channel"
-	deployAbortedMessage           = "%s has aborted the deploy"
-	deployAbortedWithReasonMessage = "%s has aborted the deploy (%s)"
-	userLeftQueueMessage           = "Your scheduled deploy has been cancelled"
-	userIsNotInQueueMessage        = "You are not in the queue"
+/deploy history — get a link to history of deploys in this channel
+/deploy lock[: <reason>] — prevent new deploys from being started in this channel, optionally providing a reason, e.g. /deploy lock: release in progress
+/deploy unlock — allow deploys in this channel again
+/deploy freeze — list recurring deploy freezes in this channel
+/deploy freeze add <rule> — add a deploy freeze, e.g. Fri 15:00 - Mon 08:00 Europe/Berlin or 2016-12-24 - 2017-01-02 Europe/Berlin
+/deploy freeze remove <number> — remove a deploy freeze
+/deploy urgent <subject> — announce deploy of <subject> in channel and put it right after the running one
+/deploy move @user <position> — change the position of user deploy in the queue, allowed to its owner and the user who is deploying now
+/deploy handover @user — make another user the owner of the running deploy
+/deploy notifications on|off — enable or disable direct messages sent to you when your queued deploy starts
+/deploy notify done|abort|both — choose whether you get a direct message when a deploy you are mentioned in is done, aborted or both
+/deploy subscribe [#channel] — get a direct message whenever a deploy is started, done or aborted in this or another channel
+/deploy unsubscribe [#channel] — stop getting direct messages about all deploys in the channel
+/deploy locale — show the language of bot messages in this channel
+/deploy locale <language>|default — change the language of bot messages in this channel, e.g. de, or reset it to the workspace one
+/deploy locale workspace <language> — change the language of bot messages in all channels of this workspace
+/deploy expiry — show what happens to deploys that have been running for too long
+/deploy expiry <warn> <announce> <abort> — remind the owner, post in channel and abort deploys after given time, e.g. 2h 4h 8h, use - to skip a step
+/deploy expiry off — disable the deploy expiry
+/deploy force <reason>: <subject> — announce deploy of <subject> in channel despite of an active deploy freeze, giving a reason
+
+If there are multiple environments configured, prefix any command with the environment name to deploy them separately,
+e.g. /deploy staging <subject> or /deploy staging done
+
+Subjects starting with abort, edit, urgent, move, handover, freeze, notify, subscribe, unsubscribe, locale, expiry or force
+are taken for commands, so /deploy move to k8s does not start a deploy. Rephrase such subjects, e.g. /deploy k8s move`
+	errorMessage                    = "`%s` returned an error %s"
+	noRunningDeploysMessage         = "No one is deploying at the moment"
+	singleDeployStatusMessage       = "%s is deploying %s since %s. There are no other deploys scheduled yet."
+	deployQueueStatusMessage        = "%s is deploying %s since %s. The queue:\n %s"
+	environmentDeployMessage        = "%s to %s"
+	alreadyInQueueMessage           = "%s is already in queue"
+	alreadyScheduledMessage         = "You already have a deploy in this channel. Type `/deploy edit <subject>` if you want to change its subject."
+	deployConflictMessage           = "%s is deploying since %s, your PR has been added to the queue%s. You can type `/deploy done` if you think the current deploy is finished or type `/deploy status` to print the queue."
+	queuePositionMessage            = " at position %d"
+	queueEstimateMessage            = " — expected to start in about %s"
+	queueEstimateNowMessage         = " — expected to start any moment now"
+	deployDoneMessage               = "%s done deploying"
+	deployInterruptedMessage        = "%s has finished the deploy started by %s"
+	deployAnnouncementMessage       = "%s is about to deploy %s"
+	deployHistoryLinkMessage        = "Click <http://%s/%s|here> to see deploy history in this channel"
+	deployAbortedMessage            = "%s has aborted the deploy"
+	deployAbortedWithReasonMessage  = "%s has aborted the deploy (%s)"
+	userLeftQueueMessage            = "Your scheduled deploy has been cancelled"
+	userIsNotInQueueMessage         = "You are not in the queue"
+	channelLockedMessage            = "Deploys in this channel have been locked by %s since %s. Run `/deploy unlock` once it is safe to deploy again."
+	channelLockedWithReasonMessage  = "Deploys in this channel have been locked by %s since %s (%s). Run `/deploy unlock` once it is safe to deploy again."
+	channelNotLockedMessage         = "Deploys in this channel are not locked"
+	channelLockedAnnouncement       = "%s has locked deploys in this channel"
+	channelLockedWithReason         = "%s has locked deploys in this channel (%s)"
+	channelUnlockedAnnouncement     = "%s has unlocked deploys in this channel"
+	deployFrozenMessage             = "Deploys in this channel are frozen (%s). Type `/deploy force <reason>: <subject>` if you really need to deploy now."
+	freezeOverrideMessage           = " :warning: ignoring the deploy freeze (%s)"
+	queuedDeployFrozenMessage       = ":warning: %s, your deploy has been started from the queue during the deploy freeze (%s). Type `/deploy abort` if it can wait until the freeze is over."
+	freezeOverrideWithReasonMessage = " :warning: ignoring the deploy freeze (%s), reason: %s"
+	freezeRulesMessage              = "Deploy freezes in this channel:\n%s"
+	noFreezeRulesMessage            = "There are no deploy freezes in this channel"
+	freezeRuleMessage               = "%d. %s"
+	activeFreezeRuleMessage         = "%d. %s (active)"
+	freezeRuleAddedMessage          = "%s has added a deploy freeze %s"
+	freezeRuleRemovedMessage        = "%s has removed the deploy freeze %s"
+	noSuchFreezeRuleMessage         = "There is no deploy freeze #%s, type `/deploy freeze` to see the list"
+	urgentDeployStatusMarker        = " :rotating_light: urgent"
+	urgentDeployQueuedMessage       = "%s has put an urgent deploy of %s right after the current deploy by %s"
+	deployMovedMessage              = "%s has moved the deploy by %s to position %d in the queue"
+	userHasNoQueuedDeploysMessage   = "%s has no deploys waiting in the queue"
+	moveNotAllowedMessage           = "Only %s or the user who is deploying now can move this deploy"
+	deployUpdatedMessage            = "%s has updated the subject of the deploy: %s"
+	scheduledDeployUpdatedMessage   = "%s has updated the subject of the scheduled deploy: %s"
+	turnNotificationsOnMessage      = "You will get a direct message when your queued deploy starts"
+	turnNotificationsOffMessage     = "You will no longer get direct messages when your queued deploy starts"
+	notifyOnDoneMessage             = "You will get a direct message when a deploy you are mentioned in is done in this channel"
+	notifyOnAbortMessage            = "You will get a direct message when a deploy you are mentioned in is aborted in this channel"
+	notifyOnBothMessage             = "You will get a direct message when a deploy you are mentioned in is done or aborted in this channel"
+	subscribedMessage               = "You will get a direct message whenever a deploy is started, done or aborted in <#%s>"
+	unsubscribedMessage             = "You will no longer get direct messages about deploys in <#%s>"
+	expiryPolicyMessage             = "Deploy expiry policy in this channel: %s"
+	noExpiryPolicyMessage           = "Deploys in this channel never expire. Type `/deploy expiry <warn> <announce> <abort>`, e.g. `/deploy expiry 2h 4h 8h`, to change this."
+	expiryPolicyChangedMessage      = "%s has changed the deploy expiry policy: %s"
+	expiryPolicyDisabledMessage     = "%s has disabled the deploy expiry"
+	expiryWarnStep                  = "warn the owner after %s"
+	expiryAnnounceStep              = "post in channel after %s"
+	expiryAbortStep                 = "abort after %s"
+	deployExpiryWarningMessage      = "Your deploy %s in <#%s> was started %s ago. Are you still deploying?"
+	deployExpiryAnnouncement        = "%s has been deploying %s for %s already. Type `/deploy done` if the deploy is finished."
+	deployAutoAbortMessage          = " The deploy will be aborted automatically in %s."
+	deployExpiredMessage            = "The deploy of %s by %s has expired and been aborted"
+	deployHandedOverMessage         = "%s has handed over the deploy of %s to %s"
+	deployTakenOverMessage          = "%s has handed over the deploy of %s started by %s to %s"
+	deployFinishedSummary           = ":white_check_mark: %s has deployed %s in %s"
+	deployAbortedSummary            = ":x: The deploy of %s by %s has been aborted after %s"
+	deployAbortedWithReasonSummary  = ":x: The deploy of %s by %s has been aborted after %s (%s)"
+	expectedDurationMessage         = " (expected to take %s)"
+	deployIsOverMessage             = "This deploy is already over. Type `/deploy status` to see who is deploying now."
+	notDeployOwnerMessage           = "Only %s can finish this deploy with a button. Type `/deploy done` if you think the deploy is finished."
+	joinQueueMessage                = "Type `/deploy <subject>` in the channel to join the deploy queue"
+	appHomeHeader                   = "*Deploys in your channels*"
+	appHomeNoChannelsMessage        = "There are no deploys in channels you are a member of. Type `/deploy <subject>` in a channel to start one."
+	appHomeChannelTitle             = "*<#%s>*"
+	appHomeEnvironmentTitle         = "*<#%s>* %s"
+	appHomeCurrentDeployMessage     = "%s is deploying %s since %s"
+	appHomeQueueLengthMessage       = "%d deploy is waiting in the queue"
+	appHomeQueueLengthPluralMessage = "%d deploys are waiting in the queue"
+	appHomeOwnDeployMessage         = "You are deploying now"
+	appHomeOwnPositionMessage       = "Your deploy is at position %d in the queue"
+	crossPostedMessage              = "%s (from <#%s>)"
+	localeMessage                   = "Bot messages in this channel are in %s. Type `/deploy locale <language>` to change this, available languages: %s"
+	localeChangedAnnouncement       = "%s has changed the language of bot messages in this channel to %s"
+	workspaceLocaleChangedMessage   = "Bot messages in this workspace will be in %s, unless another language has been chosen for the channel"
+)
+
+// Deploy modal labels
+const (
+	deployModalTitle                   = "Start a deploy"
+	deployModalSubmit                  = "Deploy"
+	deployModalSubjectLabel            = "What are you deploying?"
+	deployModalSubjectPlaceholder      = "e.g. New signup page"
+	deployModalEnvironmentLabel        = "Environment"
+	deployModalDefaultEnvironment      = "default"
+	deployModalPullRequestsLabel       = "Pull requests"
+	deployModalPullRequestsPlaceholder = "GitHub pull request URLs, one per line"
+	deployModalNotifyLabel             = "Notify when done"
+	deployModalNotifyPlaceholder       = "Select people"
+	deployModalDurationLabel           = "Expected duration"
+	deployModalDurationPlaceholder     = "e.g. 30m"
+
+	deployDoneButton  = "Done"
+	deployAbortButton = "Abort"
+	deployJoinButton  = "Join queue"
 )
 
+// ResponseBuilder builds bot messages. The messages are in English unless another locale has been chosen
+// with WithLocale().
 type ResponseBuilder struct {
 	githubClient *github.Client
+	locale       string
 }
 
 func NewResponseBuilder(githubClient *github.Client) *ResponseBuilder {
-	return &ResponseBuilder{githubClient: githubClient}
+	return &ResponseBuilder{githubClient: githubClient, locale: DefaultLocale}
+}
+
+// WithLocale returns a copy of the builder that translates messages into locale.
+func (b *ResponseBuilder) WithLocale(locale string) *ResponseBuilder {
+	localized := *b
+	localized.locale = locale
+
+	return &localized
+}
+
+// t translates the English message into the builder locale.
+func (b *ResponseBuilder) t(msg string) string {
+	return translate(b.locale, msg)
+}
+
+// plural returns the translation of the message form that matches n.
+func (b *ResponseBuilder) plural(n int, one, other string) string {
+	return translatePlural(b.locale, n, one, other)
 }
 
 func (b *ResponseBuilder) HelpMessage() *slack.Response {
-	return newUserMessage(slack.EscapeMessage(helpMessage))
+	return newUserMessage(slack.EscapeMessage(b.t(helpMessage)))
 }
 
 func (b *ResponseBuilder) ErrorMessage(cmd string, err error) *slack.Response {
-	return newUserMessage(fmt.Sprintf(errorMessage, cmd, err))
+	return newUserMessage(fmt.Sprintf(b.t(errorMessage), cmd, err))
 }
 
 func (b *ResponseBuilder) NoRunningDeploysMessage() *slack.Response {
-	return newUserMessage(slack.EscapeMessage(noRunningDeploysMessage))
+	return newUserMessage(slack.EscapeMessage(b.t(noRunningDeploysMessage)))
 }
 
 func (b *ResponseBuilder) UserLeftTheQueueMessage() *slack.Response {
-	return newUserMessage(slack.EscapeMessage(userLeftQueueMessage))
+	return newUserMessage(slack.EscapeMessage(b.t(userLeftQueueMessage)))
 }
 
 func (b *ResponseBuilder) NotInTheQueueMessage() *slack.Response {
-	return newUserMessage(slack.EscapeMessage(userIsNotInQueueMessage))
+	return newUserMessage(slack.EscapeMessage(b.t(userIsNotInQueueMessage)))
 }
 
-func (b *ResponseBuilder) DeployStatusMessage(deploys []deploy.Deploy) *slack.Response {
+// DeployStatusMessage returns the list of running and scheduled deploys. The estimated start time is added
+// for each deploy in the queue if estimates are provided.
+func (b *ResponseBuilder) DeployStatusMessage(deploys []deploy.Deploy, estimates []time.Time) *slack.Response {
 	if len(deploys) == 1 {
 		d := deploys[0]
 
-		return newUserMessage(fmt.Sprintf(singleDeployStatusMessage, d.User, slack.EscapeMessage(d.Subject), d.StartedAt.Format(time.RFC822)))
+		return newUserMessage(fmt.Sprintf(b.t(singleDeployStatusMessage), d.User, deploySubject(b.locale, slack.EscapeMessage(d.Subject), d)+b.freezeOverrideNotice(d), d.StartedAt.Format(time.RFC822)))
 	} else {
 		current := deploys[0]
 		rest := deploys[1:]
@@ -76,29 +216,200 @@ func (b *ResponseBuilder) DeployStatusMessage(deploys []deploy.Deploy) *slack.Re
 
 		for i, d := range rest {
 			users[i] = fmt.Sprintf("%d. %s [%s]", i+1, d.User, d.Subject)
+			if d.Priority {
+				users[i] += b.t(urgentDeployStatusMarker)
+			}
+			users[i] += b.freezeOverrideNotice(d)
+
+			if len(estimates) > i+1 {
+				users[i] += b.startEstimate(estimates[i+1])
+			}
 		}
 
 		return newUserMessage(
-			fmt.Sprintf(deployQueueStatusMessage, current.User, slack.EscapeMessage(current.Subject), current.StartedAt.Format(time.RFC822), strings.Join(users, "\n")),
+			fmt.Sprintf(b.t(deployQueueStatusMessage), current.User, deploySubject(b.locale, slack.EscapeMessage(current.Subject), current)+b.freezeOverrideNotice(current), current.StartedAt.Format(time.RFC822), strings.Join(users, "\n")),
 		)
 	}
 }
 
-func (b *ResponseBuilder) DeployInProgressMessage(d deploy.Deploy) *slack.Response {
-	return newUserMessage(fmt.Sprintf(deployConflictMessage, d.User, d.StartedAt.Format(time.RFC822)))
+// DeployInProgressMessage returns the response sent to a user whose deploy has been put into the queue at pos.
+// The estimate is omitted if it's zero.
+func (b *ResponseBuilder) DeployInProgressMessage(current deploy.Deploy, pos int, estimate time.Time) *slack.Response {
+	queueInfo := fmt.Sprintf(b.t(queuePositionMessage), pos)
+	if !estimate.IsZero() {
+		queueInfo += b.startEstimate(estimate)
+	}
+
+	return newUserMessage(fmt.Sprintf(b.t(deployConflictMessage), current.User, current.StartedAt.Format(time.RFC822), queueInfo))
 }
 
 func (b *ResponseBuilder) UserIsInQeueueMessage(u slack.User) *slack.Response {
-	return newUserMessage(fmt.Sprintf(alreadyInQueueMessage, u))
+	return newUserMessage(fmt.Sprintf(b.t(alreadyInQueueMessage), u))
+}
+
+func (b *ResponseBuilder) DeployAlreadyScheduledMessage() *slack.Response {
+	return newUserMessage(b.t(alreadyScheduledMessage))
 }
 
 func (b *ResponseBuilder) DeployInterruptedAnnouncement(d deploy.Deploy, user slack.User) *slack.Response {
-	return newAnnouncement(fmt.Sprintf(deployInterruptedMessage, user, d.User))
+	return newAnnouncement(fmt.Sprintf(b.t(deployInterruptedMessage), user, d.User))
 }
 
 func (b *ResponseBuilder) DeployAnnouncement(d deploy.Deploy) *slack.Response {
-	responseText := fmt.Sprintf(deployAnnouncementMessage, d.User, d.Subject)
+	responseText := fmt.Sprintf(b.t(deployAnnouncementMessage), d.User, deploySubject(b.locale, d.Subject, d))
+	if d.ExpectedDuration > 0 {
+		responseText += fmt.Sprintf(b.t(expectedDurationMessage), d.ExpectedDuration)
+	}
+
+	responseText += b.freezeOverrideNotice(d)
+
 	response := newAnnouncement(responseText)
+	response.Blocks = []slack.Block{
+		slack.NewSectionBlock(responseText),
+		deployActionsBlock(b.locale, d),
+	}
+
+	return b.withPullRequests(response, d)
+}
+
+// DeploySummary returns the final state of a finished deploy that replaces its announcement.
+func (b *ResponseBuilder) DeploySummary(d deploy.Deploy) *slack.Response {
+	var (
+		subject  = deploySubject(b.locale, d.Subject, d)
+		duration = d.FinishedAt.Sub(d.StartedAt).Round(time.Second)
+		text     string
+	)
+
+	switch {
+	case !d.Aborted:
+		text = fmt.Sprintf(b.t(deployFinishedSummary), d.User, subject, duration)
+	case d.AbortReason != "":
+		text = fmt.Sprintf(b.t(deployAbortedWithReasonSummary), subject, d.User, duration, abortReason(b.locale, d))
+	default:
+		text = fmt.Sprintf(b.t(deployAbortedSummary), subject, d.User, duration)
+	}
+
+	response := newAnnouncement(text)
+	// replace the announcement blocks to remove buttons
+	response.Blocks = []slack.Block{slack.NewSectionBlock(text)}
+
+	return b.withPullRequests(response, d)
+}
+
+// CrossPostedAnnouncement returns a copy of the deploy announcement to be posted into another channel with a link
+// back to the channel the deploy is running in. The buttons are removed, since they act on the channel they are posted in.
+func (b *ResponseBuilder) CrossPostedAnnouncement(channelID string, response *slack.Response) *slack.Response {
+	text := fmt.Sprintf(b.t(crossPostedMessage), response.Text, channelID)
+
+	crossPosted := newAnnouncement(text)
+	crossPosted.Blocks = []slack.Block{slack.NewSectionBlock(text)}
+	crossPosted.Attachments = response.Attachments
+
+	return crossPosted
+}
+
+// DeployModal returns the modal to start a deploy. The environment select is only added if there are environments
+// configured, env is selected by default.
+func (b *ResponseBuilder) DeployModal(env string, envs []string) slack.View {
+	blocks := []slack.Block{
+		slack.NewInputBlock(deployModalSubjectBlock, b.t(deployModalSubjectLabel), slack.NewTextInput(deployModalInputAction, b.t(deployModalSubjectPlaceholder), false), false),
+	}
+
+	if len(envs) > 0 {
+		options := []slack.Option{slack.NewOption(b.t(deployModalDefaultEnvironment), defaultEnvironmentValue)}
+		for _, name := range envs {
+			options = append(options, slack.NewOption(name, name))
+		}
+
+		el := slack.NewStaticSelect(deployModalInputAction, options...)
+		for i := range options {
+			if options[i].Value == env {
+				el.InitialOption = &options[i]
+			}
+		}
+
+		blocks = append(blocks, slack.NewInputBlock(deployModalEnvironmentBlock, b.t(deployModalEnvironmentLabel), el, false))
+	}
+
+	blocks = append(blocks,
+		slack.NewInputBlock(deployModalPullRequestsBlock, b.t(deployModalPullRequestsLabel), slack.NewTextInput(deployModalInputAction, b.t(deployModalPullRequestsPlaceholder), true), true),
+		slack.NewInputBlock(deployModalNotifyBlock, b.t(deployModalNotifyLabel), slack.NewUsersSelect(deployModalInputAction, b.t(deployModalNotifyPlaceholder)), true),
+		slack.NewInputBlock(deployModalDurationBlock, b.t(deployModalDurationLabel), slack.NewTextInput(deployModalInputAction, b.t(deployModalDurationPlaceholder), false), true),
+	)
+
+	return slack.NewModal(deployModalCallbackID, b.t(deployModalTitle), b.t(deployModalSubmit), blocks...)
+}
+
+// AppHomeView returns the app home tab showing the running deploy and the queue in each channel to the user.
+func (b *ResponseBuilder) AppHomeView(userID string, queues map[deploy.Channel][]deploy.Deploy) slack.View {
+	if len(queues) == 0 {
+		return slack.NewHomeView(slack.NewSectionBlock(b.t(appHomeHeader)), slack.NewSectionBlock(b.t(appHomeNoChannelsMessage)))
+	}
+
+	channels := make([]deploy.Channel, 0, len(queues))
+	for ch := range queues {
+		channels = append(channels, ch)
+	}
+	sort.Slice(channels, func(i, j int) bool { return channels[i].Key() < channels[j].Key() })
+
+	blocks := []slack.Block{slack.NewSectionBlock(b.t(appHomeHeader))}
+	for _, ch := range channels {
+		lines := []string{fmt.Sprintf(b.t(appHomeChannelTitle), ch.ID)}
+		if ch.Environment != "" {
+			lines[0] = fmt.Sprintf(b.t(appHomeEnvironmentTitle), ch.ID, ch.Environment)
+		}
+
+		deploys := queues[ch]
+		if len(deploys) == 0 {
+			lines = append(lines, b.t(noRunningDeploysMessage))
+		} else {
+			current := deploys[0]
+			lines = append(lines,
+				fmt.Sprintf(b.t(appHomeCurrentDeployMessage), current.User, slack.EscapeMessage(current.Subject), current.StartedAt.Format(time.RFC822)),
+				fmt.Sprintf(b.plural(len(deploys)-1, appHomeQueueLengthMessage, appHomeQueueLengthPluralMessage), len(deploys)-1),
+			)
+		}
+
+		for i, d := range deploys {
+			if d.User.ID != userID {
+				continue
+			}
+
+			if i == 0 {
+				lines = append(lines, b.t(appHomeOwnDeployMessage))
+			} else {
+				lines = append(lines, fmt.Sprintf(b.t(appHomeOwnPositionMessage), i))
+			}
+		}
+
+		blocks = append(blocks, slack.NewDividerBlock(), slack.NewSectionBlock(strings.Join(lines, "\n")))
+	}
+
+	return slack.NewHomeView(blocks...)
+}
+
+func (b *ResponseBuilder) DeployIsOverMessage() *slack.Response {
+	return newUserMessage(b.t(deployIsOverMessage))
+}
+
+func (b *ResponseBuilder) NotDeployOwnerMessage(d deploy.Deploy) *slack.Response {
+	return newUserMessage(fmt.Sprintf(b.t(notDeployOwnerMessage), d.User))
+}
+
+func (b *ResponseBuilder) JoinQueueMessage() *slack.Response {
+	return newUserMessage(b.t(joinQueueMessage))
+}
+
+func (b *ResponseBuilder) DeployUpdatedAnnouncement(d deploy.Deploy) *slack.Response {
+	if d.StartedAt.IsZero() {
+		return b.withPullRequests(newAnnouncement(fmt.Sprintf(b.t(scheduledDeployUpdatedMessage), d.User, deploySubject(b.locale, d.Subject, d))), d)
+	}
+
+	return b.withPullRequests(newAnnouncement(fmt.Sprintf(b.t(deployUpdatedMessage), d.User, deploySubject(b.locale, d.Subject, d))), d)
+}
+
+// withPullRequests adds the details of pull requests referenced in deploy subject as response attachments.
+func (b *ResponseBuilder) withPullRequests(response *slack.Response, d deploy.Deploy) *slack.Response {
 	for _, ref := range d.PullRequests {
 		pr, err := b.githubClient.GetPullRequest(ref.Repository, ref.ID)
 		if err != nil {
@@ -122,20 +433,185 @@ func (b *ResponseBuilder) DeployAnnouncement(d deploy.Deploy) *slack.Response {
 }
 
 func (b *ResponseBuilder) DeployDoneAnnouncement(user slack.User) *slack.Response {
-	return newAnnouncement(fmt.Sprintf(deployDoneMessage, user))
+	return newAnnouncement(fmt.Sprintf(b.t(deployDoneMessage), user))
 }
 
 func (b *ResponseBuilder) DeployAbortedAnnouncement(reason string, user slack.User) *slack.Response {
 	if reason != "" {
-		return newAnnouncement(fmt.Sprintf(deployAbortedWithReasonMessage, user, reason))
+		return newAnnouncement(fmt.Sprintf(b.t(deployAbortedWithReasonMessage), user, reason))
 	} else {
-		return newAnnouncement(fmt.Sprintf(deployAbortedMessage, user))
+		return newAnnouncement(fmt.Sprintf(b.t(deployAbortedMessage), user))
 	}
 }
 
-func (*ResponseBuilder) DeployHistoryLink(host, channelID, authToken string) *slack.Response {
+func (b *ResponseBuilder) ChannelLockedMessage(l deploy.Lock) *slack.Response {
+	if l.Reason != "" {
+		return newUserMessage(fmt.Sprintf(b.t(channelLockedWithReasonMessage), l.User, l.LockedAt.Format(time.RFC822), slack.EscapeMessage(l.Reason)))
+	}
+
+	return newUserMessage(fmt.Sprintf(b.t(channelLockedMessage), l.User, l.LockedAt.Format(time.RFC822)))
+}
+
+func (b *ResponseBuilder) ChannelNotLockedMessage() *slack.Response {
+	return newUserMessage(slack.EscapeMessage(b.t(channelNotLockedMessage)))
+}
+
+func (b *ResponseBuilder) ChannelLockedAnnouncement(l deploy.Lock) *slack.Response {
+	if l.Reason != "" {
+		return newAnnouncement(fmt.Sprintf(b.t(channelLockedWithReason), l.User, slack.EscapeMessage(l.Reason)))
+	}
+
+	return newAnnouncement(fmt.Sprintf(b.t(channelLockedAnnouncement), l.User))
+}
+
+func (b *ResponseBuilder) ChannelUnlockedAnnouncement(user slack.User) *slack.Response {
+	return newAnnouncement(fmt.Sprintf(b.t(channelUnlockedAnnouncement), user))
+}
+
+func (b *ResponseBuilder) UrgentDeployQueuedAnnouncement(d, current deploy.Deploy) *slack.Response {
+	return newAnnouncement(fmt.Sprintf(b.t(urgentDeployQueuedMessage), d.User, deploySubject(b.locale, d.Subject, d), current.User))
+}
+
+func (b *ResponseBuilder) DeployMovedAnnouncement(d deploy.Deploy, pos int, user slack.User) *slack.Response {
+	return newAnnouncement(fmt.Sprintf(b.t(deployMovedMessage), user, d.User, pos))
+}
+
+func (b *ResponseBuilder) UserHasNoQueuedDeploysMessage(userRef string) *slack.Response {
+	return newUserMessage(fmt.Sprintf(b.t(userHasNoQueuedDeploysMessage), slack.EscapeMessage(userRef)))
+}
+
+func (b *ResponseBuilder) MoveNotAllowedMessage(d deploy.Deploy) *slack.Response {
+	return newUserMessage(fmt.Sprintf(b.t(moveNotAllowedMessage), d.User))
+}
+
+func (b *ResponseBuilder) DeployHandedOverAnnouncement(d deploy.Deploy, user slack.User) *slack.Response {
+	var previousOwner slack.User
+	if len(d.PreviousOwners) > 0 {
+		previousOwner = d.PreviousOwners[len(d.PreviousOwners)-1]
+	}
+
+	if previousOwner.ID == user.ID {
+		return newAnnouncement(fmt.Sprintf(b.t(deployHandedOverMessage), user, deploySubject(b.locale, d.Subject, d), d.User))
+	}
+
+	return newAnnouncement(fmt.Sprintf(b.t(deployTakenOverMessage), user, deploySubject(b.locale, d.Subject, d), previousOwner, d.User))
+}
+
+func (b *ResponseBuilder) TurnNotificationsMessage(enabled bool) *slack.Response {
+	if enabled {
+		return newUserMessage(b.t(turnNotificationsOnMessage))
+	}
+
+	return newUserMessage(b.t(turnNotificationsOffMessage))
+}
+
+func (b *ResponseBuilder) SubscriptionMessage(channelID string, subscribed bool) *slack.Response {
+	if subscribed {
+		return newUserMessage(fmt.Sprintf(b.t(subscribedMessage), channelID))
+	}
+
+	return newUserMessage(fmt.Sprintf(b.t(unsubscribedMessage), channelID))
+}
+
+// NotifyOnMessage describes which deploy outcomes the user is notified about if mentioned in the deploy subject.
+func (b *ResponseBuilder) NotifyOnMessage(notifyOn string) *slack.Response {
+	switch notifyOn {
+	case deploy.NotifyOnDone:
+		return newUserMessage(b.t(notifyOnDoneMessage))
+	case deploy.NotifyOnAbort:
+		return newUserMessage(b.t(notifyOnAbortMessage))
+	default:
+		return newUserMessage(b.t(notifyOnBothMessage))
+	}
+}
+
+func (b *ResponseBuilder) LocaleMessage(locale string) *slack.Response {
+	return newUserMessage(fmt.Sprintf(b.t(localeMessage), localeName(locale), strings.Join(Locales(), ", ")))
+}
+
+func (b *ResponseBuilder) LocaleChangedAnnouncement(locale string, user slack.User) *slack.Response {
+	return newAnnouncement(fmt.Sprintf(b.t(localeChangedAnnouncement), user, localeName(locale)))
+}
+
+func (b *ResponseBuilder) WorkspaceLocaleChangedMessage(locale string) *slack.Response {
+	return newUserMessage(fmt.Sprintf(b.t(workspaceLocaleChangedMessage), localeName(locale)))
+}
+
+func (b *ResponseBuilder) ExpiryPolicyMessage(p deploy.ExpiryPolicy) *slack.Response {
+	return newUserMessage(fmt.Sprintf(b.t(expiryPolicyMessage), b.expiryPolicy(p)))
+}
+
+func (b *ResponseBuilder) NoExpiryPolicyMessage() *slack.Response {
+	return newUserMessage(b.t(noExpiryPolicyMessage))
+}
+
+func (b *ResponseBuilder) ExpiryPolicyChangedAnnouncement(p *deploy.ExpiryPolicy, user slack.User) *slack.Response {
+	if p == nil {
+		return newAnnouncement(fmt.Sprintf(b.t(expiryPolicyDisabledMessage), user))
+	}
+
+	return newAnnouncement(fmt.Sprintf(b.t(expiryPolicyChangedMessage), user, b.expiryPolicy(*p)))
+}
+
+// DeployExpiryWarning returns a direct message to the owner of deploy that has been running for too long.
+func (b *ResponseBuilder) DeployExpiryWarning(ch deploy.Channel, d deploy.Deploy, p deploy.ExpiryPolicy) *slack.Response {
+	responseText := fmt.Sprintf(b.t(deployExpiryWarningMessage), deploySubject(b.locale, d.Subject, d), ch.ID, time.Since(d.StartedAt).Round(time.Minute))
+
+	return newUserMessage(responseText + b.autoAbortNotice(d, p))
+}
+
+func (b *ResponseBuilder) DeployExpiryAnnouncement(d deploy.Deploy, p deploy.ExpiryPolicy) *slack.Response {
+	responseText := fmt.Sprintf(b.t(deployExpiryAnnouncement), d.User, deploySubject(b.locale, d.Subject, d), time.Since(d.StartedAt).Round(time.Minute))
+
+	return newAnnouncement(responseText + b.autoAbortNotice(d, p))
+}
+
+func (b *ResponseBuilder) DeployExpiredAnnouncement(d deploy.Deploy) *slack.Response {
+	return newAnnouncement(fmt.Sprintf(b.t(deployExpiredMessage), deploySubject(b.locale, d.Subject, d), d.User))
+}
+
+func (b *ResponseBuilder) DeployFrozenMessage(rule deploy.FreezeRule) *slack.Response {
+	return newUserMessage(fmt.Sprintf(b.t(deployFrozenMessage), rule))
+}
+
+// QueuedDeployFrozenAnnouncement returns the warning about the queued deploy d that has been started during
+// an active deploy freeze.
+func (b *ResponseBuilder) QueuedDeployFrozenAnnouncement(d deploy.Deploy, rule deploy.FreezeRule) *slack.Response {
+	return newAnnouncement(fmt.Sprintf(b.t(queuedDeployFrozenMessage), d.User, rule))
+}
+
+func (b *ResponseBuilder) FreezeRulesMessage(rules []deploy.FreezeRule, now time.Time) *slack.Response {
+	if len(rules) == 0 {
+		return newUserMessage(b.t(noFreezeRulesMessage))
+	}
+
+	return newUserMessage(fmt.Sprintf(b.t(freezeRulesMessage), b.freezeRulesList(rules, now)))
+}
+
+// WithFreezeRules appends the list of channel deploy freezes to the response text.
+func (b *ResponseBuilder) WithFreezeRules(response *slack.Response, rules []deploy.FreezeRule, now time.Time) *slack.Response {
+	if len(rules) > 0 {
+		response.Text += "\n\n" + fmt.Sprintf(b.t(freezeRulesMessage), b.freezeRulesList(rules, now))
+	}
+
+	return response
+}
+
+func (b *ResponseBuilder) FreezeRuleAddedAnnouncement(rule deploy.FreezeRule, user slack.User) *slack.Response {
+	return newAnnouncement(fmt.Sprintf(b.t(freezeRuleAddedMessage), user, rule))
+}
+
+func (b *ResponseBuilder) FreezeRuleRemovedAnnouncement(rule deploy.FreezeRule, user slack.User) *slack.Response {
+	return newAnnouncement(fmt.Sprintf(b.t(freezeRuleRemovedMessage), user, rule))
+}
+
+func (b *ResponseBuilder) NoSuchFreezeRuleMessage(n string) *slack.Response {
+	return newUserMessage(fmt.Sprintf(b.t(noSuchFreezeRuleMessage), slack.EscapeMessage(n)))
+}
+
+func (b *ResponseBuilder) DeployHistoryLink(host string, ch deploy.Channel, authToken string) *slack.Response {
 	host = strings.TrimSuffix(strings.TrimSuffix(host, ":80"), ":443")
-	path := &url.URL{Path: channelID}
+	path := &url.URL{Path: ch.Key()}
 
 	if authToken != "" {
 		q := path.Query()
@@ -143,7 +619,93 @@ func (*ResponseBuilder) DeployHistoryLink(host, channelID, authToken string) *sl
 		path.RawQuery = q.Encode()
 	}
 
-	return newUserMessage(fmt.Sprintf(deployHistoryLinkMessage, host, path))
+	return newUserMessage(fmt.Sprintf(b.t(deployHistoryLinkMessage), host, path))
+}
+
+// deploySubject appends the deploy environment to the subject if the deploy is not going to the default one.
+func deploySubject(locale, subject string, d deploy.Deploy) string {
+	if d.Environment == "" {
+		return subject
+	}
+
+	return fmt.Sprintf(translate(locale, environmentDeployMessage), subject, d.Environment)
+}
+
+// abortReason returns the escaped reason the deploy d has been aborted for. The reason given by the bot itself
+// is translated into locale.
+func abortReason(locale string, d deploy.Deploy) string {
+	if d.AbortReason == deployExpiredReason {
+		return translate(locale, deployExpiredReason)
+	}
+
+	return slack.EscapeMessage(d.AbortReason)
+}
+
+// freezeOverrideNotice returns the note about the deploy freeze ignored by the deploy, if any.
+func (b *ResponseBuilder) freezeOverrideNotice(d deploy.Deploy) string {
+	if d.FreezeOverride == nil {
+		return ""
+	}
+
+	if d.FreezeOverride.Reason == "" {
+		return fmt.Sprintf(b.t(freezeOverrideMessage), d.FreezeOverride.Freeze)
+	}
+
+	return fmt.Sprintf(b.t(freezeOverrideWithReasonMessage), d.FreezeOverride.Freeze, slack.EscapeMessage(d.FreezeOverride.Reason))
+}
+
+func (b *ResponseBuilder) startEstimate(t time.Time) string {
+	startIn := time.Until(t).Round(time.Minute)
+	if startIn <= 0 {
+		return b.t(queueEstimateNowMessage)
+	}
+
+	return fmt.Sprintf(b.t(queueEstimateMessage), startIn)
+}
+
+// expiryPolicy returns the description of expiry policy steps, i.e. "warn the owner after 2h, abort after 4h".
+func (b *ResponseBuilder) expiryPolicy(p deploy.ExpiryPolicy) string {
+	var steps []string
+
+	if p.Warn > 0 {
+		steps = append(steps, fmt.Sprintf(b.t(expiryWarnStep), deploy.FormatDuration(p.Warn)))
+	}
+
+	if p.Announce > 0 {
+		steps = append(steps, fmt.Sprintf(b.t(expiryAnnounceStep), deploy.FormatDuration(p.Announce)))
+	}
+
+	if p.Abort > 0 {
+		steps = append(steps, fmt.Sprintf(b.t(expiryAbortStep), deploy.FormatDuration(p.Abort)))
+	}
+
+	return strings.Join(steps, ", ")
+}
+
+func (b *ResponseBuilder) autoAbortNotice(d deploy.Deploy, p deploy.ExpiryPolicy) string {
+	if p.Abort <= 0 {
+		return ""
+	}
+
+	abortIn := time.Until(d.StartedAt.Add(p.Abort)).Round(time.Minute)
+	if abortIn <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(b.t(deployAutoAbortMessage), abortIn)
+}
+
+func (b *ResponseBuilder) freezeRulesList(rules []deploy.FreezeRule, now time.Time) string {
+	lines := make([]string, len(rules))
+	for i, rule := range rules {
+		if rule.Active(now) {
+			lines[i] = fmt.Sprintf(b.t(activeFreezeRuleMessage), i+1, rule)
+		} else {
+			lines[i] = fmt.Sprintf(b.t(freezeRuleMessage), i+1, rule)
+		}
+	}
+
+	return strings.Join(lines, "\n")
 }
 
 func newUserMessage(s string) *slack.Response {
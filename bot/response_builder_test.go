@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/adjust/michaelbot/github"
 	"github.com/adjust/michaelbot/slack"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestResponseBuilder_HelpMessage(t *testing.T) {
@@ -50,7 +52,7 @@ func TestResponseBuilder_DeployStatusMessage(t *testing.T) {
 	ds := []deploy.Deploy{d}
 
 	b := bot.NewResponseBuilder(github.NewClient("", nil))
-	response := b.DeployStatusMessage(ds)
+	response := b.DeployStatusMessage(ds, nil)
 
 	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
 	assert.Contains(t, response.Text, d.User.String())
@@ -58,6 +60,125 @@ func TestResponseBuilder_DeployStatusMessage(t *testing.T) {
 	assert.Contains(t, response.Text, d.StartedAt.Format(time.RFC822))
 }
 
+func TestResponseBuilder_DeployStatusMessage_Priority(t *testing.T) {
+	ds := []deploy.Deploy{
+		{User: slack.User{ID: "abc123", Name: "user1"}, Subject: "deploy subject", StartedAt: time.Now()},
+		{User: slack.User{ID: "abc456", Name: "user2"}, Subject: "hotfix", Priority: true},
+		{User: slack.User{ID: "abc789", Name: "user3"}, Subject: "feature"},
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.DeployStatusMessage(ds, nil)
+
+	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
+	assert.Contains(t, response.Text, "1. "+ds[1].User.String()+" [hotfix] :rotating_light: urgent")
+	assert.True(t, strings.HasSuffix(response.Text, "2. "+ds[2].User.String()+" [feature]"))
+}
+
+func TestResponseBuilder_DeployStatusMessage_Estimates(t *testing.T) {
+	now := time.Now()
+	ds := []deploy.Deploy{
+		{User: slack.User{ID: "abc123", Name: "user1"}, Subject: "deploy subject", StartedAt: now.Add(-time.Hour)},
+		{User: slack.User{ID: "abc456", Name: "user2"}, Subject: "hotfix"},
+		{User: slack.User{ID: "abc789", Name: "user3"}, Subject: "feature"},
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.DeployStatusMessage(ds, []time.Time{ds[0].StartedAt, now, now.Add(30*time.Minute + time.Second)})
+
+	assert.Contains(t, response.Text, "1. "+ds[1].User.String()+" [hotfix] — expected to start any moment now")
+	assert.Contains(t, response.Text, "2. "+ds[2].User.String()+" [feature] — expected to start in about 30m0s")
+}
+
+func TestResponseBuilder_UrgentDeployQueuedAnnouncement(t *testing.T) {
+	current := deploy.Deploy{User: slack.User{ID: "abc123", Name: "user1"}, Subject: "deploy subject", StartedAt: time.Now()}
+	d := deploy.Deploy{User: slack.User{ID: "abc456", Name: "user2"}, Subject: "hotfix", Priority: true}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.UrgentDeployQueuedAnnouncement(d, current)
+
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Contains(t, response.Text, d.User.String())
+	assert.Contains(t, response.Text, d.Subject)
+	assert.Contains(t, response.Text, current.User.String())
+}
+
+func TestResponseBuilder_DeployMovedAnnouncement(t *testing.T) {
+	d := deploy.Deploy{User: slack.User{ID: "abc456", Name: "user2"}, Subject: "hotfix"}
+	user := slack.User{ID: "abc123", Name: "user1"}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.DeployMovedAnnouncement(d, 2, user)
+
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Contains(t, response.Text, user.String())
+	assert.Contains(t, response.Text, d.User.String())
+	assert.Contains(t, response.Text, "position 2")
+}
+
+func TestResponseBuilder_MoveNotAllowedMessage(t *testing.T) {
+	d := deploy.Deploy{User: slack.User{ID: "abc456", Name: "user2"}, Subject: "hotfix"}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.MoveNotAllowedMessage(d)
+
+	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
+	assert.Equal(t, "Only <@abc456|user2> or the user who is deploying now can move this deploy", response.Text)
+}
+
+func TestResponseBuilder_DeployHandedOverAnnouncement(t *testing.T) {
+	owner1, owner2, owner3 := slack.User{ID: "U1", Name: "user1"}, slack.User{ID: "U2", Name: "user2"}, slack.User{ID: "U3", Name: "user3"}
+
+	d := deploy.Deploy{
+		User:           owner2,
+		Subject:        "deploy subject",
+		StartedAt:      time.Now(),
+		PreviousOwners: []slack.User{owner1},
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+
+	response := b.DeployHandedOverAnnouncement(d, owner1)
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Equal(t, "<@U1|user1> has handed over the deploy of deploy subject to <@U2|user2>", response.Text)
+
+	response = b.DeployHandedOverAnnouncement(d, owner3)
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Equal(t, "<@U3|user3> has handed over the deploy of deploy subject started by <@U1|user1> to <@U2|user2>", response.Text)
+}
+
+func TestResponseBuilder_ExpiryPolicyChangedAnnouncement(t *testing.T) {
+	user := slack.User{ID: "U1", Name: "user1"}
+	policy := deploy.ExpiryPolicy{Warn: 2 * time.Hour, Abort: 8 * time.Hour}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+
+	response := b.ExpiryPolicyChangedAnnouncement(&policy, user)
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Equal(t, "<@U1|user1> has changed the deploy expiry policy: warn the owner after 2h, abort after 8h", response.Text)
+
+	response = b.ExpiryPolicyChangedAnnouncement(nil, user)
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Equal(t, "<@U1|user1> has disabled the deploy expiry", response.Text)
+}
+
+func TestResponseBuilder_DeployExpiryAnnouncement(t *testing.T) {
+	d := deploy.Deploy{
+		User:      slack.User{ID: "U1", Name: "user1"},
+		Subject:   "deploy subject",
+		StartedAt: time.Now().Add(-4 * time.Hour),
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+
+	response := b.DeployExpiryAnnouncement(d, deploy.ExpiryPolicy{Announce: 4 * time.Hour, Abort: 6 * time.Hour})
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Equal(t, "<@U1|user1> has been deploying deploy subject for 4h0m0s already. Type `/deploy done` if the deploy is finished. The deploy will be aborted automatically in 2h0m0s.", response.Text)
+
+	response = b.DeployExpiryAnnouncement(d, deploy.ExpiryPolicy{Announce: 4 * time.Hour})
+	assert.Equal(t, "<@U1|user1> has been deploying deploy subject for 4h0m0s already. Type `/deploy done` if the deploy is finished.", response.Text)
+}
+
 func TestResponseBuilder_DeployInProgressMessage(t *testing.T) {
 	d := deploy.Deploy{
 		User:      slack.User{ID: "abc123", Name: "user1"},
@@ -66,10 +187,14 @@ func TestResponseBuilder_DeployInProgressMessage(t *testing.T) {
 	}
 
 	b := bot.NewResponseBuilder(github.NewClient("", nil))
-	response := b.DeployInProgressMessage(d)
+	response := b.DeployInProgressMessage(d, 2, time.Time{})
 
 	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
 	assert.Contains(t, response.Text, d.User.String())
+	assert.Contains(t, response.Text, "added to the queue at position 2.")
+
+	response = b.DeployInProgressMessage(d, 2, time.Now().Add(45*time.Minute+time.Second))
+	assert.Contains(t, response.Text, "added to the queue at position 2 — expected to start in about 45m0s.")
 }
 
 func TestResponseBuilder_DeployInterruptedAnnouncement(t *testing.T) {
@@ -132,6 +257,140 @@ func TestResponseBuilder_DeployAnnouncement(t *testing.T) {
 	}
 }
 
+func TestResponseBuilder_DeployUpdatedAnnouncement(t *testing.T) {
+	baseURL, mux, teardown := setupGitHubTestServer()
+	defer teardown()
+
+	mux.HandleFunc("/repos/user1/repo1/pulls/123", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"number":123,"title":"Hello","body":"PR description","html_url":"http://xyz.abc","user":{"login":"andrewslotin"}}`))
+	})
+
+	githubClient := github.NewClient("", nil)
+	githubClient.BaseURL = baseURL
+
+	d := deploy.New(slack.User{ID: "abc123", Name: "user1"}, "new feature user1/repo1#123")
+
+	b := bot.NewResponseBuilder(githubClient)
+	response := b.DeployUpdatedAnnouncement(d)
+
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Contains(t, response.Text, "scheduled deploy")
+	assert.Contains(t, response.Text, d.User.String())
+	assert.Contains(t, response.Text, d.Subject)
+
+	if assert.Len(t, response.Attachments, 1) {
+		assert.Equal(t, "PR #123: Hello", response.Attachments[0].Title)
+	}
+
+	d.Start()
+	response = b.DeployUpdatedAnnouncement(d)
+	assert.NotContains(t, response.Text, "scheduled deploy")
+	assert.Contains(t, response.Text, d.Subject)
+	assert.Len(t, response.Attachments, 1)
+}
+
+func TestResponseBuilder_DeployAnnouncement_Environment(t *testing.T) {
+	d := deploy.Deploy{
+		User:        slack.User{ID: "abc123", Name: "user1"},
+		Subject:     "new feature",
+		Environment: "staging",
+		StartedAt:   time.Now(),
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.DeployAnnouncement(d)
+
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Contains(t, response.Text, d.User.String())
+	assert.Contains(t, response.Text, "new feature to staging")
+}
+
+func TestResponseBuilder_DeploySummary(t *testing.T) {
+	startedAt := time.Now().Add(-25 * time.Minute)
+	d := deploy.Deploy{
+		User:       slack.User{ID: "abc123", Name: "user1"},
+		Subject:    "new feature",
+		StartedAt:  startedAt,
+		FinishedAt: startedAt.Add(25 * time.Minute),
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+
+	response := b.DeploySummary(d)
+	assert.Equal(t, ":white_check_mark: "+d.User.String()+" has deployed new feature in 25m0s", response.Text)
+	if assert.Len(t, response.Blocks, 1) {
+		assert.Equal(t, response.Text, response.Blocks[0].Text.Text)
+	}
+
+	d.Aborted, d.AbortReason = true, "tests failed"
+	response = b.DeploySummary(d)
+	assert.Equal(t, ":x: The deploy of new feature by "+d.User.String()+" has been aborted after 25m0s (tests failed)", response.Text)
+}
+
+func TestResponseBuilder_CrossPostedAnnouncement(t *testing.T) {
+	d := deploy.Deploy{
+		User:      slack.User{ID: "abc123", Name: "user1"},
+		Subject:   "new feature for <#C2|payments>",
+		StartedAt: time.Now(),
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+
+	response := b.CrossPostedAnnouncement("C1", b.DeployAnnouncement(d))
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Equal(t, d.User.String()+" is about to deploy new feature for <#C2|payments> (from <#C1>)", response.Text)
+	if assert.Len(t, response.Blocks, 1) {
+		assert.Equal(t, response.Text, response.Blocks[0].Text.Text)
+	}
+}
+
+func TestResponseBuilder_DeployAnnouncement_Buttons(t *testing.T) {
+	d := deploy.Deploy{
+		User:        slack.User{ID: "abc123", Name: "user1"},
+		Subject:     "new feature",
+		Environment: "staging",
+		StartedAt:   time.Now(),
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.DeployAnnouncement(d)
+
+	require.Len(t, response.Blocks, 2)
+	assert.Equal(t, "section", response.Blocks[0].Type)
+	assert.Equal(t, response.Text, response.Blocks[0].Text.Text)
+
+	assert.Equal(t, "actions", response.Blocks[1].Type)
+
+	var actions []string
+	for _, el := range response.Blocks[1].Elements {
+		assert.Equal(t, "button", el.Type)
+		assert.Contains(t, el.Value, `"Environment":"staging"`)
+		actions = append(actions, el.ActionID)
+	}
+	assert.Equal(t, []string{"deploy.done", "deploy.abort", "deploy.join"}, actions)
+}
+
+func TestResponseBuilder_DeployAnnouncement_FreezeOverride(t *testing.T) {
+	d := deploy.Deploy{
+		User:      slack.User{ID: "abc123", Name: "user1"},
+		Subject:   "hotfix",
+		StartedAt: time.Now(),
+		FreezeOverride: &deploy.FreezeOverride{
+			User:   slack.User{ID: "abc123", Name: "user1"},
+			Freeze: "Fri 15:00 – Mon 08:00 Europe/Berlin",
+			Reason: "payments are down",
+		},
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.DeployAnnouncement(d)
+
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Contains(t, response.Text, d.Subject)
+	assert.Contains(t, response.Text, d.FreezeOverride.Freeze)
+	assert.Contains(t, response.Text, d.FreezeOverride.Reason)
+}
+
 func TestResponseBuilder_DeployDoneAnnouncement(t *testing.T) {
 	user := slack.User{ID: "abc123", Name: "user1"}
 
@@ -164,9 +423,174 @@ func TestResponseBuilder_DeployAbortedAnnouncement_WithReason(t *testing.T) {
 	assert.Contains(t, response.Text, reason)
 }
 
+func TestResponseBuilder_ChannelLockedMessage(t *testing.T) {
+	l := deploy.Lock{
+		User:     slack.User{ID: "abc123", Name: "user1"},
+		Reason:   "incident <3>",
+		LockedAt: time.Now(),
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.ChannelLockedMessage(l)
+
+	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
+	assert.Contains(t, response.Text, l.User.String())
+	assert.Contains(t, response.Text, l.LockedAt.Format(time.RFC822))
+	assert.Contains(t, response.Text, "incident &lt;3&gt;")
+}
+
+func TestResponseBuilder_ChannelLockedAnnouncement(t *testing.T) {
+	l := deploy.Lock{
+		User:     slack.User{ID: "abc123", Name: "user1"},
+		Reason:   "release",
+		LockedAt: time.Now(),
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.ChannelLockedAnnouncement(l)
+
+	assert.Equal(t, slack.ResponseTypeInChannel, response.ResponseType)
+	assert.Contains(t, response.Text, l.User.String())
+	assert.Contains(t, response.Text, l.Reason)
+}
+
+func TestResponseBuilder_FreezeRulesMessage(t *testing.T) {
+	activeRule, err := deploy.ParseFreezeRule(time.Now().UTC().Format("2006-01-02"))
+	require.NoError(t, err)
+
+	weeklyRule, err := deploy.ParseFreezeRule("Fri 15:00 - Mon 08:00 Europe/Berlin")
+	require.NoError(t, err)
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+
+	response := b.FreezeRulesMessage(nil, time.Now())
+	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
+	assert.NotEmpty(t, response.Text)
+
+	response = b.FreezeRulesMessage([]deploy.FreezeRule{activeRule, weeklyRule}, time.Now())
+	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
+	assert.Contains(t, response.Text, "1. "+activeRule.String()+" (active)")
+	assert.Contains(t, response.Text, "2. "+weeklyRule.String())
+}
+
+func TestResponseBuilder_WithFreezeRules(t *testing.T) {
+	rule, err := deploy.ParseFreezeRule("Fri 15:00 - Mon 08:00 Europe/Berlin")
+	require.NoError(t, err)
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+
+	response := b.WithFreezeRules(b.NoRunningDeploysMessage(), nil, time.Now())
+	assert.Equal(t, b.NoRunningDeploysMessage(), response)
+
+	response = b.WithFreezeRules(b.NoRunningDeploysMessage(), []deploy.FreezeRule{rule}, time.Now())
+	assert.Contains(t, response.Text, b.NoRunningDeploysMessage().Text)
+	assert.Contains(t, response.Text, rule.String())
+}
+
+func TestResponseBuilder_AppHomeView(t *testing.T) {
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+
+	view := b.AppHomeView("U2", nil)
+	assert.Equal(t, "home", view.Type)
+	if assert.Len(t, view.Blocks, 2) {
+		assert.Contains(t, view.Blocks[1].Text.Text, "There are no deploys in channels you are a member of")
+	}
+
+	current := deploy.New(slack.User{ID: "U1", Name: "user1"}, "first deploy")
+	current.Start()
+
+	view = b.AppHomeView("U2", map[deploy.Channel][]deploy.Deploy{
+		{ID: "C2"}: nil,
+		{ID: "C1", Environment: "staging"}: {
+			current,
+			deploy.New(slack.User{ID: "U3", Name: "user3"}, "second deploy"),
+			deploy.New(slack.User{ID: "U2", Name: "user2"}, "third deploy"),
+		},
+	})
+
+	// header, then a divider and a section for each channel
+	require.Len(t, view.Blocks, 5)
+	assert.Equal(t, "divider", view.Blocks[1].Type)
+
+	lines := strings.Split(view.Blocks[2].Text.Text, "\n")
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, "*<#C1>* staging", lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], "<@U1|user1> is deploying first deploy since"), lines[1])
+		assert.Equal(t, "2 deploys are waiting in the queue", lines[2])
+		assert.Equal(t, "Your deploy is at position 2 in the queue", lines[3])
+	}
+
+	assert.Equal(t, "*<#C2>*\n"+b.NoRunningDeploysMessage().Text, view.Blocks[4].Text.Text)
+}
+
+func TestResponseBuilder_WithLocale(t *testing.T) {
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+
+	d := deploy.New(slack.User{ID: "U1", Name: "user1"}, "deploy subject")
+	d.Environment = "staging"
+
+	assert.Equal(t, "<@U1|user1> deployt gleich deploy subject nach staging", b.WithLocale("de").DeployAnnouncement(d).Text)
+	assert.Equal(t, "<@U1|user1> está a punto de desplegar deploy subject en staging", b.WithLocale("es").DeployAnnouncement(d).Text)
+
+	// the original builder is not affected
+	assert.Equal(t, "<@U1|user1> is about to deploy deploy subject to staging", b.DeployAnnouncement(d).Text)
+
+	// messages are sent in English if there is no translation
+	assert.Equal(t, b.NoRunningDeploysMessage().Text, b.WithLocale("fr").NoRunningDeploysMessage().Text)
+
+	if blocks := b.WithLocale("de").DeployAnnouncement(d).Blocks; assert.Len(t, blocks, 2) && assert.Len(t, blocks[1].Elements, 3) {
+		var labels []string
+		for _, el := range blocks[1].Elements {
+			labels = append(labels, el.Text.Text)
+		}
+
+		assert.Equal(t, []string{"Fertig", "Abbrechen", "Anstellen"}, labels)
+	}
+
+	policy := deploy.ExpiryPolicy{Warn: 2 * time.Hour, Announce: 4 * time.Hour, Abort: 8 * time.Hour}
+	assert.Equal(t, "Ablaufregel für Deploys in diesem Channel: den Besitzer nach 2h warnen, nach 4h im Channel posten, nach 8h abbrechen", b.WithLocale("de").ExpiryPolicyMessage(policy).Text)
+	assert.Equal(t, "<@U1|user1> ha cambiado la política de caducidad de deploys: avisar al responsable tras 2h, publicar en el canal tras 4h, cancelar tras 8h", b.WithLocale("es").ExpiryPolicyChangedAnnouncement(&policy, d.User).Text)
+
+	// the reason given by the bot is translated, unlike the ones provided by users
+	d.Start()
+	d.Abort("expired")
+	assert.Contains(t, b.WithLocale("es").DeploySummary(d).Text, "(caducado)")
+
+	d.AbortReason = "rollback"
+	assert.Contains(t, b.WithLocale("es").DeploySummary(d).Text, "(rollback)")
+}
+
+func TestResponseBuilder_AppHomeView_Plurals(t *testing.T) {
+	current := deploy.New(slack.User{ID: "U1", Name: "user1"}, "first deploy")
+	current.Start()
+
+	queueLength := func(b *bot.ResponseBuilder, n int) string {
+		ds := []deploy.Deploy{current}
+		for i := 0; i < n; i++ {
+			ds = append(ds, deploy.New(slack.User{ID: "U3", Name: "user3"}, "next deploy"))
+		}
+
+		view := b.AppHomeView("U2", map[deploy.Channel][]deploy.Deploy{{ID: "C1"}: ds})
+		require.Len(t, view.Blocks, 3)
+
+		return strings.Split(view.Blocks[2].Text.Text, "\n")[2]
+	}
+
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	for locale, expected := range map[string][]string{
+		"en": {"0 deploys are waiting in the queue", "1 deploy is waiting in the queue", "2 deploys are waiting in the queue"},
+		"de": {"0 Deploys warten in der Warteschlange", "1 Deploy wartet in der Warteschlange", "2 Deploys warten in der Warteschlange"},
+		"es": {"0 deploys están esperando en la cola", "1 deploy está esperando en la cola", "2 deploys están esperando en la cola"},
+	} {
+		for n, s := range expected {
+			assert.Equal(t, s, queueLength(b.WithLocale(locale), n), locale)
+		}
+	}
+}
+
 func TestResponseBuilder_DeployHistoryLink_WithAuthToken(t *testing.T) {
 	b := bot.NewResponseBuilder(github.NewClient("", nil))
-	response := b.DeployHistoryLink("www.example.com:8080", "abc 123", "secret token")
+	response := b.DeployHistoryLink("www.example.com:8080", deploy.Channel{ID: "abc 123"}, "secret token")
 
 	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
 	assert.Contains(t, response.Text, "http://www.example.com:8080/abc%20123?token=secret+token")
@@ -174,19 +598,35 @@ func TestResponseBuilder_DeployHistoryLink_WithAuthToken(t *testing.T) {
 
 func TestResponseBuilder_DeployHistoryLink_EmptyAuthToken(t *testing.T) {
 	b := bot.NewResponseBuilder(github.NewClient("", nil))
-	response := b.DeployHistoryLink("www.example.com:8080", "abc 123", "")
+	response := b.DeployHistoryLink("www.example.com:8080", deploy.Channel{ID: "abc 123"}, "")
 
 	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
 	assert.Contains(t, response.Text, "http://www.example.com:8080/abc%20123")
 }
 
+func TestResponseBuilder_DeployHistoryLink_Environment(t *testing.T) {
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.DeployHistoryLink("www.example.com:8080", deploy.Channel{ID: "abc123", Environment: "staging"}, "")
+
+	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
+	assert.Contains(t, response.Text, "http://www.example.com:8080/abc123/staging")
+}
+
+func TestResponseBuilder_DeployHistoryLink_Team(t *testing.T) {
+	b := bot.NewResponseBuilder(github.NewClient("", nil))
+	response := b.DeployHistoryLink("www.example.com:8080", deploy.Channel{TeamID: "T1", ID: "abc123", Environment: "staging"}, "")
+
+	assert.Equal(t, slack.ResponseTypeEphemeral, response.ResponseType)
+	assert.Contains(t, response.Text, "http://www.example.com:8080/T1-abc123/staging")
+}
+
 func TestResponseBuilder_DeployHistoryLink_StandardPorts(t *testing.T) {
 	standardPorts := [...]string{"80", "443"}
 
 	b := bot.NewResponseBuilder(github.NewClient("", nil))
 
 	for _, port := range standardPorts {
-		response := b.DeployHistoryLink("www.example.com:"+port, "abc 123", "")
+		response := b.DeployHistoryLink("www.example.com:"+port, deploy.Channel{ID: "abc 123"}, "")
 		assert.Contains(t, response.Text, "http://www.example.com/abc%20123", "port: %s", port)
 	}
 }
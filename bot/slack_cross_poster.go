@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"log"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+)
+
+// SlackCrossPoster mirrors deploy announcements into the channels mentioned in the deploy subject, i.e.
+// <#C123|payments>, so that they see when the deploy starts and how it ends. Messages are posted in the language
+// of each mentioned channel if locales are provided.
+type SlackCrossPoster struct {
+	clients   *workspaceClients
+	responses *ResponseBuilder
+	locales   *Localizer
+}
+
+func NewSlackCrossPoster(api slack.WebAPIClients, responses *ResponseBuilder, locales *Localizer) *SlackCrossPoster {
+	return &SlackCrossPoster{
+		clients:   newWorkspaceClients(api),
+		responses: responses,
+		locales:   locales,
+	}
+}
+
+func (p *SlackCrossPoster) DeployStarted(channelID string, d deploy.Deploy) {
+	p.crossPost(channelID, d, func(responses *ResponseBuilder) *slack.Response {
+		return responses.DeployAnnouncement(d)
+	})
+}
+
+func (p *SlackCrossPoster) DeployCompleted(channelID string, d deploy.Deploy) {
+	p.crossPost(channelID, d, func(responses *ResponseBuilder) *slack.Response {
+		return responses.DeploySummary(d)
+	})
+}
+
+func (p *SlackCrossPoster) DeployAborted(channelID string, d deploy.Deploy) {
+	p.crossPost(channelID, d, func(responses *ResponseBuilder) *slack.Response {
+		return responses.DeploySummary(d)
+	})
+}
+
+// crossPost posts the response built for each channel mentioned in the deploy subject.
+func (p *SlackCrossPoster) crossPost(channelID string, d deploy.Deploy, response func(*ResponseBuilder) *slack.Response) {
+	refs := deploy.FindChannelReferences(d.Subject)
+	if len(refs) == 0 {
+		return
+	}
+
+	api, err := p.clients.WebAPI(d.TeamID)
+	if err != nil {
+		log.Printf("slack-cross-poster: cannot post the deploy of %s from %s: %s", d.Subject, channelID, err)
+		return
+	}
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	posted := map[string]bool{channelID: true}
+	for _, ref := range refs {
+		if posted[ref.ID] {
+			continue
+		}
+		posted[ref.ID] = true
+
+		responses := p.responses
+		if p.locales != nil {
+			responses = responses.WithLocale(p.locales.ChannelLocale(deploy.Channel{TeamID: d.TeamID, ID: ref.ID}))
+		}
+
+		message := responses.CrossPostedAnnouncement(channelID, response(responses)).Message
+		if err := api.PostMessageContext(ctx, ref.ID, message); err != nil {
+			log.Printf("slack-cross-poster: failed to post the deploy of %s from %s: %s", d.Subject, channelID, err)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package bot_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/github"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackCrossPoster(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var messages []string
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		assert.NotContains(t, r.FormValue("blocks"), "actions")
+
+		messages = append(messages, r.FormValue("channel")+": "+r.FormValue("text"))
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	poster := bot.NewSlackCrossPoster(api, bot.NewResponseBuilder(github.NewClient("", nil)), nil)
+
+	d := deploy.New(slack.User{ID: "U1", Name: "user1"}, "billing for <#C2|payments> and <#C3> cc <#C2> <#C1|deploys>")
+	d.Start()
+
+	poster.DeployStarted("C1", d)
+
+	d.Abort("tests failed")
+	d.FinishedAt = d.StartedAt.Add(5 * time.Minute)
+	poster.DeployAborted("C1", d)
+
+	// deploys without channel references are not cross-posted
+	poster.DeployCompleted("C1", deploy.New(slack.User{ID: "U1", Name: "user1"}, "billing"))
+
+	subject := "billing for <#C2|payments> and <#C3> cc <#C2> <#C1|deploys>"
+	assert.Equal(t, []string{
+		"C2: <@U1|user1> is about to deploy " + subject + " (from <#C1>)",
+		"C3: <@U1|user1> is about to deploy " + subject + " (from <#C1>)",
+		"C2: :x: The deploy of " + subject + " by <@U1|user1> has been aborted after 5m0s (tests failed) (from <#C1>)",
+		"C3: :x: The deploy of " + subject + " by <@U1|user1> has been aborted after 5m0s (tests failed) (from <#C1>)",
+	}, messages)
+}
+
+func TestSlackCrossPoster_Locale(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var messages []string
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		messages = append(messages, r.FormValue("channel")+": "+r.FormValue("text"))
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+	deploy.NewChannelDeploys(store).SetLocale(deploy.Channel{ID: "C2"}, "de")
+
+	poster := bot.NewSlackCrossPoster(api, bot.NewResponseBuilder(github.NewClient("", nil)), bot.NewLocalizer(store))
+
+	d := deploy.New(slack.User{ID: "U1", Name: "user1"}, "billing for <#C2|payments> and <#C3>")
+	d.Start()
+
+	poster.DeployStarted("C1", d)
+
+	// each message is posted in the language of the channel it is cross-posted to
+	assert.Equal(t, []string{
+		"C2: <@U1|user1> deployt gleich " + d.Subject + " (aus <#C1>)",
+		"C3: <@U1|user1> is about to deploy " + d.Subject + " (from <#C1>)",
+	}, messages)
+}
+
+func TestBot_CrossPost_Queue(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages = append(messages, r.FormValue("channel")+": "+r.FormValue("text"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	b := bot.New(slackToken, "", deploy.NewInMemoryStore())
+	b.AddDeployEventHandler(bot.NewSlackCrossPoster(api, bot.NewResponseBuilder(github.NewClient("", nil)), nil))
+
+	command := func(userID, text string) {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// the summary is cross-posted even if another deploy is waiting in the queue
+	command("U1", "billing for <#C2|payments>")
+	command("U2", "auth service")
+	command("U1", "done")
+
+	command("U3", "refunds for <#C2|payments>")
+	command("U2", "done")
+	command("U4", "frontend")
+	command("U3", "abort tests failed")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if assert.Len(t, messages, 4) {
+		assert.Equal(t, "C2: <@U1|u1> is about to deploy billing for <#C2|payments> (from <#C1>)", messages[0])
+		assert.True(t, strings.HasPrefix(messages[1], "C2: :white_check_mark: <@U1|u1> has deployed billing for <#C2|payments> in "), messages[1])
+		assert.Equal(t, "C2: <@U3|u3> is about to deploy refunds for <#C2|payments> (from <#C1>)", messages[2])
+		assert.True(t, strings.HasPrefix(messages[3], "C2: :x: The deploy of refunds for <#C2|payments> by <@U3|u3> has been aborted after "), messages[3])
+		assert.True(t, strings.HasSuffix(messages[3], " (tests failed) (from <#C1>)"), messages[3])
+	}
+}
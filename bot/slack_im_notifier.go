@@ -1,95 +1,240 @@
 package bot
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
-	"sync"
+	"strconv"
 	"time"
 
 	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/scheduler"
 	"github.com/adjust/michaelbot/slack"
 )
 
+const deployReminderTimerKind = "deploy.reminder"
+
+type deployReminder struct {
+	TeamID string `json:",omitempty"`
+	User   slack.User
+	Text   string
+}
+
+const (
+	deployReminderMessage              = "Your deploy %q was started %s ago. Are you still deploying?"
+	handedOverDeployReminderMessage    = "The deploy %q you took over from %s was started %s ago. Are you still deploying?"
+	subscriberDoneMessage              = "%s just deployed %s"
+	subscriberAbortedMessage           = "The deploy of %s by %s has been aborted"
+	subscriberAbortedWithReasonMessage = "The deploy of %s by %s has been aborted (%s)"
+)
+
+// SlackIMNotifier reminds deploy owners about long-running deploys and lets users mentioned in the deploy subject
+// know how it ended. The outcomes each user is notified about are taken from their settings, if available. Messages
+// are translated into the language of each recipient if locales are provided.
 type SlackIMNotifier struct {
-	im             *slack.InstantMessenger
-	users          *slack.TeamDirectory
+	clients        *workspaceClients
+	sched          *scheduler.Scheduler
+	settings       deploy.UserSettingsStore
+	locales        *Localizer
 	warningTimeout time.Duration
-	timer          *time.Timer
-	mutex          sync.Mutex
 }
 
-func NewSlackIMNotifier(api *slack.WebAPI, warningTimeout time.Duration) *SlackIMNotifier {
-	return &SlackIMNotifier{
-		im:             slack.NewInstantMessenger(api),
-		users:          slack.NewTeamDirectory(api),
+func NewSlackIMNotifier(api slack.WebAPIClients, sched *scheduler.Scheduler, settings deploy.UserSettingsStore, locales *Localizer, warningTimeout time.Duration) *SlackIMNotifier {
+	notifier := &SlackIMNotifier{
+		clients:        newWorkspaceClients(api),
+		sched:          sched,
+		settings:       settings,
+		locales:        locales,
 		warningTimeout: warningTimeout,
 	}
+	sched.Handle(deployReminderTimerKind, notifier.sendReminder)
+
+	return notifier
+}
+
+func (notifier *SlackIMNotifier) DeployStarted(channelID string, d deploy.Deploy) {
+	locale := notifier.ownerLocale(channelID, d)
+	notifier.scheduleReminder(channelID, d, fmt.Sprintf(translate(locale, deployReminderMessage), d.Subject, notifier.warningTimeout))
+}
+
+// DeployHandedOver restarts the reminder timer, so that the new deploy owner gets the reminder.
+func (notifier *SlackIMNotifier) DeployHandedOver(channelID string, d deploy.Deploy) {
+	previousOwner := d.PreviousOwners[len(d.PreviousOwners)-1]
+	startedAgo := time.Since(d.StartedAt) + notifier.warningTimeout
+
+	locale := notifier.ownerLocale(channelID, d)
+	notifier.scheduleReminder(channelID, d, fmt.Sprintf(translate(locale, handedOverDeployReminderMessage), d.Subject, previousOwner, startedAgo.Round(time.Minute)))
+}
+
+func (notifier *SlackIMNotifier) DeployCompleted(channelID string, d deploy.Deploy) {
+	notifier.sched.Cancel(deployReminderTimerID(channelID, d))
+	notifier.notifySubscribers(channelID, d, deploy.NotifyOnDone, func(locale string) string {
+		return fmt.Sprintf(translate(locale, subscriberDoneMessage), d.User, d.Subject)
+	})
+}
+
+func (notifier *SlackIMNotifier) DeployAborted(channelID string, d deploy.Deploy) {
+	notifier.sched.Cancel(deployReminderTimerID(channelID, d))
+	notifier.notifySubscribers(channelID, d, deploy.NotifyOnAbort, func(locale string) string {
+		if d.AbortReason != "" {
+			return fmt.Sprintf(translate(locale, subscriberAbortedWithReasonMessage), d.Subject, d.User, abortReason(locale, d))
+		}
+
+		return fmt.Sprintf(translate(locale, subscriberAbortedMessage), d.Subject, d.User)
+	})
 }
 
-func (notifier *SlackIMNotifier) DeployStarted(_ string, d deploy.Deploy) {
-	notifier.mutex.Lock()
-	defer notifier.mutex.Unlock()
+// notifySubscribers sends a direct message to users mentioned in the deploy subject, unless they chose not
+// to be notified about this outcome in the channel. The message text is built in the language of each user.
+func (notifier *SlackIMNotifier) notifySubscribers(channelID string, d deploy.Deploy, outcome string, text func(locale string) string) {
+	if len(d.Subscribers) == 0 {
+		return
+	}
 
-	// stop current timer if exist
-	notifier.stopTimer()
+	im, err := notifier.clients.InstantMessenger(d.TeamID)
+	if err != nil {
+		log.Printf("cannot notify subscribers about the deploy of %s: %s", d.Subject, err)
+		return
+	}
 
-	notifier.timer = time.AfterFunc(notifier.warningTimeout, func() {
-		message := slack.Message{
-			Text: fmt.Sprintf("Your deploy %q was started %s ago. Are you still deploying?", d.Subject, notifier.warningTimeout),
+	users, err := notifier.clients.TeamDirectory(d.TeamID)
+	if err != nil {
+		log.Printf("cannot notify subscribers about the deploy of %s: %s", d.Subject, err)
+		return
+	}
+
+	ch := deploy.Channel{TeamID: d.TeamID, ID: channelID, Environment: d.Environment}
+
+	for _, user := range subscribedUsers(users, d) {
+		if notifier.settings != nil {
+			if notifyOn := notifier.settings.GetUserSettings(user.ID).NotifyOn(ch); notifyOn != deploy.NotifyOnBoth && notifyOn != outcome {
+				continue
+			}
 		}
 
-		err := notifier.im.SendMessage(d.User, message)
+		ctx, cancel := webAPIContext()
+		err := im.SendMessageContext(ctx, user, slack.Message{Text: text(notifier.locale(ch, user))})
+		cancel()
+
 		if err != nil {
-			log.Printf("failed to send an instant message to %s: %s", d.User.Name, err)
+			log.Printf("failed to send an instant message to %s: %s", user.Name, err)
+			continue
 		}
-	})
+	}
 }
 
-func (notifier *SlackIMNotifier) DeployCompleted(_ string, d deploy.Deploy) {
-	notifier.mutex.Lock()
-	notifier.stopTimer()
-	notifier.mutex.Unlock()
+// ownerLocale returns the language of messages sent to the owner of deploy d.
+func (notifier *SlackIMNotifier) ownerLocale(channelID string, d deploy.Deploy) string {
+	ch := deploy.Channel{TeamID: d.TeamID, ID: channelID, Environment: d.Environment}
+
+	return recipientLocale(notifier.locales, notifier.clients, ch, d.User)
+}
 
+// locale returns the language of messages sent to user about deploys in channel.
+func (notifier *SlackIMNotifier) locale(ch deploy.Channel, user slack.User) string {
+	if notifier.locales == nil {
+		return DefaultLocale
+	}
+
+	return notifier.locales.UserLocale(ch, user)
+}
+
+// subscribedUsers resolves the users mentioned in the deploy subject, expanding user groups to their members.
+// Deactivated users are skipped and each user is returned only once.
+func subscribedUsers(users *slack.TeamDirectory, d deploy.Deploy) []slack.User {
 	var (
-		user slack.User
-		err  error
+		subscribers []slack.User
+		seen        = make(map[string]bool)
 	)
 
+	add := func(user slack.User) {
+		if !seen[user.ID] {
+			seen[user.ID] = true
+			subscribers = append(subscribers, user)
+		}
+	}
+
 	for _, userRef := range d.Subscribers {
-		if userRef.ID != "" {
-			user.ID, user.Name = userRef.ID, userRef.Name
-		} else {
-			user, err = notifier.users.Fetch(userRef.Name)
+		switch {
+		case userRef.IsGroup():
+			members, err := users.GroupMembers(userRef.GroupID)
+			if err != nil {
+				log.Printf("cannot notify @%s about completed deploy of %s: %s", userRef.Name, d.Subject, err)
+				continue
+			}
+
+			for _, user := range members {
+				add(user)
+			}
+		case userRef.ID != "":
+			// make sure that the subscriber has not been deactivated since the deploy has been announced
+			user, err := users.FetchByID(userRef.ID)
+			if err != nil {
+				if _, ok := err.(slack.NoSuchUserError); ok {
+					continue
+				}
+
+				log.Printf("cannot check whether %s is an active user: %s", userRef.Name, err)
+				user = slack.User{ID: userRef.ID, Name: userRef.Name}
+			}
+
+			add(user)
+		default:
+			user, err := users.Fetch(userRef.Name)
 			if err != nil {
 				if _, ok := err.(slack.NoSuchUserError); !ok {
-					log.Printf("cannot notify %s about completed deploy of %s: %s", user.Name, d.Subject, err)
+					log.Printf("cannot notify %s about completed deploy of %s: %s", userRef.Name, d.Subject, err)
 				}
 
 				continue
 			}
-		}
 
-		message := slack.Message{
-			Text: fmt.Sprintf("%s just deployed %s", d.User, d.Subject),
-		}
-
-		err = notifier.im.SendMessage(user, message)
-		if err != nil {
-			log.Printf("failed to send an instant message to %s: %s", user.Name, err)
-			continue
+			add(user)
 		}
 	}
+
+	return subscribers
 }
 
-func (notifier *SlackIMNotifier) DeployAborted(_ string, _ deploy.Deploy) {
-	notifier.mutex.Lock()
-	notifier.stopTimer()
-	notifier.mutex.Unlock()
+// scheduleReminder schedules a direct message to the deploy owner. There is at most one pending reminder
+// for each deploy, so scheduling another one replaces the existing timer.
+func (notifier *SlackIMNotifier) scheduleReminder(channelID string, d deploy.Deploy, text string) {
+	data, err := json.Marshal(deployReminder{TeamID: d.TeamID, User: d.User, Text: text})
+	if err != nil {
+		log.Printf("failed to marshal reminder for %s: %s", d.User.Name, err)
+		return
+	}
+
+	notifier.sched.Schedule(scheduler.Timer{
+		ID:     deployReminderTimerID(channelID, d),
+		Kind:   deployReminderTimerKind,
+		FireAt: time.Now().Add(notifier.warningTimeout),
+		Data:   data,
+	})
 }
 
-func (notifier *SlackIMNotifier) stopTimer() {
-	if notifier.timer != nil {
-		notifier.timer.Stop()
-		notifier.timer = nil
+func (notifier *SlackIMNotifier) sendReminder(t scheduler.Timer) {
+	var reminder deployReminder
+	if err := json.Unmarshal(t.Data, &reminder); err != nil {
+		log.Printf("malformed reminder %s: %s", t.ID, err)
+		return
+	}
+
+	im, err := notifier.clients.InstantMessenger(reminder.TeamID)
+	if err == nil {
+		ctx, cancel := webAPIContext()
+		err = im.SendMessageContext(ctx, reminder.User, slack.Message{Text: reminder.Text})
+		cancel()
+	}
+
+	if err != nil {
+		log.Printf("failed to send an instant message to %s: %s", reminder.User.Name, err)
 	}
 }
+
+// deployReminderTimerID returns the reminder timer key that is unique for each deploy in each channel.
+func deployReminderTimerID(channelID string, d deploy.Deploy) string {
+	ch := deploy.Channel{TeamID: d.TeamID, ID: channelID, Environment: d.Environment}
+
+	return deployReminderTimerKind + "/" + ch.Key() + "/" + strconv.FormatInt(d.StartedAt.UnixNano(), 10)
+}
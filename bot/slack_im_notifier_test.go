@@ -4,13 +4,18 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/adjust/michaelbot/bot"
 	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/scheduler"
 	"github.com/adjust/michaelbot/slack"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSlackIMNotifier_DeployCompleted(t *testing.T) {
@@ -37,13 +42,13 @@ func TestSlackIMNotifier_DeployCompleted(t *testing.T) {
 
 	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
 		requestNum.UsersList++
-		assert.Equal(t, webAPIToken, r.FormValue("token"))
+		assert.Equal(t, "Bearer "+webAPIToken, r.Header.Get("Authorization"))
 
 		fmt.Fprint(w, `{"ok":true,"members":[{"id":"R1","name":"recipient1"},{"id":"R2","name":"recipient2"},{"id":"R3","name":"recipient3"}]}`)
 	})
 	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
 		requestNum.IMOpen++
-		assert.Equal(t, webAPIToken, r.FormValue("token"))
+		assert.Equal(t, "Bearer "+webAPIToken, r.Header.Get("Authorization"))
 
 		if userID := r.FormValue("users"); assert.NotEmpty(t, userID) {
 			fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, userID)
@@ -52,7 +57,7 @@ func TestSlackIMNotifier_DeployCompleted(t *testing.T) {
 		}
 	})
 	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, webAPIToken, r.FormValue("token"))
+		assert.Equal(t, "Bearer "+webAPIToken, r.Header.Get("Authorization"))
 
 		if channelID := r.FormValue("channel"); assert.NotEmpty(t, channelID) {
 			receivers = append(receivers, channelID)
@@ -69,7 +74,7 @@ func TestSlackIMNotifier_DeployCompleted(t *testing.T) {
 	api := slack.NewWebAPI(webAPIToken, nil)
 	api.BaseURL = server.URL
 
-	notifier := bot.NewSlackIMNotifier(api, time.Hour)
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, nil, time.Hour)
 	notifier.DeployCompleted("", d)
 
 	assert.Equal(t, 1, requestNum.UsersList) // nonExistingRecipient will not hit the cache
@@ -83,7 +88,7 @@ func TestSlackIMNotifier_DeployCompleted(t *testing.T) {
 	// Retry to check user list caching
 	receivers = receivers[:0]
 	notifier.DeployCompleted("", d)
-	assert.Equal(t, 2, requestNum.UsersList) // +1 request because of nonExistingRecipient
+	assert.Equal(t, 1, requestNum.UsersList) // nonExistingRecipient does not refresh the recently fetched list
 	assert.Equal(t, 2, requestNum.IMOpen)    // no new channels are expected to be open
 
 	if assert.Len(t, receivers, 2) {
@@ -92,6 +97,208 @@ func TestSlackIMNotifier_DeployCompleted(t *testing.T) {
 	}
 }
 
+func TestSlackIMNotifier_DeployCompleted_DeactivatedSubscriber(t *testing.T) {
+	d := deploy.Deploy{
+		User:    slack.User{ID: "U1", Name: "author"},
+		Subject: "Deploy subject",
+		Subscribers: []deploy.UserReference{
+			{ID: "R1", Name: "recipient1"},
+			{ID: "R2", Name: "recipient2"},
+		},
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var receivers []string
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"R1","name":"recipient1","deleted":true},{"id":"R2","name":"renamed2"}]}`)
+	})
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		receivers = append(receivers, r.FormValue("channel"))
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, nil, time.Hour)
+	notifier.DeployCompleted("", d)
+
+	assert.Equal(t, []string{"DMR2"}, receivers)
+}
+
+func TestSlackIMNotifier_DeployCompleted_UserGroup(t *testing.T) {
+	d := deploy.New(slack.User{ID: "U1", Name: "author"}, "Deploy subject for <!subteam^S1|@backend> cc <@U2|user2>")
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu         sync.Mutex
+		groupsList int
+		receivers  []string
+	)
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"U2","name":"user2"},{"id":"U3","name":"user3"},{"id":"U4","name":"user4","deleted":true}]}`)
+	})
+	mux.HandleFunc("/usergroups.users.list", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		groupsList++
+		mu.Unlock()
+
+		assert.Equal(t, "S1", r.FormValue("usergroup"))
+		fmt.Fprint(w, `{"ok":true,"users":["U2","U3","U4"]}`)
+	})
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		receivers = append(receivers, r.FormValue("channel"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, nil, time.Hour)
+	notifier.DeployCompleted("", d)
+	notifier.DeployCompleted("", d)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"DMU2", "DMU3", "DMU2", "DMU3"}, receivers)
+	assert.Equal(t, 1, groupsList) // group members are cached
+}
+
+func TestSlackIMNotifier_DeployAborted(t *testing.T) {
+	d := deploy.New(slack.User{ID: "U1", Name: "author"}, "Deploy subject cc <@R1|recipient1>")
+	d.Abort("tests are <red>")
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var messages []slack.Message
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"R1","name":"recipient1"}]}`)
+	})
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DMR1", r.FormValue("channel"))
+		messages = append(messages, slack.Message{Text: r.FormValue("text")})
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, nil, time.Hour).DeployAborted("C1", d)
+
+	if assert.Len(t, messages, 1) {
+		assert.Equal(t, "The deploy of "+d.Subject+" by <@U1|author> has been aborted (tests are &lt;red&gt;)", messages[0].Text)
+	}
+}
+
+func TestSlackIMNotifier_NotifyOnPreference(t *testing.T) {
+	d := deploy.New(slack.User{ID: "U1", Name: "author"}, "Deploy subject cc <@R1|done> <@R2|abort> <@R3|both>")
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var receivers []string
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"R1","name":"done"},{"id":"R2","name":"abort"},{"id":"R3","name":"both"}]}`)
+	})
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		receivers = append(receivers, r.FormValue("channel"))
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	settings := deploy.NewInMemoryStore()
+	for userID, notifyOn := range map[string]string{"R1": deploy.NotifyOnDone, "R2": deploy.NotifyOnAbort} {
+		var s deploy.UserSettings
+		s.SetNotifyOn(deploy.Channel{ID: "C1"}, notifyOn)
+		settings.SetUserSettings(userID, s)
+	}
+
+	// the preference of R3 in another channel does not apply
+	s := settings.GetUserSettings("R3")
+	s.SetNotifyOn(deploy.Channel{ID: "C2"}, deploy.NotifyOnDone)
+	settings.SetUserSettings("R3", s)
+
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), settings, nil, time.Hour)
+
+	notifier.DeployCompleted("C1", d)
+	assert.Equal(t, []string{"DMR1", "DMR3"}, receivers)
+
+	receivers = receivers[:0]
+	notifier.DeployAborted("C1", d)
+	assert.Equal(t, []string{"DMR2", "DMR3"}, receivers)
+}
+
+func TestSlackIMNotifier_Locale(t *testing.T) {
+	d := deploy.New(slack.User{ID: "U1", Name: "author"}, "Deploy subject cc <@R1|recipient1> <@R2|recipient2> <@R3|recipient3>")
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	messages := make(map[string]string)
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.FormValue("include_locale"))
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"R1","name":"recipient1","locale":"de-DE"},{"id":"R2","name":"recipient2","locale":"fr-FR"},{"id":"R3","name":"recipient3","locale":"en-US"}]}`)
+	})
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		messages[r.FormValue("channel")] = r.FormValue("text")
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+	deploy.NewChannelDeploys(store).SetLocale(deploy.Channel{ID: "C1"}, "es")
+
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, bot.NewLocalizer(store), time.Hour)
+	notifier.DeployCompleted("C1", d)
+
+	assert.Equal(t, map[string]string{
+		// the Slack locale of the user
+		"DMR1": "<@U1|author> hat gerade " + d.Subject + " deployt",
+		// unsupported Slack locale, the channel one is used
+		"DMR2": "<@U1|author> acaba de desplegar " + d.Subject,
+		"DMR3": "<@U1|author> just deployed " + d.Subject,
+	}, messages)
+}
+
 func TestSlackIMNotifier_DeployStart_Warning(t *testing.T) {
 	const webAPIToken = "xxxxx-token1"
 
@@ -111,7 +318,7 @@ func TestSlackIMNotifier_DeployStart_Warning(t *testing.T) {
 
 	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
 		requestNum.IMOpen++
-		assert.Equal(t, webAPIToken, r.FormValue("token"))
+		assert.Equal(t, "Bearer "+webAPIToken, r.Header.Get("Authorization"))
 
 		if userID := r.FormValue("users"); assert.NotEmpty(t, userID) {
 			fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, userID)
@@ -122,7 +329,7 @@ func TestSlackIMNotifier_DeployStart_Warning(t *testing.T) {
 
 	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
 		requestNum.IMPostMessage++
-		assert.Equal(t, webAPIToken, r.FormValue("token"))
+		assert.Equal(t, "Bearer "+webAPIToken, r.Header.Get("Authorization"))
 
 		if channelID := r.FormValue("channel"); assert.NotEmpty(t, channelID) {
 			receivers = append(receivers, channelID)
@@ -138,7 +345,7 @@ func TestSlackIMNotifier_DeployStart_Warning(t *testing.T) {
 	api := slack.NewWebAPI(webAPIToken, nil)
 	api.BaseURL = server.URL
 
-	notifier := bot.NewSlackIMNotifier(api, 10*time.Millisecond)
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, nil, 10*time.Millisecond)
 	notifier.DeployStarted("", d)
 	time.Sleep(20 * time.Millisecond)
 
@@ -152,6 +359,49 @@ func TestSlackIMNotifier_DeployStart_Warning(t *testing.T) {
 	notifier.DeployCompleted("", d)
 }
 
+func TestSlackIMNotifier_DeployHandedOver_Warning(t *testing.T) {
+	const webAPIToken = "xxxxx-token1"
+
+	d := deploy.Deploy{
+		User:           slack.User{ID: "U2", Name: "new_owner"},
+		Subject:        "Deploy subject",
+		StartedAt:      time.Now(),
+		PreviousOwners: []slack.User{{ID: "U1", Name: "author"}},
+	}
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var receivers []string
+
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		receivers = append(receivers, r.FormValue("channel"))
+
+		if msg := r.FormValue("text"); assert.NotEmpty(t, msg) {
+			assert.Contains(t, msg, `The deploy "Deploy subject" you took over from <@U1|author>`)
+		}
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI(webAPIToken, nil)
+	api.BaseURL = server.URL
+
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, nil, 10*time.Millisecond)
+	notifier.DeployStarted("", deploy.Deploy{User: d.PreviousOwners[0], Subject: d.Subject, StartedAt: d.StartedAt})
+	notifier.DeployHandedOver("", d)
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Equal(t, []string{"DMU2"}, receivers)
+
+	notifier.DeployCompleted("", d)
+}
+
 func TestSlackIMNotifier_DeployStart_CompletedBeforeWarning(t *testing.T) {
 	const webAPIToken = "xxxxx-token1"
 
@@ -180,7 +430,7 @@ func TestSlackIMNotifier_DeployStart_CompletedBeforeWarning(t *testing.T) {
 	api := slack.NewWebAPI(webAPIToken, nil)
 	api.BaseURL = server.URL
 
-	notifier := bot.NewSlackIMNotifier(api, 100*time.Millisecond)
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, nil, 100*time.Millisecond)
 	notifier.DeployStarted("", d)
 	time.Sleep(10 * time.Millisecond)
 	notifier.DeployCompleted("", d)
@@ -217,7 +467,7 @@ func TestSlackIMNotifier_DeployStart_AbortBeforeWarning(t *testing.T) {
 	api := slack.NewWebAPI(webAPIToken, nil)
 	api.BaseURL = server.URL
 
-	notifier := bot.NewSlackIMNotifier(api, 100*time.Millisecond)
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, nil, 100*time.Millisecond)
 	notifier.DeployStarted("", d)
 	time.Sleep(10 * time.Millisecond)
 	notifier.DeployAborted("", d)
@@ -225,3 +475,220 @@ func TestSlackIMNotifier_DeployStart_AbortBeforeWarning(t *testing.T) {
 	assert.Equal(t, 0, requestNum.IMOpen)
 	assert.Equal(t, 0, requestNum.IMPostMessage)
 }
+
+func TestSlackIMNotifier_DeployStart_MultipleChannels(t *testing.T) {
+	const webAPIToken = "xxxxx-token1"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu        sync.Mutex
+		receivers []string
+	)
+
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		receivers = append(receivers, r.FormValue("channel"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI(webAPIToken, nil)
+	api.BaseURL = server.URL
+
+	d1 := deploy.Deploy{User: slack.User{ID: "U1", Name: "author1"}, Subject: "Deploy 1", StartedAt: time.Now()}
+	d2 := deploy.Deploy{User: slack.User{ID: "U2", Name: "author2"}, Subject: "Deploy 2", StartedAt: time.Now()}
+
+	notifier := bot.NewSlackIMNotifier(api, scheduler.New(deploy.NewInMemoryStore()), nil, nil, 10*time.Millisecond)
+	notifier.DeployStarted("C1", d1)
+	notifier.DeployStarted("C2", d2)
+	time.Sleep(30 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.ElementsMatch(t, []string{"DMU1", "DMU2"}, receivers)
+}
+
+func TestSlackIMNotifier_DeployStart_Restart(t *testing.T) {
+	const webAPIToken = "xxxxx-token1"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu        sync.Mutex
+		receivers []string
+	)
+
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		receivers = append(receivers, r.FormValue("channel"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI(webAPIToken, nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	d := deploy.Deploy{User: slack.User{ID: "U1", Name: "author"}, Subject: "Deploy subject", StartedAt: time.Now()}
+	bot.NewSlackIMNotifier(api, scheduler.New(store), nil, nil, time.Hour).DeployStarted("C1", d)
+	require.Len(t, store.GetTimers(), 1)
+
+	// simulate restart with reminder being due while the service was down
+	timer := store.GetTimers()[0]
+	timer.FireAt = time.Now().Add(-time.Minute)
+	store.SetTimer(timer)
+
+	sched := scheduler.New(store)
+	bot.NewSlackIMNotifier(api, sched, nil, nil, time.Hour)
+	sched.Restore()
+	time.Sleep(20 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"DMU1"}, receivers)
+	assert.Empty(t, store.GetTimers())
+}
+
+func TestBot_DeployReminder_Queue(t *testing.T) {
+	const slackToken = "slack-token"
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	store := deploy.NewInMemoryStore()
+
+	b := bot.New(slackToken, "", store)
+	b.AddDeployEventHandler(bot.NewSlackIMNotifier(api, scheduler.New(store), nil, nil, time.Hour))
+
+	command := func(userID, text string) {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	reminders := func() []string {
+		var ids []string
+		for _, timer := range store.GetTimers() {
+			ids = append(ids, timer.ID)
+		}
+
+		return ids
+	}
+
+	command("U1", "first deploy")
+	command("U2", "second deploy")
+	command("U3", "third deploy")
+	require.Len(t, reminders(), 1)
+
+	// the reminder about the finished deploy is replaced with the one for the next deploy
+	first := reminders()[0]
+	command("U1", "done")
+	if assert.Len(t, reminders(), 1) {
+		assert.NotEqual(t, first, reminders()[0])
+	}
+
+	second := reminders()[0]
+	command("U2", "abort")
+	if assert.Len(t, reminders(), 1) {
+		assert.NotEqual(t, second, reminders()[0])
+	}
+
+	command("U3", "done")
+	assert.Empty(t, reminders())
+}
+
+func TestBot_DeployAborted_Queue(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"R1","name":"recipient1"}]}`)
+	})
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages = append(messages, r.FormValue("channel")+": "+r.FormValue("text"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	b := bot.New(slackToken, "", store)
+	b.AddDeployEventHandler(bot.NewSlackIMNotifier(api, scheduler.New(store), nil, nil, time.Hour))
+
+	command := func(userID, text string) {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	command("U1", "first deploy cc <@R1|recipient1>")
+	command("U2", "second deploy")
+	command("U1", "abort tests failed")
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{
+		"DMR1: The deploy of first deploy cc <@R1|recipient1> by <@U1|u1> has been aborted (tests failed)",
+	}, messages)
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+)
+
+const (
+	subscriptionStartedMessage           = "%s has started deploying %s in <#%s>"
+	subscriptionCompletedMessage         = "%s has finished deploying %s in <#%s>"
+	subscriptionAbortedMessage           = "The deploy of %s by %s in <#%s> has been aborted"
+	subscriptionAbortedWithReasonMessage = "The deploy of %s by %s in <#%s> has been aborted (%s)"
+)
+
+// SlackSubscriptionNotifier sends a direct message to users subscribed to a channel with `/deploy subscribe`
+// whenever a deploy is started, completed or aborted there. The deploy owner and users mentioned in the deploy
+// subject are skipped, since they already know about it. Messages are translated into the language of each
+// subscriber if locales are provided.
+type SlackSubscriptionNotifier struct {
+	clients *workspaceClients
+	deploys *deploy.ChannelDeploys
+	locales *Localizer
+}
+
+func NewSlackSubscriptionNotifier(api slack.WebAPIClients, store deploy.Store, locales *Localizer) *SlackSubscriptionNotifier {
+	return &SlackSubscriptionNotifier{
+		clients: newWorkspaceClients(api),
+		deploys: deploy.NewChannelDeploys(store),
+		locales: locales,
+	}
+}
+
+func (notifier *SlackSubscriptionNotifier) DeployStarted(channelID string, d deploy.Deploy) {
+	notifier.notify(channelID, d, func(locale string) string {
+		return fmt.Sprintf(translate(locale, subscriptionStartedMessage), d.User, deploySubject(locale, d.Subject, d), channelID)
+	})
+}
+
+func (notifier *SlackSubscriptionNotifier) DeployCompleted(channelID string, d deploy.Deploy) {
+	notifier.notify(channelID, d, func(locale string) string {
+		return fmt.Sprintf(translate(locale, subscriptionCompletedMessage), d.User, deploySubject(locale, d.Subject, d), channelID)
+	})
+}
+
+func (notifier *SlackSubscriptionNotifier) DeployAborted(channelID string, d deploy.Deploy) {
+	notifier.notify(channelID, d, func(locale string) string {
+		if d.AbortReason != "" {
+			return fmt.Sprintf(translate(locale, subscriptionAbortedWithReasonMessage), deploySubject(locale, d.Subject, d), d.User, channelID, abortReason(locale, d))
+		}
+
+		return fmt.Sprintf(translate(locale, subscriptionAbortedMessage), deploySubject(locale, d.Subject, d), d.User, channelID)
+	})
+}
+
+// notify sends a direct message to the subscribers of channel. The message text is built in the language of each user.
+func (notifier *SlackSubscriptionNotifier) notify(channelID string, d deploy.Deploy, text func(locale string) string) {
+	ch := deploy.Channel{TeamID: d.TeamID, ID: channelID}
+
+	subscribers := notifier.deploys.Subscribers(ch)
+	if len(subscribers) == 0 {
+		return
+	}
+
+	im, err := notifier.clients.InstantMessenger(d.TeamID)
+	if err != nil {
+		log.Printf("cannot notify subscribers of %s about the deploy of %s: %s", channelID, d.Subject, err)
+		return
+	}
+
+	for _, user := range subscribers {
+		if user.ID == d.User.ID || mentioned(user, d) {
+			continue
+		}
+
+		message := slack.Message{Text: text(recipientLocale(notifier.locales, notifier.clients, ch, user))}
+
+		ctx, cancel := webAPIContext()
+		err := im.SendMessageContext(ctx, user, message)
+		cancel()
+
+		if err != nil {
+			log.Printf("failed to send an instant message to %s: %s", user.Name, err)
+		}
+	}
+}
+
+// mentioned returns true if user is mentioned in the deploy subject. Members of mentioned user groups are not
+// taken into account.
+func mentioned(user slack.User, d deploy.Deploy) bool {
+	for _, ref := range d.Subscribers {
+		if ref.Matches(user) {
+			return true
+		}
+	}
+
+	return false
+}
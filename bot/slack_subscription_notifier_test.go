@@ -0,0 +1,249 @@
+package bot_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlackSubscriptionNotifier(t *testing.T) {
+	const webAPIToken = "xxxxx-token1"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var messages []string
+
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer "+webAPIToken, r.Header.Get("Authorization"))
+
+		messages = append(messages, r.FormValue("channel")+": "+r.FormValue("text"))
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI(webAPIToken, nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	repo := deploy.NewChannelDeploys(store)
+	for _, u := range []slack.User{{ID: "U1", Name: "owner"}, {ID: "U2", Name: "mentioned"}, {ID: "U3", Name: "subscriber"}} {
+		repo.Subscribe(deploy.Channel{ID: "C1"}, u)
+	}
+
+	notifier := bot.NewSlackSubscriptionNotifier(api, store, nil)
+
+	d := deploy.New(slack.User{ID: "U1", Name: "owner"}, "Deploy 1 cc <@U2|mentioned>")
+	d.Environment = "staging"
+
+	notifier.DeployStarted("C1", d)
+	notifier.DeployCompleted("C1", d)
+
+	d.Abort("broken <build>")
+	notifier.DeployAborted("C1", d)
+
+	// no subscribers in another channel
+	notifier.DeployStarted("C2", d)
+
+	assert.Equal(t, []string{
+		"DMU3: <@U1|owner> has started deploying Deploy 1 cc <@U2|mentioned> to staging in <#C1>",
+		"DMU3: <@U1|owner> has finished deploying Deploy 1 cc <@U2|mentioned> to staging in <#C1>",
+		"DMU3: The deploy of Deploy 1 cc <@U2|mentioned> to staging by <@U1|owner> in <#C1> has been aborted (broken &lt;build&gt;)",
+	}, messages)
+}
+
+func TestSlackSubscriptionNotifier_Locale(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	messages := make(map[string]string)
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"U2","name":"user2","locale":"de-DE"},{"id":"U3","name":"user3","locale":"fr-FR"}]}`)
+	})
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		messages[r.FormValue("channel")] = r.FormValue("text")
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	repo := deploy.NewChannelDeploys(store)
+	repo.SetLocale(deploy.Channel{ID: "C1"}, "es")
+	for _, u := range []slack.User{{ID: "U2", Name: "user2"}, {ID: "U3", Name: "user3"}} {
+		repo.Subscribe(deploy.Channel{ID: "C1"}, u)
+	}
+
+	d := deploy.New(slack.User{ID: "U1", Name: "owner"}, "Deploy 1")
+	d.Abort("broken build")
+
+	bot.NewSlackSubscriptionNotifier(api, store, bot.NewLocalizer(store)).DeployAborted("C1", d)
+
+	assert.Equal(t, map[string]string{
+		// the Slack locale of the user
+		"DMU2": "Der Deploy von Deploy 1 durch <@U1|owner> in <#C1> wurde abgebrochen (broken build)",
+		// unsupported Slack locale, the channel one is used
+		"DMU3": "El deploy de Deploy 1 por <@U1|owner> en <#C1> se ha cancelado (broken build)",
+	}, messages)
+}
+
+func TestBot_Subscribe(t *testing.T) {
+	const (
+		slackToken  = "slack-token"
+		webAPIToken = "xxxxx-token1"
+	)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"members":[]}`)
+	})
+	mux.HandleFunc("/conversations.info", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":%q,"is_private":%t}}`, r.FormValue("channel"), strings.HasPrefix(r.FormValue("channel"), "G"))
+	})
+	mux.HandleFunc("/users.conversations", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("user") == "U3" {
+			fmt.Fprint(w, `{"ok":true,"channels":[{"id":"C2"},{"id":"G1"}]}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"ok":true,"channels":[{"id":"C2"}]}`)
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages = append(messages, r.FormValue("channel")+": "+r.FormValue("text"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI(webAPIToken, nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+
+	b := bot.New(slackToken, "", store)
+	b.AddDeployEventHandler(bot.NewSlackSubscriptionNotifier(api, store, nil))
+	b.EnableTeamDirectory(api)
+
+	command := func(channelID, userID, text string) string {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"channel_id": {channelID},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp slack.Response
+		if rec.Body.Len() > 0 {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		}
+
+		return resp.Text
+	}
+
+	assert.Equal(t, "You will get a direct message whenever a deploy is started, done or aborted in <#C1>", command("C1", "U2", "subscribe"))
+	assert.Equal(t, "You will get a direct message whenever a deploy is started, done or aborted in <#C1>", command("C2", "U3", "subscribe <#C1|general>"))
+	command("C2", "U4", "subscribe <#C1|general>")
+	assert.Equal(t, "You will no longer get direct messages about deploys in <#C1>", command("C2", "U4", "unsubscribe <#C1|general>"))
+	assert.Contains(t, command("C2", "U4", "subscribe general"), "usage")
+
+	// private channels are only open to their members
+	assert.Equal(t, "You will get a direct message whenever a deploy is started, done or aborted in <#G1>", command("C2", "U3", "subscribe <#G1|secret>"))
+	assert.Contains(t, command("C2", "U4", "subscribe <#G1|secret>"), "private channel you are not a member of")
+	assert.Equal(t, []slack.User{{ID: "U3", Name: "u3"}}, deploy.NewChannelDeploys(store).Subscribers(deploy.Channel{ID: "G1"}))
+
+	received := func() []string {
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		ms := messages
+		messages = nil
+
+		return ms
+	}
+
+	command("C1", "U1", "first deploy")
+	assert.ElementsMatch(t, []string{
+		"DMU2: <@U1|u1> has started deploying first deploy in <#C1>",
+		"DMU3: <@U1|u1> has started deploying first deploy in <#C1>",
+	}, received())
+
+	// subscribers learn about the end of a deploy even if there is another one waiting in the queue
+	command("C1", "U4", "second deploy")
+	command("C1", "U1", "done")
+	assert.ElementsMatch(t, []string{
+		"DMU2: <@U1|u1> has finished deploying first deploy in <#C1>",
+		"DMU3: <@U1|u1> has finished deploying first deploy in <#C1>",
+		"DMU2: <@U4|u4> has started deploying second deploy in <#C1>",
+		"DMU3: <@U4|u4> has started deploying second deploy in <#C1>",
+	}, received())
+
+	command("C1", "U5", "third deploy")
+	command("C1", "U4", "abort broken build")
+	assert.ElementsMatch(t, []string{
+		"DMU2: The deploy of second deploy by <@U4|u4> in <#C1> has been aborted (broken build)",
+		"DMU3: The deploy of second deploy by <@U4|u4> in <#C1> has been aborted (broken build)",
+		"DMU2: <@U5|u5> has started deploying third deploy in <#C1>",
+		"DMU3: <@U5|u5> has started deploying third deploy in <#C1>",
+	}, received())
+
+	command("C1", "U5", "abort")
+	assert.ElementsMatch(t, []string{
+		"DMU2: The deploy of third deploy by <@U5|u5> in <#C1> has been aborted",
+		"DMU3: The deploy of third deploy by <@U5|u5> in <#C1> has been aborted",
+	}, received())
+
+	command("C1", "U1", "fourth deploy")
+	command("C1", "U1", "done")
+	assert.ElementsMatch(t, []string{
+		"DMU2: <@U1|u1> has started deploying fourth deploy in <#C1>",
+		"DMU3: <@U1|u1> has started deploying fourth deploy in <#C1>",
+		"DMU2: <@U1|u1> has finished deploying fourth deploy in <#C1>",
+		"DMU3: <@U1|u1> has finished deploying fourth deploy in <#C1>",
+	}, received())
+}
@@ -11,47 +11,75 @@ import (
 const (
 	DeployInProgressEmotion = ":no_entry:"
 	DeployDoneEmotion       = ":white_check_mark:"
+	DeployLockedEmotion     = ":lock:"
 )
 
 type SlackTopicManager struct {
-	api *slack.WebAPI
+	clients *workspaceClients
 }
 
-func NewSlackTopicManager(webAPIClient *slack.WebAPI) *SlackTopicManager {
-	return &SlackTopicManager{api: webAPIClient}
+func NewSlackTopicManager(webAPIClients slack.WebAPIClients) *SlackTopicManager {
+	return &SlackTopicManager{clients: newWorkspaceClients(webAPIClients)}
 }
 
-func (mgr *SlackTopicManager) DeployStarted(channelID string, _ deploy.Deploy) {
-	err := mgr.channelTopicReplace(channelID, DeployDoneEmotion, DeployInProgressEmotion)
+func (mgr *SlackTopicManager) DeployStarted(channelID string, d deploy.Deploy) {
+	err := mgr.channelTopicReplace(d.TeamID, channelID, strings.NewReplacer(DeployDoneEmotion, DeployInProgressEmotion))
 	if err != nil {
 		log.Printf("slack-topic-manager: %s", err)
 	}
 }
 
-func (mgr *SlackTopicManager) DeployCompleted(channelID string, _ deploy.Deploy) {
-	err := mgr.channelTopicReplace(channelID, DeployInProgressEmotion, DeployDoneEmotion)
+func (mgr *SlackTopicManager) DeployCompleted(channelID string, d deploy.Deploy) {
+	err := mgr.channelTopicReplace(d.TeamID, channelID, strings.NewReplacer(DeployInProgressEmotion, DeployDoneEmotion))
 	if err != nil {
 		log.Printf("slack-topic-manager: %s", err)
 	}
 }
 
-func (mgr *SlackTopicManager) DeployAborted(channelID string, _ deploy.Deploy) {
-	err := mgr.channelTopicReplace(channelID, DeployInProgressEmotion, DeployDoneEmotion)
+func (mgr *SlackTopicManager) DeployAborted(channelID string, d deploy.Deploy) {
+	err := mgr.channelTopicReplace(d.TeamID, channelID, strings.NewReplacer(DeployInProgressEmotion, DeployDoneEmotion))
 	if err != nil {
 		log.Printf("slack-topic-manager: %s", err)
 	}
 }
 
-func (mgr *SlackTopicManager) channelTopicReplace(channelID, old, new string) error {
-	currentTopic, err := mgr.api.GetChannelTopic(channelID)
+func (mgr *SlackTopicManager) ChannelLocked(channelID string, l deploy.Lock) {
+	err := mgr.channelTopicReplace(l.TeamID, channelID, strings.NewReplacer(DeployDoneEmotion, DeployLockedEmotion, DeployInProgressEmotion, DeployLockedEmotion))
+	if err != nil {
+		log.Printf("slack-topic-manager: %s", err)
+	}
+}
+
+func (mgr *SlackTopicManager) ChannelUnlocked(channelID string, l deploy.Lock, deployInProgress bool) {
+	status := DeployDoneEmotion
+	if deployInProgress {
+		status = DeployInProgressEmotion
+	}
+
+	err := mgr.channelTopicReplace(l.TeamID, channelID, strings.NewReplacer(DeployLockedEmotion, status))
+	if err != nil {
+		log.Printf("slack-topic-manager: %s", err)
+	}
+}
+
+func (mgr *SlackTopicManager) channelTopicReplace(teamID, channelID string, r *strings.Replacer) error {
+	api, err := mgr.clients.WebAPI(teamID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := webAPIContext()
+	defer cancel()
+
+	currentTopic, err := api.GetChannelTopicContext(ctx, channelID)
 	if err != nil {
 		return err
 	}
 
-	newTopic := strings.Replace(currentTopic, old, new, -1)
+	newTopic := r.Replace(currentTopic)
 	if newTopic == currentTopic {
 		return nil
 	}
 
-	return mgr.api.SetConversationTopic(channelID, newTopic)
+	return api.SetConversationTopicContext(ctx, channelID, newTopic)
 }
@@ -163,6 +163,54 @@ func TestSlackTopicManager_DeployAborted_NoRunningDeploy(t *testing.T) {
 	assert.Equal(t, "-=:poop:"+strings.Repeat(bot.DeployDoneEmotion, 3)+":poop:=-", channel.Topic)
 }
 
+func TestSlackTopicManager_ChannelLocked(t *testing.T) {
+	baseURL, channel, teardown := setupSlackWebAPITestServer(t)
+	defer teardown()
+
+	channel.ID = "CHANNELID1"
+	channel.Topic = "-=:poop:" + bot.DeployDoneEmotion + bot.DeployInProgressEmotion + ":poop:=-"
+
+	webAPI := slack.NewWebAPI(webAPIToken, nil)
+	webAPI.BaseURL = baseURL
+
+	mgr := bot.NewSlackTopicManager(webAPI)
+	mgr.ChannelLocked(channel.ID, deploy.Lock{})
+
+	assert.Equal(t, "-=:poop:"+strings.Repeat(bot.DeployLockedEmotion, 2)+":poop:=-", channel.Topic)
+}
+
+func TestSlackTopicManager_ChannelUnlocked_NoRunningDeploy(t *testing.T) {
+	baseURL, channel, teardown := setupSlackWebAPITestServer(t)
+	defer teardown()
+
+	channel.ID = "CHANNELID1"
+	channel.Topic = "-=:poop:" + bot.DeployLockedEmotion + ":poop:=-"
+
+	webAPI := slack.NewWebAPI(webAPIToken, nil)
+	webAPI.BaseURL = baseURL
+
+	mgr := bot.NewSlackTopicManager(webAPI)
+	mgr.ChannelUnlocked(channel.ID, deploy.Lock{}, false)
+
+	assert.Equal(t, "-=:poop:"+bot.DeployDoneEmotion+":poop:=-", channel.Topic)
+}
+
+func TestSlackTopicManager_ChannelUnlocked_InProgress(t *testing.T) {
+	baseURL, channel, teardown := setupSlackWebAPITestServer(t)
+	defer teardown()
+
+	channel.ID = "CHANNELID1"
+	channel.Topic = "-=:poop:" + bot.DeployLockedEmotion + ":poop:=-"
+
+	webAPI := slack.NewWebAPI(webAPIToken, nil)
+	webAPI.BaseURL = baseURL
+
+	mgr := bot.NewSlackTopicManager(webAPI)
+	mgr.ChannelUnlocked(channel.ID, deploy.Lock{}, true)
+
+	assert.Equal(t, "-=:poop:"+bot.DeployInProgressEmotion+":poop:=-", channel.Topic)
+}
+
 func setupSlackWebAPITestServer(t *testing.T) (baseURL string, channel *SlackChannel, teardownFn func()) {
 	channel = &SlackChannel{}
 	mux := http.NewServeMux()
@@ -173,7 +221,7 @@ func setupSlackWebAPITestServer(t *testing.T) (baseURL string, channel *SlackCha
 	})
 
 	mux.HandleFunc("/conversations.setTopic", func(w http.ResponseWriter, r *http.Request) {
-		if token := r.FormValue("token"); !assert.Equal(t, webAPIToken, token) {
+		if token := r.Header.Get("Authorization"); !assert.Equal(t, "Bearer "+webAPIToken, token) {
 			fmt.Fprintf(w, `{"ok":false,"error":"wrong token %q"}`, token)
 			return
 		}
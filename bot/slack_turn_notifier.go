@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+)
+
+const turnNotificationMessage = "It's your turn to deploy %s in <#%s>. Type `/deploy done` in the channel once you are finished, " +
+	"or `/deploy notifications off` if you don't want to receive these messages."
+
+// SlackTurnNotifier sends a direct message to the owner of a queued deploy once it is started. The message is
+// translated into the language of the owner if locales are provided.
+type SlackTurnNotifier struct {
+	clients  *workspaceClients
+	settings deploy.UserSettingsStore
+	locales  *Localizer
+}
+
+func NewSlackTurnNotifier(api slack.WebAPIClients, settings deploy.UserSettingsStore, locales *Localizer) *SlackTurnNotifier {
+	return &SlackTurnNotifier{
+		clients:  newWorkspaceClients(api),
+		settings: settings,
+		locales:  locales,
+	}
+}
+
+func (notifier *SlackTurnNotifier) DeployStarted(channelID string, d deploy.Deploy) {
+	// deploys that have been started right away are announced to the user in the command response
+	if d.QueuedAt.IsZero() {
+		return
+	}
+
+	if notifier.settings.GetUserSettings(d.User.ID).MuteTurnNotifications {
+		return
+	}
+
+	locale := recipientLocale(notifier.locales, notifier.clients, deploy.Channel{TeamID: d.TeamID, ID: channelID, Environment: d.Environment}, d.User)
+	message := slack.Message{
+		Text: fmt.Sprintf(translate(locale, turnNotificationMessage), deploySubject(locale, d.Subject, d), channelID),
+	}
+
+	im, err := notifier.clients.InstantMessenger(d.TeamID)
+	if err == nil {
+		ctx, cancel := webAPIContext()
+		err = im.SendMessageContext(ctx, d.User, message)
+		cancel()
+	}
+
+	if err != nil {
+		log.Printf("failed to send an instant message to %s: %s", d.User.Name, err)
+	}
+}
+
+func (notifier *SlackTurnNotifier) DeployCompleted(_ string, _ deploy.Deploy) {}
+
+func (notifier *SlackTurnNotifier) DeployAborted(_ string, _ deploy.Deploy) {}
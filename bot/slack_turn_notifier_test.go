@@ -0,0 +1,110 @@
+package bot_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlackTurnNotifier_DeployStarted(t *testing.T) {
+	const webAPIToken = "xxxxx-token1"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var messages []string
+
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		messages = append(messages, r.FormValue("channel")+": "+r.FormValue("text"))
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI(webAPIToken, nil)
+	api.BaseURL = server.URL
+
+	settings := deploy.NewInMemoryStore()
+	settings.SetUserSettings("U3", deploy.UserSettings{MuteTurnNotifications: true})
+
+	notifier := bot.NewSlackTurnNotifier(api, settings, nil)
+
+	// started right away
+	notifier.DeployStarted("C1", deploy.Deploy{
+		User:      slack.User{ID: "U1", Name: "user1"},
+		Subject:   "Deploy 1",
+		StartedAt: time.Now(),
+	})
+
+	// started after waiting in the queue
+	notifier.DeployStarted("C1", deploy.Deploy{
+		User:        slack.User{ID: "U2", Name: "user2"},
+		Subject:     "Deploy 2",
+		Environment: "staging",
+		QueuedAt:    time.Now().Add(-time.Hour),
+		StartedAt:   time.Now(),
+	})
+
+	// opted out
+	notifier.DeployStarted("C1", deploy.Deploy{
+		User:      slack.User{ID: "U3", Name: "user3"},
+		Subject:   "Deploy 3",
+		QueuedAt:  time.Now().Add(-time.Hour),
+		StartedAt: time.Now(),
+	})
+
+	if assert.Len(t, messages, 1) {
+		assert.Contains(t, messages[0], "DMU2: It's your turn to deploy Deploy 2 to staging in <#C1>.")
+	}
+}
+
+func TestSlackTurnNotifier_Locale(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	messages := make(map[string]string)
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"U1","name":"user1","locale":"de-DE"},{"id":"U2","name":"user2","locale":"fr-FR"}]}`)
+	})
+	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok":true,"channel":{"id":"DM%s"}}`, r.FormValue("users"))
+	})
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		messages[r.FormValue("channel")] = r.FormValue("text")
+		fmt.Fprint(w, `{"ok":true}`)
+	})
+
+	api := slack.NewWebAPI("xxxxx-token1", nil)
+	api.BaseURL = server.URL
+
+	store := deploy.NewInMemoryStore()
+	deploy.NewChannelDeploys(store).SetLocale(deploy.Channel{ID: "C1"}, "es")
+
+	notifier := bot.NewSlackTurnNotifier(api, store, bot.NewLocalizer(store))
+	for _, userID := range []string{"U1", "U2"} {
+		notifier.DeployStarted("C1", deploy.Deploy{
+			User:        slack.User{ID: userID, Name: strings.ToLower(userID)},
+			Subject:     "Deploy 1",
+			Environment: "staging",
+			QueuedAt:    time.Now().Add(-time.Hour),
+			StartedAt:   time.Now(),
+		})
+	}
+
+	// the Slack locale of the user
+	assert.Contains(t, messages["DMU1"], "Du bist dran, Deploy 1 nach staging in <#C1> zu deployen.")
+	// unsupported Slack locale, the channel one is used
+	assert.Contains(t, messages["DMU2"], "Es tu turno de desplegar Deploy 1 en staging en <#C1>.")
+}
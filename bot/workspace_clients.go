@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/adjust/michaelbot/slack"
+)
+
+// webAPITimeout limits Web API calls that are not bound to an incoming request, such as announcements and
+// notifications sent in the background, including the retries of rate-limited calls
+const webAPITimeout = 30 * time.Second
+
+// webAPIContext returns the context for a background Web API call. The caller is responsible for calling cancel.
+func webAPIContext() (ctx context.Context, cancel context.CancelFunc) {
+	return context.WithTimeout(context.Background(), webAPITimeout)
+}
+
+// workspaceClients resolves Web API clients for Slack workspaces. It also keeps an instant messenger and a team
+// directory for each workspace, so that IM channels and user lists are cached between calls. The cache of a workspace
+// is dropped once the app is reinstalled into it.
+type workspaceClients struct {
+	clients slack.WebAPIClients
+
+	mu         sync.Mutex
+	workspaces map[string]*workspaceCache
+}
+
+// workspaceCache holds the clients created with the Web API client of a workspace
+type workspaceCache struct {
+	api *slack.WebAPI
+	im  *slack.InstantMessenger
+	dir *slack.TeamDirectory
+}
+
+// installationNotifier is implemented by WebAPIClients that replace the client of a workspace once the app
+// is reinstalled, i.e. slack.Installations.
+type installationNotifier interface {
+	OnReplace(f func(teamID string))
+}
+
+func newWorkspaceClients(clients slack.WebAPIClients) *workspaceClients {
+	ws := &workspaceClients{
+		clients:    clients,
+		workspaces: make(map[string]*workspaceCache),
+	}
+
+	if n, ok := clients.(installationNotifier); ok {
+		n.OnReplace(ws.forget)
+	}
+
+	return ws
+}
+
+// WebAPI returns the client for the workspace identified by teamID.
+func (ws *workspaceClients) WebAPI(teamID string) (*slack.WebAPI, error) {
+	api, ok := ws.clients.ForTeam(teamID)
+	if !ok {
+		return nil, fmt.Errorf("the app is not installed into workspace %s", teamID)
+	}
+
+	return api, nil
+}
+
+// InstantMessenger returns the instant messenger for the workspace identified by teamID.
+func (ws *workspaceClients) InstantMessenger(teamID string) (*slack.InstantMessenger, error) {
+	api, err := ws.WebAPI(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	c := ws.cache(teamID, api)
+	if c.im == nil {
+		c.im = slack.NewInstantMessenger(api)
+	}
+
+	return c.im, nil
+}
+
+// TeamDirectory returns the user directory of the workspace identified by teamID.
+func (ws *workspaceClients) TeamDirectory(teamID string) (*slack.TeamDirectory, error) {
+	api, err := ws.WebAPI(teamID)
+	if err != nil {
+		return nil, err
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	c := ws.cache(teamID, api)
+	if c.dir == nil {
+		c.dir = slack.NewTeamDirectory(api)
+	}
+
+	return c.dir, nil
+}
+
+// cache returns the cache of the workspace identified by teamID, replacing it if it has been created with another
+// client than api. It should be called with ws.mu locked.
+func (ws *workspaceClients) cache(teamID string, api *slack.WebAPI) *workspaceCache {
+	c, ok := ws.workspaces[teamID]
+	if !ok || c.api != api {
+		c = &workspaceCache{api: api}
+		ws.workspaces[teamID] = c
+	}
+
+	return c
+}
+
+// forget drops the cache of the workspace identified by teamID.
+func (ws *workspaceClients) forget(teamID string) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	delete(ws.workspaces, teamID)
+}
@@ -0,0 +1,165 @@
+package bot_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/bot"
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBot_SeparateWorkspaces(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu       sync.Mutex
+		messages []string
+	)
+
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		messages = append(messages, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")+"/"+r.FormValue("channel")+": "+r.FormValue("text"))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true,"ts":"1.1"}`)
+	})
+
+	store := deploy.NewInMemoryStore()
+	store.SetInstallation(slack.Installation{TeamID: "T1", AccessToken: "xoxb-1"})
+	store.SetInstallation(slack.Installation{TeamID: "T2", AccessToken: "xoxb-2"})
+
+	installations := slack.NewInstallations(store, nil)
+	installations.BaseURL = server.URL
+
+	b := bot.New(slackToken, "", store)
+	b.SeparateWorkspaces()
+	b.EnableDeployThreads(installations)
+
+	command := func(teamID, userID, text string) {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"team_id":    {teamID},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// channels with the same ID in different workspaces have separate queues
+	command("T1", "U1", "first deploy")
+	command("T2", "U2", "second deploy")
+
+	assert.Equal(t, []string{"T1-C1", "T2-C1"}, store.ChannelKeys())
+
+	deploys := deploy.NewChannelDeploys(store)
+	if d, ok := deploys.Current(deploy.Channel{TeamID: "T1", ID: "C1"}); assert.True(t, ok) {
+		assert.Equal(t, "U1", d.User.ID)
+		assert.Equal(t, "T1", d.TeamID)
+	}
+
+	if d, ok := deploys.Current(deploy.Channel{TeamID: "T2", ID: "C1"}); assert.True(t, ok) {
+		assert.Equal(t, "U2", d.User.ID)
+		assert.Equal(t, "T2", d.TeamID)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	// announcements are posted with the bot token of each workspace
+	assert.Equal(t, []string{
+		"xoxb-1/C1: <@U1|u1> is about to deploy first deploy",
+		"xoxb-2/C1: <@U2|u2> is about to deploy second deploy",
+	}, messages)
+}
+
+func TestBot_Reinstall(t *testing.T) {
+	const slackToken = "slack-token"
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	var (
+		mu     sync.Mutex
+		tokens []string
+	)
+
+	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokens = append(tokens, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		mu.Unlock()
+
+		fmt.Fprint(w, `{"ok":true,"members":[{"id":"U1","name":"u1"},{"id":"U2","name":"u2"}]}`)
+	})
+
+	store := deploy.NewInMemoryStore()
+	store.SetInstallation(slack.Installation{TeamID: "T1", AccessToken: "xoxb-1"})
+
+	installations := slack.NewInstallations(store, nil)
+	installations.BaseURL = server.URL
+
+	b := bot.New(slackToken, "", store)
+	b.SeparateWorkspaces()
+	b.EnableTeamDirectory(installations)
+
+	command := func(userID, text string) {
+		form := url.Values{
+			"token":      {slackToken},
+			"command":    {"/deploy"},
+			"team_id":    {"T1"},
+			"channel_id": {"C1"},
+			"user_id":    {userID},
+			"user_name":  {strings.ToLower(userID)},
+			"text":       {text},
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		rec := httptest.NewRecorder()
+		b.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	command("U1", "first deploy")
+	command("U1", "handover @u2")
+
+	// the user list is fetched again with the new token once the app is reinstalled
+	installations.Add(slack.Installation{TeamID: "T1", AccessToken: "xoxb-2"})
+	command("U2", "handover @u1")
+
+	if d, ok := deploy.NewChannelDeploys(store).Current(deploy.Channel{TeamID: "T1", ID: "C1"}); assert.True(t, ok) {
+		assert.Equal(t, "U1", d.User.ID)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"xoxb-1", "xoxb-2"}, tokens)
+}
@@ -27,6 +27,8 @@ func (h *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ch := deploy.Channel{ID: channelID, Environment: EnvironmentFromRequest(r)}
+
 	var history []deploy.Deploy
 	if v := r.FormValue("since"); v != "" {
 		timeSince, err := time.Parse(time.RFC3339, v)
@@ -38,9 +40,9 @@ func (h *Dashboard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		history = h.repo.Since(channelID, timeSince)
+		history = h.repo.Since(ch.Key(), timeSince)
 	} else {
-		history = h.repo.All(channelID)
+		history = h.repo.All(ch.Key())
 	}
 
 	if err := Responder(r).RespondWithHistory(w, history); err != nil {
@@ -64,6 +66,26 @@ func ChannelIDFromRequest(r *http.Request) string {
 	return path
 }
 
+// EnvironmentFromRequest extracts and returns deploy environment name from request URL, i.e. staging
+// for /channel1/staging.json. It returns an empty string for the default environment.
+func EnvironmentFromRequest(r *http.Request) string {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+
+	n := strings.IndexByte(path, '/')
+	if n < 0 {
+		return ""
+	}
+	path = path[n+1:]
+
+	if n := strings.IndexByte(path, '/'); n >= 0 {
+		return path[:n]
+	} else if n := strings.LastIndexByte(path, '.'); n >= 0 {
+		return path[:n]
+	}
+
+	return path
+}
+
 // Responder returns a formatters.ResponseFormatter according to the extension in URL path.
 func Responder(r *http.Request) formatters.ResponseFormatter {
 	switch {
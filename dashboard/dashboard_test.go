@@ -86,6 +86,11 @@ func TestDashboard_MultipleDeploys(t *testing.T) {
 
 	d4 := deploy.New(slack.User{ID: "2", Name: "Another User"}, "Forth deploy")
 	d4.StartedAt, _ = time.Parse(time.RFC822, "04 Aug 16 09:50 CEST")
+	d4.FreezeOverride = &deploy.FreezeOverride{
+		User:   slack.User{ID: "2", Name: "Another User"},
+		Freeze: "Fri 15:00 - Mon 08:00 UTC",
+		Reason: "payments are down",
+	}
 
 	var repo repoMock
 	repo.On("All", "key1").Return([]deploy.Deploy{d1, d2, d3, d4})
@@ -109,7 +114,7 @@ func TestDashboard_MultipleDeploys(t *testing.T) {
 		"* Test User was deploying First deploy since 04 Aug 16 09:28 CEST until 04 Aug 16 09:38 CEST\n" +
 		"* Test User was deploying Second deploy since 04 Aug 16 09:39 CEST until 04 Aug 16 09:40 CEST (aborted)\n" +
 		"* Test User was deploying Third deploy since 04 Aug 16 09:42 CEST until 04 Aug 16 09:43 CEST (aborted, something went wrong)\n" +
-		"* Another User is currently deploying Forth deploy since 04 Aug 16 09:50 CEST"
+		"* Another User is currently deploying Forth deploy since 04 Aug 16 09:50 CEST (ignoring deploy freeze Fri 15:00 - Mon 08:00 UTC, payments are down)"
 
 	assert.Equal(t, expected, string(bytes.TrimSpace(body)))
 
@@ -257,6 +262,56 @@ func TestChannelIDFromRequest(t *testing.T) {
 	}
 }
 
+func TestDashboard_Environment(t *testing.T) {
+	baseURL, mux, teardown := setup()
+	defer teardown()
+
+	d := deploy.New(slack.User{ID: "1", Name: "Test User"}, "Test deploy")
+	d.Environment = "staging"
+	d.StartedAt, _ = time.Parse(time.RFC822, "04 Aug 16 09:28 CEST")
+	d.FinishedAt, _ = time.Parse(time.RFC822, "04 Aug 16 09:38 CEST")
+
+	var repo repoMock
+	repo.On("All", "key1/staging").Return([]deploy.Deploy{d})
+
+	mux.Handle("/", dashboard.New(&repo))
+
+	response, err := http.Get(baseURL + "/key1/staging.json")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+
+	body, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `"environment":"staging"`)
+
+	repo.AssertExpectations(t)
+}
+
+func TestEnvironmentFromRequest(t *testing.T) {
+	examples := map[string]string{
+		"/channel1":                     "",
+		"/channel2.json":                "",
+		"/channel3/staging":             "staging",
+		"/channel4/staging.json":        "staging",
+		"/channel5/staging.txt?key=val": "staging",
+		"/channel6/staging/history":     "staging",
+		"/":                             "",
+	}
+
+	for path, expectedEnv := range examples {
+		req, err := http.NewRequest("GET", path, nil)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		assert.Equal(t, expectedEnv, dashboard.EnvironmentFromRequest(req), path)
+	}
+}
+
 func setup() (url string, mux *http.ServeMux, teardownFn func()) {
 	mux = http.NewServeMux()
 	srv := httptest.NewServer(mux)
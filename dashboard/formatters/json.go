@@ -13,12 +13,26 @@ var (
 )
 
 type jsonPresenter struct {
-	Author     string    `json:"author"`
-	Subject    string    `json:"subject"`
-	StartedAt  time.Time `json:"started_at"`
-	FinishedAt time.Time `json:"finished_at,omitempty"`
-	Aborted    bool      `json:"aborted,omitempty"`
-	Reason     string    `json:"reason,omitempty"`
+	Author       string                     `json:"author"`
+	Subject      string                     `json:"subject"`
+	Env          string                     `json:"environment,omitempty"`
+	StartedAt    time.Time                  `json:"started_at"`
+	FinishedAt   time.Time                  `json:"finished_at,omitempty"`
+	Aborted      bool                       `json:"aborted,omitempty"`
+	Reason       string                     `json:"reason,omitempty"`
+	Freeze       string                     `json:"ignored_freeze,omitempty"`
+	FreezeReason string                     `json:"ignored_freeze_reason,omitempty"`
+	Urgent       bool                       `json:"urgent,omitempty"`
+	QueueChanges []jsonQueueChangePresenter `json:"queue_changes,omitempty"`
+	PrevAuthors  []string                   `json:"previous_authors,omitempty"`
+}
+
+type jsonQueueChangePresenter struct {
+	Author    string    `json:"author"`
+	Action    string    `json:"action"`
+	From      int       `json:"from,omitempty"`
+	To        int       `json:"to"`
+	ChangedAt time.Time `json:"changed_at"`
 }
 
 type jsonFormatter struct{}
@@ -30,10 +44,28 @@ func (jsonFormatter) RespondWithHistory(w http.ResponseWriter, history []deploy.
 	for i, d := range history {
 		v[i].Author = d.User.Name
 		v[i].Subject = d.Subject
+		v[i].Env = d.Environment
 		v[i].StartedAt = d.StartedAt
 		v[i].FinishedAt = d.FinishedAt
 		v[i].Aborted = d.Aborted
 		v[i].Reason = d.AbortReason
+		if d.FreezeOverride != nil {
+			v[i].Freeze = d.FreezeOverride.Freeze
+			v[i].FreezeReason = d.FreezeOverride.Reason
+		}
+		v[i].Urgent = d.Priority
+		for _, u := range d.PreviousOwners {
+			v[i].PrevAuthors = append(v[i].PrevAuthors, u.Name)
+		}
+		for _, c := range d.QueueChanges {
+			v[i].QueueChanges = append(v[i].QueueChanges, jsonQueueChangePresenter{
+				Author:    c.User.Name,
+				Action:    c.Action,
+				From:      c.From,
+				To:        c.To,
+				ChangedAt: c.ChangedAt,
+			})
+		}
 	}
 
 	data, err := json.Marshal(v)
@@ -23,9 +23,9 @@ Deploy history
 
 {{ range . -}}
 {{ if not .FinishedAt.IsZero -}}
-  * {{ .User.Name }} was deploying {{ .Subject }} since {{ .StartedAt | ftime }} until {{ .FinishedAt | ftime }}{{ if .Aborted }} (aborted{{ if .AbortReason }}, {{ .AbortReason }}{{ end }}){{ end }}
+  * {{ .User.Name }} was deploying {{ .Subject }} since {{ .StartedAt | ftime }} until {{ .FinishedAt | ftime }}{{ if .Aborted }} (aborted{{ if .AbortReason }}, {{ .AbortReason }}{{ end }}){{ end }}{{ if .FreezeOverride }} (ignoring deploy freeze {{ .FreezeOverride.Freeze }}{{ if .FreezeOverride.Reason }}, {{ .FreezeOverride.Reason }}{{ end }}){{ end }}{{ if .Priority }} (urgent){{ end }}{{ if .PreviousOwners }} (handed over by{{ range .PreviousOwners }} {{ .Name }}{{ end }}){{ end }}
 {{ else -}}
-  * {{ .User.Name }} is currently deploying {{ .Subject }} since {{ .StartedAt | ftime }}
+  * {{ .User.Name }} is currently deploying {{ .Subject }} since {{ .StartedAt | ftime }}{{ if .FreezeOverride }} (ignoring deploy freeze {{ .FreezeOverride.Freeze }}{{ if .FreezeOverride.Reason }}, {{ .FreezeOverride.Reason }}{{ end }}){{ end }}
 {{ end -}}
 {{ else -}}
   No deploys in channel so far
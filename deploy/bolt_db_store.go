@@ -5,8 +5,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/adjust/michaelbot/scheduler"
+	"github.com/adjust/michaelbot/slack"
 	"github.com/boltdb/bolt"
 )
 
@@ -21,6 +24,19 @@ const (
 	subscribersKey  = "subscribers"
 )
 
+// Top-level buckets that are not channel deploy records are prefixed with an underscore to avoid
+// collisions with Slack channel IDs.
+const (
+	timersBucket        = "_timers"
+	usersBucket         = "_users"
+	installationsBucket = "_installations"
+	workspacesBucket    = "_workspaces"
+)
+
+// defaultWorkspaceKey is used for the settings of a single workspace app installation, since BoltDB
+// does not allow empty keys.
+const defaultWorkspaceKey = "_default"
+
 var (
 	ErrNoDeploy = errors.New("no deploys in channel")
 )
@@ -92,6 +108,54 @@ func (s *BoltDBStore) SetQueue(key string, queue Queue) {
 	})
 }
 
+func (s *BoltDBStore) GetSettings(key string) (settings ChannelSettings) {
+	s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(key))
+
+		if bucket == nil {
+			return nil
+		}
+
+		bytes := bucket.Get([]byte("settings"))
+
+		if bytes == nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(bytes, &settings); err != nil {
+			settings = ChannelSettings{}
+		}
+
+		return nil
+	})
+
+	return settings
+}
+
+func (s *BoltDBStore) SetSettings(key string, settings ChannelSettings) {
+	s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(key))
+
+		if err != nil {
+			return fmt.Errorf("failed to store settings %#v in channel %s: %s", settings, key, err)
+		}
+
+		bytes, err := json.Marshal(settings)
+
+		if err != nil {
+			return fmt.Errorf("failed to marshal settings %#v: %s", settings, err)
+		}
+
+		err = bucket.Put([]byte("settings"), bytes)
+
+		if err != nil {
+			return fmt.Errorf("failed to put settings into a bucket %#v: %s", settings, err)
+		}
+
+		return nil
+	})
+}
+
 func (s *BoltDBStore) AddToHistory(key string, deploy Deploy) {
 	s.db.Update(func(tx *bolt.Tx) error {
 		bucket, err := tx.CreateBucketIfNotExists([]byte(key))
@@ -196,8 +260,211 @@ func (s *BoltDBStore) Since(key string, startTime time.Time) []Deploy {
 	return deploys
 }
 
+func (s *BoltDBStore) GetUserSettings(userID string) (settings UserSettings) {
+	s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(usersBucket))
+
+		if bucket == nil {
+			return nil
+		}
+
+		bytes := bucket.Get([]byte(userID))
+
+		if bytes == nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(bytes, &settings); err != nil {
+			settings = UserSettings{}
+		}
+
+		return nil
+	})
+
+	return settings
+}
+
+func (s *BoltDBStore) SetUserSettings(userID string, settings UserSettings) {
+	s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(usersBucket))
+
+		if err != nil {
+			return fmt.Errorf("failed to create users bucket: %s", err)
+		}
+
+		bytes, err := json.Marshal(settings)
+
+		if err != nil {
+			return fmt.Errorf("failed to marshal user settings %#v: %s", settings, err)
+		}
+
+		return bucket.Put([]byte(userID), bytes)
+	})
+}
+
+func (s *BoltDBStore) GetInstallation(teamID string) (inst slack.Installation, ok bool) {
+	s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(installationsBucket))
+
+		if bucket == nil {
+			return nil
+		}
+
+		bytes := bucket.Get([]byte(teamID))
+
+		if bytes == nil {
+			return nil
+		}
+
+		ok = json.Unmarshal(bytes, &inst) == nil
+
+		return nil
+	})
+
+	return inst, ok
+}
+
+func (s *BoltDBStore) SetInstallation(inst slack.Installation) {
+	s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(installationsBucket))
+
+		if err != nil {
+			return fmt.Errorf("failed to create installations bucket: %s", err)
+		}
+
+		bytes, err := json.Marshal(inst)
+
+		if err != nil {
+			return fmt.Errorf("failed to marshal installation for %s: %s", inst.TeamID, err)
+		}
+
+		return bucket.Put([]byte(inst.TeamID), bytes)
+	})
+}
+
+func (s *BoltDBStore) GetWorkspaceSettings(teamID string) (settings WorkspaceSettings) {
+	s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(workspacesBucket))
+
+		if bucket == nil {
+			return nil
+		}
+
+		bytes := bucket.Get(workspaceKey(teamID))
+
+		if bytes == nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(bytes, &settings); err != nil {
+			settings = WorkspaceSettings{}
+		}
+
+		return nil
+	})
+
+	return settings
+}
+
+func (s *BoltDBStore) SetWorkspaceSettings(teamID string, settings WorkspaceSettings) {
+	s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(workspacesBucket))
+
+		if err != nil {
+			return fmt.Errorf("failed to create workspaces bucket: %s", err)
+		}
+
+		bytes, err := json.Marshal(settings)
+
+		if err != nil {
+			return fmt.Errorf("failed to marshal workspace settings %#v: %s", settings, err)
+		}
+
+		return bucket.Put(workspaceKey(teamID), bytes)
+	})
+}
+
+func (s *BoltDBStore) ChannelKeys() []string {
+	var keys []string
+
+	s.db.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+			if !strings.HasPrefix(string(name), "_") {
+				keys = append(keys, string(name))
+			}
+
+			return nil
+		})
+	})
+
+	return keys
+}
+
+func (s *BoltDBStore) GetTimers() []scheduler.Timer {
+	var timers []scheduler.Timer
+
+	s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(timersBucket))
+
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.ForEach(func(k, v []byte) error {
+			var t scheduler.Timer
+			if err := json.Unmarshal(v, &t); err != nil {
+				return nil
+			}
+
+			timers = append(timers, t)
+
+			return nil
+		})
+	})
+
+	return timers
+}
+
+func (s *BoltDBStore) SetTimer(t scheduler.Timer) {
+	s.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(timersBucket))
+
+		if err != nil {
+			return fmt.Errorf("failed to create timers bucket: %s", err)
+		}
+
+		bytes, err := json.Marshal(t)
+
+		if err != nil {
+			return fmt.Errorf("failed to marshal timer %#v: %s", t, err)
+		}
+
+		return bucket.Put([]byte(t.ID), bytes)
+	})
+}
+
+func (s *BoltDBStore) RemoveTimer(id string) {
+	s.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(timersBucket))
+
+		if bucket == nil {
+			return nil
+		}
+
+		return bucket.Delete([]byte(id))
+	})
+}
+
 func itob(v uint64) []byte {
 	b := make([]byte, 8)
 	binary.BigEndian.PutUint64(b, v)
 	return b
 }
+
+func workspaceKey(teamID string) []byte {
+	if teamID == "" {
+		return []byte(defaultWorkspaceKey)
+	}
+
+	return []byte(teamID)
+}
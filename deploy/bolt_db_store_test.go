@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/scheduler"
+	"github.com/adjust/michaelbot/slack"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -45,6 +47,78 @@ func TestBoltDBStore_AsRepository(t *testing.T) {
 	}})
 }
 
+func TestBoltDBStore_AsTimerStore(t *testing.T) {
+	suite.Run(t, &TimerStoreSuite{Setup: func() (store scheduler.Store, teardownFn func(), err error) {
+		path, err := tempDBFilePath()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		teardownFn = func() { os.Remove(path) }
+
+		store, err = deploy.NewBoltDBStore(path)
+		if err != nil {
+			return nil, teardownFn, err
+		}
+
+		return store, teardownFn, nil
+	}})
+}
+
+func TestBoltDBStore_AsUserSettingsStore(t *testing.T) {
+	suite.Run(t, &UserSettingsStoreSuite{Setup: func() (store deploy.UserSettingsStore, teardownFn func(), err error) {
+		path, err := tempDBFilePath()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		teardownFn = func() { os.Remove(path) }
+
+		store, err = deploy.NewBoltDBStore(path)
+		if err != nil {
+			return nil, teardownFn, err
+		}
+
+		return store, teardownFn, nil
+	}})
+}
+
+func TestBoltDBStore_AsWorkspaceSettingsStore(t *testing.T) {
+	suite.Run(t, &WorkspaceSettingsStoreSuite{Setup: func() (store deploy.WorkspaceSettingsStore, teardownFn func(), err error) {
+		path, err := tempDBFilePath()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		teardownFn = func() { os.Remove(path) }
+
+		store, err = deploy.NewBoltDBStore(path)
+		if err != nil {
+			return nil, teardownFn, err
+		}
+
+		return store, teardownFn, nil
+	}})
+}
+
+func TestBoltDBStore_AsInstallationStore(t *testing.T) {
+	suite.Run(t, &InstallationStoreSuite{Setup: func() (store slack.InstallationStore, teardownFn func(), err error) {
+		path, err := tempDBFilePath()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		teardownFn = func() { os.Remove(path) }
+
+		store, err = deploy.NewBoltDBStore(path)
+		if err != nil {
+			return nil, teardownFn, err
+		}
+
+		return store, teardownFn, nil
+	}})
+}
+
 func tempDBFilePath() (string, error) {
 	fd, err := ioutil.TempFile(os.TempDir(), "doppelganger")
 	if err != nil {
@@ -0,0 +1,48 @@
+package deploy
+
+import "strings"
+
+// Channel identifies a deploy queue. Each environment within a Slack channel has its own queue and history.
+type Channel struct {
+	// TeamID is the Slack workspace the channel belongs to. It is only set if the app has been installed
+	// into multiple workspaces.
+	TeamID      string
+	ID          string
+	Environment string
+}
+
+// Key returns the store key for the channel queue and history. Deploys to the default (empty) environment
+// are kept under the channel ID to stay compatible with the history recorded before environments were introduced.
+// Channels of different workspaces are prefixed with the team ID.
+func (ch Channel) Key() string {
+	key := ch.ID
+	if ch.TeamID != "" {
+		key = ch.TeamID + "-" + key
+	}
+
+	if ch.Environment == "" {
+		return key
+	}
+
+	return key + "/" + ch.Environment
+}
+
+// SettingsKey returns the store key for the settings shared by all environments in the channel.
+func (ch Channel) SettingsKey() string {
+	return Channel{TeamID: ch.TeamID, ID: ch.ID}.Key()
+}
+
+// ParseChannelKey is the reverse of Channel.Key().
+func ParseChannelKey(key string) Channel {
+	var ch Channel
+	if i := strings.Index(key, "/"); i >= 0 {
+		key, ch.Environment = key[:i], key[i+1:]
+	}
+
+	if i := strings.Index(key, "-"); i >= 0 {
+		ch.TeamID, key = key[:i], key[i+1:]
+	}
+	ch.ID = key
+
+	return ch
+}
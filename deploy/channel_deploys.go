@@ -2,15 +2,34 @@ package deploy
 
 import (
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/adjust/michaelbot/slack"
 )
 
 var (
-	AlreadyInQueueError   = errors.New("User is already in queue")
-	DeployInProgressError = errors.New("Another deploy is in progress")
+	AlreadyInQueueError     = errors.New("User is already in queue")
+	DeployInProgressError   = errors.New("Another deploy is in progress")
+	ChannelLockedError      = errors.New("Deploys in channel are locked")
+	DeployFrozenError       = errors.New("Deploys in channel are frozen")
+	NotInQueueError         = errors.New("User is not in queue")
+	NoDeployInProgressError = errors.New("No deploy in progress")
+	MoveNotAllowedError     = errors.New("Only the deploy owner or the current deployer can move a deploy")
 )
 
+// channelLocks holds a *sync.Mutex per store key to serialize the read-modify-write updates of channel queues
+// and settings. The locks are shared since there may be several ChannelDeploys using the same store.
+var channelLocks sync.Map
+
+// lockKey locks the updates of the value stored under key and returns a function to unlock them.
+func lockKey(key string) func() {
+	mu, _ := channelLocks.LoadOrStore(key, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+
+	return mu.(*sync.Mutex).Unlock
+}
+
 type ChannelDeploys struct {
 	store Store
 }
@@ -19,20 +38,56 @@ func NewChannelDeploys(store Store) *ChannelDeploys {
 	return &ChannelDeploys{store: store}
 }
 
-func (repo *ChannelDeploys) All(channelID string) []Deploy {
-	queue := repo.store.GetQueue(channelID)
+func (repo *ChannelDeploys) All(ch Channel) []Deploy {
+	queue := repo.store.GetQueue(ch.Key())
 
 	return queue.Items
 }
 
-func (repo *ChannelDeploys) Current(channelID string) (Deploy, bool) {
-	queue := repo.store.GetQueue(channelID)
+// Channels returns all channels and environments known to the store.
+func (repo *ChannelDeploys) Channels() []Channel {
+	var channels []Channel
+	for _, key := range repo.store.ChannelKeys() {
+		channels = append(channels, ParseChannelKey(key))
+	}
+
+	return channels
+}
+
+func (repo *ChannelDeploys) Current(ch Channel) (Deploy, bool) {
+	queue := repo.store.GetQueue(ch.Key())
 
 	return queue.Current()
 }
 
-func (repo *ChannelDeploys) Start(channelID string, deploy Deploy) (Deploy, error) {
-	queue := repo.store.GetQueue(channelID)
+// Start starts a deploy or puts it into the queue if there is another deploy in progress. Deploys with Priority set
+// are put right after the running one.
+func (repo *ChannelDeploys) Start(ch Channel, deploy Deploy) (Deploy, error) {
+	if _, frozen := repo.ActiveFreeze(ch, time.Now()); frozen {
+		return deploy, DeployFrozenError
+	}
+
+	return repo.start(ch, deploy)
+}
+
+// ForceStart starts or queues a deploy ignoring active deploy freeze. The broken freeze rule is recorded
+// in the deploy along with the reason to override it. Channel locks still apply to forced deploys.
+func (repo *ChannelDeploys) ForceStart(ch Channel, deploy Deploy, reason string) (Deploy, error) {
+	if rule, frozen := repo.ActiveFreeze(ch, time.Now()); frozen {
+		deploy.FreezeOverride = &FreezeOverride{User: deploy.User, Freeze: rule.String(), Reason: reason}
+	}
+
+	return repo.start(ch, deploy)
+}
+
+func (repo *ChannelDeploys) start(ch Channel, deploy Deploy) (Deploy, error) {
+	defer lockKey("queue:" + ch.Key())()
+
+	if _, locked := repo.Locked(ch); locked {
+		return deploy, ChannelLockedError
+	}
+
+	queue := repo.store.GetQueue(ch.Key())
 
 	current, deployInProgress := queue.Current()
 
@@ -40,22 +95,38 @@ func (repo *ChannelDeploys) Start(channelID string, deploy Deploy) (Deploy, erro
 		return deploy, AlreadyInQueueError
 	}
 
+	deploy.Environment = ch.Environment
+	deploy.TeamID = ch.TeamID
+
 	if deployInProgress {
-		queue.Add(deploy)
-		repo.store.SetQueue(channelID, queue)
+		deploy.QueuedAt = time.Now().UTC()
+		if deploy.Priority {
+			pos := queue.AddUrgent(deploy)
+			queue.Items[pos].QueueChanges = append(queue.Items[pos].QueueChanges, QueueChange{
+				User:      deploy.User,
+				Action:    QueueChangeUrgent,
+				To:        pos,
+				ChangedAt: time.Now().UTC(),
+			})
+		} else {
+			queue.Add(deploy)
+		}
+		repo.store.SetQueue(ch.Key(), queue)
 
 		return current, DeployInProgressError
 	}
 
 	deploy.Start()
 	queue.Add(deploy)
-	repo.store.SetQueue(channelID, queue)
+	repo.store.SetQueue(ch.Key(), queue)
 
 	return deploy, nil
 }
 
-func (repo *ChannelDeploys) Finish(channelID string) (Deploy, bool) {
-	queue := repo.store.GetQueue(channelID)
+func (repo *ChannelDeploys) Finish(ch Channel) (Deploy, bool) {
+	defer lockKey("queue:" + ch.Key())()
+
+	queue := repo.store.GetQueue(ch.Key())
 	current, deployInProgress := queue.Pop()
 
 	if !deployInProgress {
@@ -63,7 +134,7 @@ func (repo *ChannelDeploys) Finish(channelID string) (Deploy, bool) {
 	}
 
 	current.Finish()
-	repo.store.AddToHistory(channelID, current)
+	repo.store.AddToHistory(ch.Key(), current)
 
 	next, queueIsNotEmpty := queue.Current()
 
@@ -72,13 +143,15 @@ func (repo *ChannelDeploys) Finish(channelID string) (Deploy, bool) {
 		queue.ReplaceHeadWith(next)
 	}
 
-	repo.store.SetQueue(channelID, queue)
+	repo.store.SetQueue(ch.Key(), queue)
 
 	return current, true
 }
 
-func (repo *ChannelDeploys) Abort(channelID, reason string) (Deploy, bool) {
-	queue := repo.store.GetQueue(channelID)
+func (repo *ChannelDeploys) Abort(ch Channel, reason string) (Deploy, bool) {
+	defer lockKey("queue:" + ch.Key())()
+
+	queue := repo.store.GetQueue(ch.Key())
 	current, deployInProgress := queue.Pop()
 
 	if !deployInProgress {
@@ -86,7 +159,7 @@ func (repo *ChannelDeploys) Abort(channelID, reason string) (Deploy, bool) {
 	}
 
 	current.Abort(reason)
-	repo.store.AddToHistory(channelID, current)
+	repo.store.AddToHistory(ch.Key(), current)
 
 	next, queueIsNotEmpty := queue.Current()
 
@@ -95,19 +168,313 @@ func (repo *ChannelDeploys) Abort(channelID, reason string) (Deploy, bool) {
 		queue.ReplaceHeadWith(next)
 	}
 
-	repo.store.SetQueue(channelID, queue)
+	repo.store.SetQueue(ch.Key(), queue)
 
 	return current, true
 }
 
-func (repo *ChannelDeploys) LeaveQueue(channelID string, user slack.User) bool {
-	queue := repo.store.GetQueue(channelID)
+func (repo *ChannelDeploys) LeaveQueue(ch Channel, user slack.User) bool {
+	defer lockKey("queue:" + ch.Key())()
+
+	queue := repo.store.GetQueue(ch.Key())
 
 	userHasBeenRemoved := queue.RemoveUser(user)
 
 	if userHasBeenRemoved {
-		repo.store.SetQueue(channelID, queue)
+		repo.store.SetQueue(ch.Key(), queue)
 	}
 
 	return userHasBeenRemoved
 }
+
+// Edit changes the subject of running or scheduled deploy started by user.
+func (repo *ChannelDeploys) Edit(ch Channel, user slack.User, subject string) (Deploy, error) {
+	defer lockKey("queue:" + ch.Key())()
+
+	queue := repo.store.GetQueue(ch.Key())
+
+	pos, ok := queue.Position(user)
+	if !ok {
+		return Deploy{}, NotInQueueError
+	}
+
+	queue.Items[pos].SetSubject(subject)
+	repo.store.SetQueue(ch.Key(), queue)
+
+	return queue.Items[pos], nil
+}
+
+// HandOver makes user the owner of the running deploy. The user should not have other deploys in the queue.
+func (repo *ChannelDeploys) HandOver(ch Channel, user slack.User) (Deploy, error) {
+	defer lockKey("queue:" + ch.Key())()
+
+	queue := repo.store.GetQueue(ch.Key())
+
+	current, deployInProgress := queue.Current()
+	if !deployInProgress {
+		return current, NoDeployInProgressError
+	}
+
+	if queue.IsUserInQueue(user) {
+		return current, AlreadyInQueueError
+	}
+
+	current.HandOver(user)
+	queue.ReplaceHeadWith(current)
+	repo.store.SetQueue(ch.Key(), queue)
+
+	return current, nil
+}
+
+// SetMessageTS saves the timestamp of the announcement message posted for the running deploy d. It returns false
+// if d is not running anymore.
+func (repo *ChannelDeploys) SetMessageTS(ch Channel, d Deploy, ts string) (Deploy, bool) {
+	defer lockKey("queue:" + ch.Key())()
+
+	queue := repo.store.GetQueue(ch.Key())
+
+	current, deployInProgress := queue.Current()
+	if !deployInProgress || current.User.ID != d.User.ID || !current.StartedAt.Equal(d.StartedAt) {
+		return d, false
+	}
+
+	current.MessageTS = ts
+	queue.ReplaceHeadWith(current)
+	repo.store.SetQueue(ch.Key(), queue)
+
+	return current, true
+}
+
+// Move changes the position of a deploy scheduled by the referenced user and records the change made by user
+// into the deploy audit trail. The queue positions start from 1, the running deploy can not be moved. Only the deploy
+// owner and the user running the current deploy are allowed to move it, otherwise the deploy is returned along with
+// MoveNotAllowedError. Move returns the moved deploy along with its new position.
+func (repo *ChannelDeploys) Move(ch Channel, user slack.User, ref UserReference, pos int) (Deploy, int, error) {
+	defer lockKey("queue:" + ch.Key())()
+
+	queue := repo.store.GetQueue(ch.Key())
+
+	from := -1
+	for i, d := range queue.Items {
+		if i > 0 && ref.Matches(d.User) {
+			from = i
+			break
+		}
+	}
+
+	if from < 0 {
+		return Deploy{}, 0, NotInQueueError
+	}
+
+	u := queue.Items[from].User
+	if user.ID != u.ID && user.ID != queue.Items[0].User.ID {
+		return queue.Items[from], 0, MoveNotAllowedError
+	}
+
+	queue.Move(u, pos)
+
+	to, _ := queue.Position(u)
+	if to == from {
+		return queue.Items[to], to, nil
+	}
+
+	queue.Items[to].QueueChanges = append(queue.Items[to].QueueChanges, QueueChange{
+		User:      user,
+		Action:    QueueChangeMove,
+		From:      from,
+		To:        to,
+		ChangedAt: time.Now().UTC(),
+	})
+	repo.store.SetQueue(ch.Key(), queue)
+
+	return queue.Items[to], to, nil
+}
+
+// EstimateStartTimes returns the estimated start time for each deploy in the queue based on the median duration
+// of recent deploys in channel. The second returned value is false if there is not enough history to make an estimate.
+func (repo *ChannelDeploys) EstimateStartTimes(ch Channel) ([]time.Time, bool) {
+	now := time.Now()
+
+	duration, ok := MedianDuration(repo.store.Since(ch.Key(), now.Add(-estimationPeriod)))
+	if !ok {
+		return nil, false
+	}
+
+	return EstimateStartTimes(repo.All(ch), duration, now), true
+}
+
+// Lock prevents new deploys from being started or queued in all environments of the channel. If the channel
+// is already locked, Lock returns the existing lock along with ChannelLockedError.
+func (repo *ChannelDeploys) Lock(ch Channel, user slack.User, reason string) (Lock, error) {
+	defer lockKey("settings:" + ch.SettingsKey())()
+
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	if settings.Lock != nil {
+		return *settings.Lock, ChannelLockedError
+	}
+
+	settings.Lock = &Lock{
+		User:     user,
+		Reason:   reason,
+		LockedAt: time.Now().UTC(),
+		TeamID:   ch.TeamID,
+	}
+	repo.store.SetSettings(ch.SettingsKey(), settings)
+
+	return *settings.Lock, nil
+}
+
+// Unlock removes the channel lock and returns it. The second returned value is false if the channel
+// has not been locked.
+func (repo *ChannelDeploys) Unlock(ch Channel) (Lock, bool) {
+	defer lockKey("settings:" + ch.SettingsKey())()
+
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	if settings.Lock == nil {
+		return Lock{}, false
+	}
+
+	lock := *settings.Lock
+	settings.Lock = nil
+	repo.store.SetSettings(ch.SettingsKey(), settings)
+
+	return lock, true
+}
+
+// Locked returns current channel lock if there is one.
+func (repo *ChannelDeploys) Locked(ch Channel) (Lock, bool) {
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	if settings.Lock == nil {
+		return Lock{}, false
+	}
+
+	return *settings.Lock, true
+}
+
+// FreezeRules returns the list of deploy freeze rules in channel. Fixed date range rules that are over are not included.
+func (repo *ChannelDeploys) FreezeRules(ch Channel) []FreezeRule {
+	var rules []FreezeRule
+
+	now := time.Now()
+	for _, rule := range repo.store.GetSettings(ch.SettingsKey()).FreezeRules {
+		if !rule.Expired(now) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// AddFreezeRule adds a new deploy freeze rule to all environments of the channel.
+func (repo *ChannelDeploys) AddFreezeRule(ch Channel, rule FreezeRule) {
+	defer lockKey("settings:" + ch.SettingsKey())()
+
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	settings.FreezeRules = append(repo.FreezeRules(ch), rule)
+	repo.store.SetSettings(ch.SettingsKey(), settings)
+}
+
+// RemoveFreezeRule removes the n-th (starting from 1) rule as returned by FreezeRules().
+func (repo *ChannelDeploys) RemoveFreezeRule(ch Channel, n int) (FreezeRule, bool) {
+	defer lockKey("settings:" + ch.SettingsKey())()
+
+	rules := repo.FreezeRules(ch)
+	if n < 1 || n > len(rules) {
+		return FreezeRule{}, false
+	}
+
+	rule := rules[n-1]
+
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	settings.FreezeRules = append(rules[:n-1:n-1], rules[n:]...)
+	repo.store.SetSettings(ch.SettingsKey(), settings)
+
+	return rule, true
+}
+
+// ActiveFreeze returns the first freeze rule that is active at time t.
+func (repo *ChannelDeploys) ActiveFreeze(ch Channel, t time.Time) (FreezeRule, bool) {
+	for _, rule := range repo.store.GetSettings(ch.SettingsKey()).FreezeRules {
+		if rule.Active(t) {
+			return rule, true
+		}
+	}
+
+	return FreezeRule{}, false
+}
+
+// ExpiryPolicy returns the policy for deploys that have been running for too long in channel.
+func (repo *ChannelDeploys) ExpiryPolicy(ch Channel) (ExpiryPolicy, bool) {
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	if settings.Expiry == nil {
+		return ExpiryPolicy{}, false
+	}
+
+	return *settings.Expiry, true
+}
+
+// SetExpiryPolicy sets the expiry policy for all environments of the channel. Passing nil disables the deploy expiry.
+func (repo *ChannelDeploys) SetExpiryPolicy(ch Channel, p *ExpiryPolicy) {
+	defer lockKey("settings:" + ch.SettingsKey())()
+
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	settings.Expiry = p
+	repo.store.SetSettings(ch.SettingsKey(), settings)
+}
+
+// Subscribers returns the users who are notified about deploys in all environments of the channel.
+func (repo *ChannelDeploys) Subscribers(ch Channel) []slack.User {
+	return repo.store.GetSettings(ch.SettingsKey()).Subscribers
+}
+
+// Subscribe adds user to the channel subscribers. It returns false if the user has already been subscribed.
+func (repo *ChannelDeploys) Subscribe(ch Channel, user slack.User) bool {
+	defer lockKey("settings:" + ch.SettingsKey())()
+
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	for _, u := range settings.Subscribers {
+		if u.ID == user.ID {
+			return false
+		}
+	}
+
+	settings.Subscribers = append(settings.Subscribers, user)
+	repo.store.SetSettings(ch.SettingsKey(), settings)
+
+	return true
+}
+
+// Unsubscribe removes user from the channel subscribers. It returns false if the user has not been subscribed.
+func (repo *ChannelDeploys) Unsubscribe(ch Channel, user slack.User) bool {
+	defer lockKey("settings:" + ch.SettingsKey())()
+
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	for i, u := range settings.Subscribers {
+		if u.ID != user.ID {
+			continue
+		}
+
+		settings.Subscribers = append(settings.Subscribers[:i:i], settings.Subscribers[i+1:]...)
+		repo.store.SetSettings(ch.SettingsKey(), settings)
+
+		return true
+	}
+
+	return false
+}
+
+// Locale returns the language of bot messages chosen for all environments of the channel. An empty string
+// is returned if there is none.
+func (repo *ChannelDeploys) Locale(ch Channel) string {
+	return repo.store.GetSettings(ch.SettingsKey()).Locale
+}
+
+// SetLocale sets the language of bot messages in all environments of the channel. Passing an empty string
+// resets it to the workspace default.
+func (repo *ChannelDeploys) SetLocale(ch Channel, locale string) {
+	defer lockKey("settings:" + ch.SettingsKey())()
+
+	settings := repo.store.GetSettings(ch.SettingsKey())
+	settings.Locale = locale
+	repo.store.SetSettings(ch.SettingsKey(), settings)
+}
@@ -1,6 +1,8 @@
 package deploy_test
 
 import (
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -8,6 +10,7 @@ import (
 	"github.com/adjust/michaelbot/slack"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 /*
@@ -28,6 +31,30 @@ func (m *StoreMock) SetQueue(key string, q deploy.Queue) {
 
 func (m *StoreMock) AddToHistory(key string, d deploy.Deploy) {}
 
+func (m *StoreMock) All(key string) []deploy.Deploy {
+	args := m.Called(key)
+	return args.Get(0).([]deploy.Deploy)
+}
+
+func (m *StoreMock) Since(key string, startTime time.Time) []deploy.Deploy {
+	args := m.Called(key, startTime)
+	return args.Get(0).([]deploy.Deploy)
+}
+
+func (m *StoreMock) GetSettings(key string) deploy.ChannelSettings {
+	args := m.Called(key)
+	return args.Get(0).(deploy.ChannelSettings)
+}
+
+func (m *StoreMock) SetSettings(key string, s deploy.ChannelSettings) {
+	m.Called(key, s)
+}
+
+func (m *StoreMock) ChannelKeys() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
 /*
    Tests
 */
@@ -46,11 +73,11 @@ func TestChannelDeploys_Current(t *testing.T) {
 
 	repo := deploy.NewChannelDeploys(store)
 
-	if d, ok := repo.Current("key1"); assert.True(t, ok) {
+	if d, ok := repo.Current(deploy.Channel{ID: "key1"}); assert.True(t, ok) {
 		assert.Equal(t, current, d)
 	}
 
-	_, ok := repo.Current("key2")
+	_, ok := repo.Current(deploy.Channel{ID: "key2"})
 	assert.False(t, ok)
 
 	store.AssertExpectations(t)
@@ -71,14 +98,14 @@ func TestChannelDeploys_Finish(t *testing.T) {
 
 	repo := deploy.NewChannelDeploys(store)
 
-	if d, ok := repo.Finish("key1"); assert.True(t, ok) {
+	if d, ok := repo.Finish(deploy.Channel{ID: "key1"}); assert.True(t, ok) {
 		assert.Equal(t, current.User, d.User)
 		assert.Equal(t, current.Subject, d.Subject)
 		assert.WithinDuration(t, time.Now(), d.FinishedAt, time.Second)
 		assert.False(t, d.Aborted)
 	}
 
-	_, ok := repo.Finish("key2")
+	_, ok := repo.Finish(deploy.Channel{ID: "key2"})
 	assert.False(t, ok)
 }
 
@@ -97,13 +124,437 @@ func TestChannelDeploys_Abort(t *testing.T) {
 
 	repo := deploy.NewChannelDeploys(store)
 
-	if d, ok := repo.Abort("key1", "something went wrong"); assert.True(t, ok) {
+	if d, ok := repo.Abort(deploy.Channel{ID: "key1"}, "something went wrong"); assert.True(t, ok) {
 		assert.Equal(t, current.User, d.User)
 		assert.Equal(t, current.Subject, d.Subject)
 		assert.WithinDuration(t, time.Now(), d.FinishedAt, time.Second)
 		assert.True(t, d.Aborted)
 	}
 
-	_, ok := repo.Abort("key2", "something went wrong")
+	_, ok := repo.Abort(deploy.Channel{ID: "key2"}, "something went wrong")
+	assert.False(t, ok)
+}
+
+func TestChannelDeploys_Start_Environments(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+
+	staging := deploy.Channel{ID: "key1", Environment: "staging"}
+	production := deploy.Channel{ID: "key1", Environment: "production"}
+
+	d1, err := repo.Start(staging, deploy.New(slack.User{ID: "1", Name: "User 1"}, "Staging deploy"))
+	require.NoError(t, err)
+	assert.Equal(t, "staging", d1.Environment)
+
+	d2, err := repo.Start(production, deploy.New(slack.User{ID: "2", Name: "User 2"}, "Production deploy"))
+	require.NoError(t, err)
+	assert.Equal(t, "production", d2.Environment)
+
+	_, ok := repo.Current(deploy.Channel{ID: "key1"})
+	assert.False(t, ok)
+
+	if d, ok := repo.Current(staging); assert.True(t, ok) {
+		assert.Equal(t, d1, d)
+	}
+
+	if d, ok := repo.Finish(production); assert.True(t, ok) {
+		assert.Equal(t, d2.Subject, d.Subject)
+	}
+
+	_, ok = repo.Current(production)
+	assert.False(t, ok)
+
+	_, ok = repo.Current(staging)
+	assert.True(t, ok)
+}
+
+func TestChannelDeploys_Lock(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+
+	staging := deploy.Channel{ID: "key1", Environment: "staging"}
+	owner := slack.User{ID: "1", Name: "User 1"}
+
+	lock, err := repo.Lock(deploy.Channel{ID: "key1"}, owner, "incident")
+	require.NoError(t, err)
+	assert.Equal(t, owner, lock.User)
+	assert.Equal(t, "incident", lock.Reason)
+	assert.WithinDuration(t, time.Now(), lock.LockedAt, time.Second)
+
+	if l, ok := repo.Locked(staging); assert.True(t, ok) {
+		assert.Equal(t, lock, l)
+	}
+
+	l, err := repo.Lock(staging, slack.User{ID: "2", Name: "User 2"}, "release")
+	assert.Equal(t, deploy.ChannelLockedError, err)
+	assert.Equal(t, lock, l)
+
+	_, err = repo.Start(staging, deploy.New(slack.User{ID: "2", Name: "User 2"}, "Staging deploy"))
+	assert.Equal(t, deploy.ChannelLockedError, err)
+
+	_, ok := repo.Current(staging)
+	assert.False(t, ok)
+
+	if l, ok := repo.Unlock(staging); assert.True(t, ok) {
+		assert.Equal(t, lock, l)
+	}
+
+	_, ok = repo.Unlock(staging)
+	assert.False(t, ok)
+
+	_, err = repo.Start(staging, deploy.New(slack.User{ID: "2", Name: "User 2"}, "Staging deploy"))
+	assert.NoError(t, err)
+}
+
+func TestChannelDeploys_FreezeRules(t *testing.T) {
+	store := deploy.NewInMemoryStore()
+	repo := deploy.NewChannelDeploys(store)
+
+	staging := deploy.Channel{ID: "key1", Environment: "staging"}
+	user := slack.User{ID: "1", Name: "User 1"}
+
+	now := time.Now().UTC()
+	activeRule, err := deploy.ParseFreezeRule(now.AddDate(0, 0, -1).Format("2006-01-02") + " - " + now.AddDate(0, 0, 1).Format("2006-01-02"))
+	require.NoError(t, err)
+
+	expiredRule, err := deploy.ParseFreezeRule("2016-12-24 - 2017-01-02")
+	require.NoError(t, err)
+
+	repo.AddFreezeRule(deploy.Channel{ID: "key1"}, expiredRule)
+	assert.Empty(t, repo.FreezeRules(staging))
+
+	repo.AddFreezeRule(deploy.Channel{ID: "key1"}, activeRule)
+	assert.Equal(t, []deploy.FreezeRule{activeRule}, repo.FreezeRules(staging))
+
+	if rule, ok := repo.ActiveFreeze(staging, now); assert.True(t, ok) {
+		assert.Equal(t, activeRule, rule)
+	}
+
+	_, err = repo.Start(staging, deploy.New(user, "Staging deploy"))
+	assert.Equal(t, deploy.DeployFrozenError, err)
+
+	d, err := repo.ForceStart(staging, deploy.New(user, "Hotfix"), "payments are down")
+	require.NoError(t, err)
+
+	if assert.NotNil(t, d.FreezeOverride) {
+		assert.Equal(t, user, d.FreezeOverride.User)
+		assert.Equal(t, activeRule.String(), d.FreezeOverride.Freeze)
+		assert.Equal(t, "payments are down", d.FreezeOverride.Reason)
+	}
+
+	// the override is kept in the deploy history
+	repo.Finish(staging)
+	if history := store.All(staging.Key()); assert.Len(t, history, 1) && assert.NotNil(t, history[0].FreezeOverride) {
+		assert.Equal(t, "payments are down", history[0].FreezeOverride.Reason)
+	}
+
+	_, ok := repo.RemoveFreezeRule(staging, 2)
+	assert.False(t, ok)
+
+	if rule, ok := repo.RemoveFreezeRule(staging, 1); assert.True(t, ok) {
+		assert.Equal(t, activeRule, rule)
+	}
+
+	assert.Empty(t, repo.FreezeRules(staging))
+
+	_, ok = repo.ActiveFreeze(staging, now)
+	assert.False(t, ok)
+}
+
+func TestChannelDeploys_Start_Priority(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+	ch := deploy.Channel{ID: "key1"}
+
+	user1, user2, user3 := slack.User{ID: "1", Name: "user1"}, slack.User{ID: "2", Name: "user2"}, slack.User{ID: "3", Name: "user3"}
+
+	urgent := deploy.New(user1, "Urgent deploy")
+	urgent.Priority = true
+
+	d, err := repo.Start(ch, urgent)
+	require.NoError(t, err)
+	assert.Empty(t, d.QueueChanges)
+
+	_, err = repo.Start(ch, deploy.New(user2, "Regular deploy"))
+	require.Equal(t, deploy.DeployInProgressError, err)
+
+	hotfix := deploy.New(user3, "Hotfix")
+	hotfix.Priority = true
+
+	current, err := repo.Start(ch, hotfix)
+	require.Equal(t, deploy.DeployInProgressError, err)
+	assert.Equal(t, user1, current.User)
+
+	deploys := repo.All(ch)
+	require.Len(t, deploys, 3)
+
+	assert.Equal(t, user3, deploys[1].User)
+	assert.True(t, deploys[1].Priority)
+	if assert.Len(t, deploys[1].QueueChanges, 1) {
+		assert.Equal(t, user3, deploys[1].QueueChanges[0].User)
+		assert.Equal(t, deploy.QueueChangeUrgent, deploys[1].QueueChanges[0].Action)
+		assert.Equal(t, 1, deploys[1].QueueChanges[0].To)
+	}
+
+	assert.Equal(t, user2, deploys[2].User)
+
+	assert.Zero(t, deploys[0].QueuedAt)
+	assert.WithinDuration(t, time.Now(), deploys[1].QueuedAt, time.Second)
+	assert.WithinDuration(t, time.Now(), deploys[2].QueuedAt, time.Second)
+}
+
+func TestChannelDeploys_Move(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+	ch := deploy.Channel{ID: "key1"}
+
+	for _, u := range []slack.User{{ID: "1", Name: "user1"}, {ID: "2", Name: "user2"}, {ID: "3", Name: "user3"}} {
+		repo.Start(ch, deploy.New(u, "Deploy"))
+	}
+
+	// only the deploy owner and the current deployer can move it
+	d, _, err := repo.Move(ch, slack.User{ID: "2", Name: "user2"}, deploy.UserReference{Name: "user3"}, 1)
+	assert.Equal(t, deploy.MoveNotAllowedError, err)
+	assert.Equal(t, "3", d.User.ID)
+
+	mover := slack.User{ID: "1", Name: "user1"}
+
+	d, pos, err := repo.Move(ch, mover, deploy.UserReference{Name: "user3"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "3", d.User.ID)
+	assert.Equal(t, 1, pos)
+
+	deploys := repo.All(ch)
+	require.Len(t, deploys, 3)
+	assert.Equal(t, "3", deploys[1].User.ID)
+	if assert.Len(t, deploys[1].QueueChanges, 1) {
+		assert.Equal(t, mover, deploys[1].QueueChanges[0].User)
+		assert.Equal(t, deploy.QueueChangeMove, deploys[1].QueueChanges[0].Action)
+		assert.Equal(t, 2, deploys[1].QueueChanges[0].From)
+		assert.Equal(t, 1, deploys[1].QueueChanges[0].To)
+	}
+	assert.Equal(t, "2", deploys[2].User.ID)
+
+	_, _, err = repo.Move(ch, mover, deploy.UserReference{ID: "1"}, 2)
+	assert.Equal(t, deploy.NotInQueueError, err, "the running deploy can not be moved")
+
+	_, _, err = repo.Move(ch, mover, deploy.UserReference{ID: "5"}, 1)
+	assert.Equal(t, deploy.NotInQueueError, err)
+
+	d, pos, err = repo.Move(ch, slack.User{ID: "2", Name: "user2"}, deploy.UserReference{ID: "2"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "2", d.User.ID)
+	assert.Equal(t, 1, pos)
+
+	d, pos, err = repo.Move(ch, mover, deploy.UserReference{ID: "3"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pos)
+	assert.Len(t, d.QueueChanges, 2)
+
+	_, ok := repo.Finish(ch)
+	require.True(t, ok)
+
+	if d, ok := repo.Finish(ch); assert.True(t, ok) {
+		assert.Equal(t, "3", d.User.ID)
+		assert.Len(t, d.QueueChanges, 2)
+	}
+}
+
+func TestChannelDeploys_HandOver(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+	ch := deploy.Channel{ID: "key1"}
+
+	user1, user2, user3 := slack.User{ID: "1", Name: "user1"}, slack.User{ID: "2", Name: "user2"}, slack.User{ID: "3", Name: "user3"}
+
+	_, err := repo.HandOver(ch, user2)
+	assert.Equal(t, deploy.NoDeployInProgressError, err)
+
+	started, err := repo.Start(ch, deploy.New(user1, "Deploy"))
+	require.NoError(t, err)
+
+	_, err = repo.Start(ch, deploy.New(user3, "Another deploy"))
+	require.Equal(t, deploy.DeployInProgressError, err)
+
+	_, err = repo.HandOver(ch, user3)
+	assert.Equal(t, deploy.AlreadyInQueueError, err)
+
+	d, err := repo.HandOver(ch, user2)
+	require.NoError(t, err)
+	assert.Equal(t, user2, d.User)
+	assert.Equal(t, []slack.User{user1}, d.PreviousOwners)
+	assert.Equal(t, started.StartedAt, d.StartedAt)
+
+	if current, ok := repo.Current(ch); assert.True(t, ok) {
+		assert.Equal(t, d, current)
+	}
+}
+
+func TestChannelDeploys_SetMessageTS(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+	ch := deploy.Channel{ID: "key1"}
+
+	user1, user2 := slack.User{ID: "1", Name: "user1"}, slack.User{ID: "2", Name: "user2"}
+
+	d1, err := repo.Start(ch, deploy.New(user1, "Deploy"))
+	require.NoError(t, err)
+
+	repo.Start(ch, deploy.New(user2, "Another deploy"))
+
+	d, ok := repo.SetMessageTS(ch, d1, "1503435956.000247")
+	require.True(t, ok)
+	assert.Equal(t, "1503435956.000247", d.MessageTS)
+
+	finished, _ := repo.Finish(ch)
+	assert.Equal(t, "1503435956.000247", finished.MessageTS)
+
+	// the deploy is over
+	_, ok = repo.SetMessageTS(ch, d1, "1503435956.000248")
+	assert.False(t, ok)
+
+	if current, ok := repo.Current(ch); assert.True(t, ok) {
+		assert.Empty(t, current.MessageTS)
+	}
+}
+
+// slowQueueStore delays reading queues to let the concurrent updates interleave.
+type slowQueueStore struct {
+	deploy.Store
+}
+
+func (s slowQueueStore) GetQueue(key string) deploy.Queue {
+	q := s.Store.GetQueue(key)
+	time.Sleep(time.Millisecond)
+
+	return q
+}
+
+func TestChannelDeploys_ConcurrentUpdates(t *testing.T) {
+	store := slowQueueStore{deploy.NewInMemoryStore()}
+	ch := deploy.Channel{ID: "key1"}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			user := slack.User{ID: strconv.Itoa(i), Name: "user" + strconv.Itoa(i)}
+			repo := deploy.NewChannelDeploys(store)
+
+			d, err := repo.Start(ch, deploy.New(user, "Deploy"))
+			if err == nil {
+				repo.SetMessageTS(ch, d, "1503435956.000247")
+			}
+			repo.Edit(ch, user, "Deploy #"+strconv.Itoa(i))
+		}(i)
+	}
+	wg.Wait()
+
+	deploys := deploy.NewChannelDeploys(store).All(ch)
+	require.Len(t, deploys, 50)
+
+	assert.Equal(t, "1503435956.000247", deploys[0].MessageTS)
+	for _, d := range deploys {
+		assert.Equal(t, "Deploy #"+d.User.ID, d.Subject)
+	}
+}
+
+func TestChannelDeploys_Edit(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+	ch := deploy.Channel{ID: "key1"}
+
+	user1, user2 := slack.User{ID: "1", Name: "user1"}, slack.User{ID: "2", Name: "user2"}
+
+	_, err := repo.Edit(ch, user1, "Updated subject")
+	assert.Equal(t, deploy.NotInQueueError, err)
+
+	repo.Start(ch, deploy.New(user1, "Deploy a/b#1"))
+	repo.Start(ch, deploy.New(user2, "Another deploy"))
+
+	d, err := repo.Edit(ch, user2, "Another deploy x/y#2")
+	require.NoError(t, err)
+	assert.Equal(t, "Another deploy x/y#2", d.Subject)
+	assert.Equal(t, []deploy.PullRequestReference{{ID: "2", Repository: "x/y"}}, d.PullRequests)
+
+	d, err = repo.Edit(ch, user1, "Deploy a/b#1 and a/b#3")
+	require.NoError(t, err)
+	assert.Len(t, d.PullRequests, 2)
+
+	deploys := repo.All(ch)
+	require.Len(t, deploys, 2)
+	assert.Equal(t, "Deploy a/b#1 and a/b#3", deploys[0].Subject)
+	assert.False(t, deploys[0].StartedAt.IsZero())
+	assert.Equal(t, "Another deploy x/y#2", deploys[1].Subject)
+}
+
+func TestChannelDeploys_ExpiryPolicy(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+	ch := deploy.Channel{ID: "key1", Environment: "staging"}
+
+	_, ok := repo.ExpiryPolicy(ch)
+	assert.False(t, ok)
+
+	policy := deploy.ExpiryPolicy{Warn: time.Hour, Abort: 4 * time.Hour}
+	repo.SetExpiryPolicy(deploy.Channel{ID: "key1"}, &policy)
+
+	if p, ok := repo.ExpiryPolicy(ch); assert.True(t, ok) {
+		assert.Equal(t, policy, p)
+	}
+
+	repo.SetExpiryPolicy(ch, nil)
+	_, ok = repo.ExpiryPolicy(deploy.Channel{ID: "key1"})
+	assert.False(t, ok)
+}
+
+func TestChannelDeploys_EstimateStartTimes(t *testing.T) {
+	store := deploy.NewInMemoryStore()
+	repo := deploy.NewChannelDeploys(store)
+	ch := deploy.Channel{ID: "key1"}
+
+	_, ok := repo.EstimateStartTimes(ch)
 	assert.False(t, ok)
+
+	now := time.Now()
+	store.AddToHistory(ch.Key(), deploy.Deploy{StartedAt: now.Add(-2 * time.Hour), FinishedAt: now.Add(-2*time.Hour + 10*time.Minute)})
+
+	repo.Start(ch, deploy.New(slack.User{ID: "1", Name: "user1"}, "Deploy 1"))
+	repo.Start(ch, deploy.New(slack.User{ID: "2", Name: "user2"}, "Deploy 2"))
+
+	if estimates, ok := repo.EstimateStartTimes(ch); assert.True(t, ok) && assert.Len(t, estimates, 2) {
+		deploys := repo.All(ch)
+		assert.Equal(t, deploys[0].StartedAt, estimates[0])
+		assert.WithinDuration(t, deploys[0].StartedAt.Add(10*time.Minute), estimates[1], time.Second)
+	}
+}
+
+func TestChannelDeploys_Subscribe(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+
+	staging := deploy.Channel{ID: "key1", Environment: "staging"}
+	user1, user2 := slack.User{ID: "1", Name: "User 1"}, slack.User{ID: "2", Name: "User 2"}
+
+	assert.Empty(t, repo.Subscribers(staging))
+
+	assert.True(t, repo.Subscribe(deploy.Channel{ID: "key1"}, user1))
+	assert.True(t, repo.Subscribe(staging, user2))
+	assert.False(t, repo.Subscribe(staging, slack.User{ID: "1", Name: "Renamed"}))
+
+	assert.Equal(t, []slack.User{user1, user2}, repo.Subscribers(deploy.Channel{ID: "key1", Environment: "production"}))
+	assert.Empty(t, repo.Subscribers(deploy.Channel{ID: "key2"}))
+
+	assert.True(t, repo.Unsubscribe(staging, user1))
+	assert.False(t, repo.Unsubscribe(staging, user1))
+
+	assert.Equal(t, []slack.User{user2}, repo.Subscribers(staging))
+}
+
+func TestChannelDeploys_Locale(t *testing.T) {
+	repo := deploy.NewChannelDeploys(deploy.NewInMemoryStore())
+
+	staging := deploy.Channel{ID: "key1", Environment: "staging"}
+
+	assert.Empty(t, repo.Locale(staging))
+
+	repo.SetLocale(deploy.Channel{ID: "key1"}, "de")
+	assert.Equal(t, "de", repo.Locale(staging))
+	assert.Empty(t, repo.Locale(deploy.Channel{ID: "key2"}))
+
+	repo.SetLocale(staging, "")
+	assert.Empty(t, repo.Locale(deploy.Channel{ID: "key1"}))
 }
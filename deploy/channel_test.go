@@ -0,0 +1,27 @@
+package deploy_test
+
+import (
+	"testing"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannel_Key(t *testing.T) {
+	assert.Equal(t, "C1", deploy.Channel{ID: "C1"}.Key())
+	assert.Equal(t, "C1/staging", deploy.Channel{ID: "C1", Environment: "staging"}.Key())
+	assert.Equal(t, "T1-C1", deploy.Channel{TeamID: "T1", ID: "C1"}.Key())
+	assert.Equal(t, "T1-C1/staging", deploy.Channel{TeamID: "T1", ID: "C1", Environment: "staging"}.Key())
+}
+
+func TestChannel_SettingsKey(t *testing.T) {
+	assert.Equal(t, "C1", deploy.Channel{ID: "C1", Environment: "staging"}.SettingsKey())
+	assert.Equal(t, "T1-C1", deploy.Channel{TeamID: "T1", ID: "C1", Environment: "staging"}.SettingsKey())
+}
+
+func TestParseChannelKey(t *testing.T) {
+	assert.Equal(t, deploy.Channel{ID: "C1"}, deploy.ParseChannelKey("C1"))
+	assert.Equal(t, deploy.Channel{ID: "C1", Environment: "staging"}, deploy.ParseChannelKey("C1/staging"))
+	assert.Equal(t, deploy.Channel{TeamID: "T1", ID: "C1"}, deploy.ParseChannelKey("T1-C1"))
+	assert.Equal(t, deploy.Channel{TeamID: "T1", ID: "C1", Environment: "pre-prod"}, deploy.ParseChannelKey("T1-C1/pre-prod"))
+}
@@ -18,6 +18,59 @@ func (q *Queue) Add(d Deploy) {
 	q.Items = append(q.Items, d)
 }
 
+// AddUrgent inserts a deploy right after the running one and other urgent deploys that are already
+// waiting in the queue. It returns the position of the inserted deploy in the queue.
+func (q *Queue) AddUrgent(d Deploy) int {
+	pos := 0
+	if len(q.Items) > 0 {
+		pos = 1
+	}
+
+	for pos < len(q.Items) && q.Items[pos].Priority {
+		pos++
+	}
+
+	q.Items = append(q.Items, Deploy{})
+	copy(q.Items[pos+1:], q.Items[pos:])
+	q.Items[pos] = d
+
+	return pos
+}
+
+// Position returns the position of user deploy in the queue. Position 0 is the running deploy.
+func (q *Queue) Position(u slack.User) (int, bool) {
+	for i, d := range q.Items {
+		if d.User.ID == u.ID {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// Move changes the position of a deploy scheduled by user u. Positions start from 1, and the running deploy
+// can neither be moved nor replaced. Positions past the end of the queue move the deploy to the end.
+func (q *Queue) Move(u slack.User, pos int) bool {
+	from, ok := q.Position(u)
+	if !ok || from == 0 || pos < 1 {
+		return false
+	}
+
+	if pos > len(q.Items)-1 {
+		pos = len(q.Items) - 1
+	}
+
+	d := q.Items[from]
+	if from < pos {
+		copy(q.Items[from:pos], q.Items[from+1:pos+1])
+	} else {
+		copy(q.Items[pos+1:from+1], q.Items[pos:from])
+	}
+	q.Items[pos] = d
+
+	return true
+}
+
 func (q *Queue) Current() (d Deploy, ok bool) {
 	if len(q.Items) > 0 {
 		return q.Items[0], true
@@ -72,23 +125,56 @@ func (q *Queue) ReplaceHeadWith(d Deploy) {
 }
 
 type Deploy struct {
-	User         slack.User
-	Subject      string
-	StartedAt    time.Time
-	FinishedAt   time.Time
-	Aborted      bool
-	AbortReason  string
-	PullRequests []PullRequestReference
-	Subscribers  []UserReference
+	User           slack.User
+	Subject        string
+	Environment    string
+	QueuedAt       time.Time `json:",omitempty"`
+	StartedAt      time.Time
+	FinishedAt     time.Time
+	Aborted        bool
+	AbortReason    string
+	PullRequests   []PullRequestReference
+	Subscribers    []UserReference
+	FreezeOverride *FreezeOverride `json:",omitempty"`
+	Priority       bool            `json:",omitempty"`
+	QueueChanges   []QueueChange   `json:",omitempty"`
+	PreviousOwners []slack.User    `json:",omitempty"`
+	// ExpectedDuration is an estimate of how long the deploy takes provided by user
+	ExpectedDuration time.Duration `json:",omitempty"`
+	// MessageTS is the timestamp of the deploy announcement message, later events are posted in its thread
+	MessageTS string `json:",omitempty"`
+	// TeamID is the Slack workspace the deploy has been started in, it is only set if the app has been installed
+	// into multiple workspaces
+	TeamID string `json:",omitempty"`
+}
+
+const (
+	QueueChangeUrgent = "urgent"
+	QueueChangeMove   = "move"
+)
+
+// QueueChange is an audit trail record about the deploy position being changed by a user while it was waiting
+// in the queue. Positions start from 1, From is 0 for deploys that have been added as urgent.
+type QueueChange struct {
+	User      slack.User
+	Action    string
+	From      int
+	To        int
+	ChangedAt time.Time
 }
 
 func New(user slack.User, subject string) Deploy {
-	return Deploy{
-		User:         user,
-		Subject:      subject,
-		PullRequests: FindPullRequestReferences(subject),
-		Subscribers:  FindUserReferences(subject),
-	}
+	d := Deploy{User: user}
+	d.SetSubject(subject)
+
+	return d
+}
+
+// SetSubject updates the deploy subject along with pull requests and users referenced in it.
+func (d *Deploy) SetSubject(subject string) {
+	d.Subject = subject
+	d.PullRequests = FindPullRequestReferences(subject)
+	d.Subscribers = FindUserReferences(subject)
 }
 
 func (d Deploy) Finished() bool {
@@ -121,6 +207,13 @@ func (d *Deploy) Abort(reason string) {
 	d.Aborted, d.AbortReason = true, reason
 }
 
+// HandOver makes u the owner of deploy keeping its start time. The current owner is added to the list
+// of previous owners.
+func (d *Deploy) HandOver(u slack.User) {
+	d.PreviousOwners = append(d.PreviousOwners, d.User)
+	d.User = u
+}
+
 func (d1 Deploy) Equal(d2 Deploy) bool {
 	return d1.User == d2.User &&
 		d1.Subject == d2.Subject &&
@@ -31,6 +31,19 @@ func TestNewDeploy(t *testing.T) {
 	}
 }
 
+func TestDeploy_SetSubject(t *testing.T) {
+	d := deploy.New(slack.User{ID: "1", Name: "Test User"}, "Test deploy a/b#1 for @user1")
+
+	d.SetSubject("Test deploy x/y#4 for @user2")
+	assert.Equal(t, "Test deploy x/y#4 for @user2", d.Subject)
+	assert.Equal(t, []deploy.PullRequestReference{{ID: "4", Repository: "x/y"}}, d.PullRequests)
+	assert.Equal(t, []deploy.UserReference{{Name: "user2"}}, d.Subscribers)
+
+	d.SetSubject("Test deploy")
+	assert.Empty(t, d.PullRequests)
+	assert.Empty(t, d.Subscribers)
+}
+
 func TestDeploy_Start(t *testing.T) {
 	d := deploy.New(slack.User{ID: "1", Name: "Test User"}, "Test deploy")
 	assert.True(t, d.Start())
@@ -95,3 +108,67 @@ func TestDeploy_Equal(t *testing.T) {
 	d2.FinishedAt = d1.FinishedAt
 	assert.True(t, d1.Equal(d2))
 }
+
+func TestQueue_AddUrgent(t *testing.T) {
+	queue := deploy.NewEmptyQueue()
+
+	assert.Equal(t, 0, queue.AddUrgent(deploy.Deploy{User: slack.User{ID: "1"}}))
+	queue.Add(deploy.Deploy{User: slack.User{ID: "2"}})
+
+	urgent1 := deploy.Deploy{User: slack.User{ID: "3"}, Priority: true}
+	assert.Equal(t, 1, queue.AddUrgent(urgent1))
+
+	urgent2 := deploy.Deploy{User: slack.User{ID: "4"}, Priority: true}
+	assert.Equal(t, 2, queue.AddUrgent(urgent2))
+
+	var ids []string
+	for _, d := range queue.Items {
+		ids = append(ids, d.User.ID)
+	}
+	assert.Equal(t, []string{"1", "3", "4", "2"}, ids)
+}
+
+func TestQueue_Move(t *testing.T) {
+	queue := deploy.NewEmptyQueue()
+	for _, id := range []string{"1", "2", "3", "4"} {
+		queue.Add(deploy.Deploy{User: slack.User{ID: id}})
+	}
+
+	userIDs := func() []string {
+		var ids []string
+		for _, d := range queue.Items {
+			ids = append(ids, d.User.ID)
+		}
+
+		return ids
+	}
+
+	assert.True(t, queue.Move(slack.User{ID: "4"}, 1))
+	assert.Equal(t, []string{"1", "4", "2", "3"}, userIDs())
+
+	assert.True(t, queue.Move(slack.User{ID: "4"}, 10))
+	assert.Equal(t, []string{"1", "2", "3", "4"}, userIDs())
+
+	assert.True(t, queue.Move(slack.User{ID: "2"}, 2))
+	assert.Equal(t, []string{"1", "3", "2", "4"}, userIDs())
+
+	assert.False(t, queue.Move(slack.User{ID: "1"}, 2), "the running deploy can not be moved")
+	assert.False(t, queue.Move(slack.User{ID: "3"}, 0), "the running deploy can not be replaced")
+	assert.False(t, queue.Move(slack.User{ID: "5"}, 1))
+	assert.Equal(t, []string{"1", "3", "2", "4"}, userIDs())
+}
+
+func TestDeploy_HandOver(t *testing.T) {
+	owner1, owner2, owner3 := slack.User{ID: "1", Name: "user1"}, slack.User{ID: "2", Name: "user2"}, slack.User{ID: "3", Name: "user3"}
+
+	d := deploy.New(owner1, "Test deploy")
+	d.Start()
+	startedAt := d.StartedAt
+
+	d.HandOver(owner2)
+	d.HandOver(owner3)
+
+	assert.Equal(t, owner3, d.User)
+	assert.Equal(t, []slack.User{owner1, owner2}, d.PreviousOwners)
+	assert.Equal(t, startedAt, d.StartedAt)
+}
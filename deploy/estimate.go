@@ -0,0 +1,56 @@
+package deploy
+
+import (
+	"sort"
+	"time"
+)
+
+// estimationPeriod is the period of time in channel history used to estimate deploy duration
+const estimationPeriod = 30 * 24 * time.Hour
+
+// MedianDuration returns the median duration of finished deploys. Aborted deploys are not taken into account,
+// since they usually take less time than the successful ones.
+func MedianDuration(deploys []Deploy) (time.Duration, bool) {
+	var durations []time.Duration
+	for _, d := range deploys {
+		if d.Finished() && !d.Aborted && !d.StartedAt.IsZero() {
+			durations = append(durations, d.FinishedAt.Sub(d.StartedAt))
+		}
+	}
+
+	if len(durations) == 0 {
+		return 0, false
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	n := len(durations)
+	if n%2 == 0 {
+		return (durations[n/2-1] + durations[n/2]) / 2, true
+	}
+
+	return durations[n/2], true
+}
+
+// EstimateStartTimes returns the estimated start time for each deploy in queue assuming that every deploy takes
+// duration time. The running deploy that already takes longer is expected to be finished at any moment.
+func EstimateStartTimes(queue []Deploy, duration time.Duration, now time.Time) []time.Time {
+	estimates := make([]time.Time, len(queue))
+	if len(queue) == 0 {
+		return estimates
+	}
+
+	estimates[0] = queue[0].StartedAt
+
+	next := queue[0].StartedAt.Add(duration)
+	if next.Before(now) {
+		next = now
+	}
+
+	for i := 1; i < len(queue); i++ {
+		estimates[i] = next
+		next = next.Add(duration)
+	}
+
+	return estimates
+}
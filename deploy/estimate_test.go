@@ -0,0 +1,54 @@
+package deploy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMedianDuration(t *testing.T) {
+	now := time.Now()
+
+	history := []deploy.Deploy{
+		{StartedAt: now.Add(-5 * time.Hour), FinishedAt: now.Add(-5*time.Hour + 10*time.Minute)},
+		{StartedAt: now.Add(-4 * time.Hour), FinishedAt: now.Add(-4*time.Hour + 30*time.Minute)},
+		{StartedAt: now.Add(-3 * time.Hour), FinishedAt: now.Add(-3*time.Hour + time.Minute), Aborted: true},
+		{StartedAt: now.Add(-2 * time.Hour), FinishedAt: now.Add(-2*time.Hour + 20*time.Minute)},
+		{StartedAt: now.Add(-time.Hour)},
+	}
+
+	if d, ok := deploy.MedianDuration(history); assert.True(t, ok) {
+		assert.Equal(t, 20*time.Minute, d)
+	}
+
+	history = append(history, deploy.Deploy{StartedAt: now.Add(-10 * time.Minute), FinishedAt: now})
+	if d, ok := deploy.MedianDuration(history); assert.True(t, ok) {
+		assert.Equal(t, 15*time.Minute, d)
+	}
+
+	_, ok := deploy.MedianDuration(history[2:3])
+	assert.False(t, ok)
+}
+
+func TestEstimateStartTimes(t *testing.T) {
+	now := time.Now()
+
+	queue := []deploy.Deploy{{StartedAt: now.Add(-5 * time.Minute)}, {}, {}}
+	assert.Equal(t, []time.Time{
+		queue[0].StartedAt,
+		now.Add(15 * time.Minute),
+		now.Add(35 * time.Minute),
+	}, deploy.EstimateStartTimes(queue, 20*time.Minute, now))
+
+	// the running deploy takes longer than usual
+	queue[0].StartedAt = now.Add(-time.Hour)
+	assert.Equal(t, []time.Time{
+		queue[0].StartedAt,
+		now,
+		now.Add(20 * time.Minute),
+	}, deploy.EstimateStartTimes(queue, 20*time.Minute, now))
+
+	assert.Empty(t, deploy.EstimateStartTimes(nil, 20*time.Minute, now))
+}
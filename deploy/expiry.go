@@ -0,0 +1,80 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ExpiryPolicy defines what happens to deploys that have been running for too long. Each step is measured
+// since the deploy start, zero duration disables the step.
+type ExpiryPolicy struct {
+	// Warn is the time after which the deploy owner gets a direct message
+	Warn time.Duration
+	// Announce is the time after which the bot posts a reminder into the channel
+	Announce time.Duration
+	// Abort is the time after which the deploy is aborted with "expired" reason
+	Abort time.Duration
+}
+
+// ParseExpiryPolicy parses the expiry policy definition in form of "<warn> <announce> <abort>", i.e. "2h 4h 8h".
+// Use "-" or "0" to skip a step.
+func ParseExpiryPolicy(s string) (ExpiryPolicy, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return ExpiryPolicy{}, fmt.Errorf("expiry policy should look like `2h 4h 8h`")
+	}
+
+	var steps [3]time.Duration
+	for i, f := range fields {
+		if f == "-" {
+			continue
+		}
+
+		d, err := time.ParseDuration(f)
+		if err != nil || d < 0 {
+			return ExpiryPolicy{}, fmt.Errorf("malformed duration %q, expected something like 30m or 2h", f)
+		}
+
+		steps[i] = d
+	}
+
+	p := ExpiryPolicy{Warn: steps[0], Announce: steps[1], Abort: steps[2]}
+	if p == (ExpiryPolicy{}) {
+		return p, fmt.Errorf("expiry policy should have at least one step")
+	}
+
+	return p, nil
+}
+
+func (p ExpiryPolicy) String() string {
+	var steps []string
+
+	if p.Warn > 0 {
+		steps = append(steps, "warn the owner after "+FormatDuration(p.Warn))
+	}
+
+	if p.Announce > 0 {
+		steps = append(steps, "post in channel after "+FormatDuration(p.Announce))
+	}
+
+	if p.Abort > 0 {
+		steps = append(steps, "abort after "+FormatDuration(p.Abort))
+	}
+
+	return strings.Join(steps, ", ")
+}
+
+// formatDuration formats d as time.Duration does, omitting zero minutes and seconds, i.e. 2h instead of 2h0m0s.
+func FormatDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+
+	return s
+}
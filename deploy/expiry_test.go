@@ -0,0 +1,39 @@
+package deploy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpiryPolicy(t *testing.T) {
+	examples := map[string]deploy.ExpiryPolicy{
+		"2h 4h 8h":     {Warn: 2 * time.Hour, Announce: 4 * time.Hour, Abort: 8 * time.Hour},
+		"90m - 24h":    {Warn: 90 * time.Minute, Abort: 24 * time.Hour},
+		"0 1h30m  0s ": {Announce: 90 * time.Minute},
+	}
+
+	for s, expected := range examples {
+		p, err := deploy.ParseExpiryPolicy(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, p, s)
+	}
+}
+
+func TestParseExpiryPolicy_Malformed(t *testing.T) {
+	for _, s := range []string{"", "2h 4h", "2h 4h 8h 16h", "2h 4h tomorrow", "- - -", "0 0 0", "-1h 2h 4h"} {
+		_, err := deploy.ParseExpiryPolicy(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestExpiryPolicy_String(t *testing.T) {
+	p := deploy.ExpiryPolicy{Warn: 90 * time.Minute, Announce: 4 * time.Hour, Abort: 24*time.Hour + 30*time.Second}
+	assert.Equal(t, "warn the owner after 1h30m, post in channel after 4h, abort after 24h0m30s", p.String())
+
+	p = deploy.ExpiryPolicy{Abort: 45 * time.Minute}
+	assert.Equal(t, "abort after 45m", p.String())
+}
@@ -0,0 +1,229 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adjust/michaelbot/slack"
+)
+
+const (
+	freezeDateFormat = "2006-01-02"
+	freezeTimeFormat = "15:04"
+	week             = 7 * 24 * time.Hour
+)
+
+// FreezeRule describes a period of time when no deploys are allowed in a channel. A rule either repeats
+// every week, i.e. from Friday 15:00 until Monday 08:00, or covers a fixed range of dates.
+type FreezeRule struct {
+	Weekly *WeeklyFreeze `json:",omitempty"`
+	// Since and Until limit the fixed date range rules, Until is not included into the range.
+	Since    time.Time
+	Until    time.Time
+	Location string
+}
+
+// WeeklyFreeze is a recurring freeze window. Start and End are offsets since the beginning of the week (Sunday 00:00)
+// in the rule location.
+type WeeklyFreeze struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// FreezeOverride records who has started a deploy despite of an active deploy freeze and why.
+type FreezeOverride struct {
+	User   slack.User
+	Freeze string
+	Reason string `json:",omitempty"`
+}
+
+// ParseFreezeRule parses freeze rule definition. Both recurring weekly rules, i.e. "Fri 15:00 - Mon 08:00 Europe/Berlin",
+// and fixed date ranges, i.e. "2016-12-24 - 2017-01-02 Europe/Berlin", are supported. The time zone is optional and defaults to UTC.
+func ParseFreezeRule(s string) (FreezeRule, error) {
+	var fields []string
+	for _, f := range strings.Fields(s) {
+		if f != "-" && f != "–" && f != "to" {
+			fields = append(fields, f)
+		}
+	}
+
+	if len(fields) == 0 {
+		return FreezeRule{}, fmt.Errorf("empty freeze rule")
+	}
+
+	if _, ok := parseWeekday(fields[0]); ok {
+		return parseWeeklyFreezeRule(fields)
+	}
+
+	return parseDateRangeFreezeRule(fields)
+}
+
+func parseWeeklyFreezeRule(fields []string) (FreezeRule, error) {
+	if len(fields) != 4 && len(fields) != 5 {
+		return FreezeRule{}, fmt.Errorf("weekly freeze rule should look like `Fri 15:00 - Mon 08:00 Europe/Berlin`")
+	}
+
+	loc, err := parseFreezeLocation(fields[4:])
+	if err != nil {
+		return FreezeRule{}, err
+	}
+
+	start, err := parseWeekOffset(fields[0], fields[1])
+	if err != nil {
+		return FreezeRule{}, err
+	}
+
+	end, err := parseWeekOffset(fields[2], fields[3])
+	if err != nil {
+		return FreezeRule{}, err
+	}
+
+	if start == end {
+		return FreezeRule{}, fmt.Errorf("weekly freeze rule should not start and end at the same time")
+	}
+
+	return FreezeRule{
+		Weekly:   &WeeklyFreeze{Start: start, End: end},
+		Location: loc.String(),
+	}, nil
+}
+
+func parseDateRangeFreezeRule(fields []string) (FreezeRule, error) {
+	if len(fields) > 3 {
+		return FreezeRule{}, fmt.Errorf("freeze date range should look like `2016-12-24 - 2017-01-02 Europe/Berlin`")
+	}
+
+	dates := fields
+	if len(fields) > 1 {
+		if _, err := time.Parse(freezeDateFormat, fields[len(fields)-1]); err != nil {
+			dates = fields[:len(fields)-1]
+		}
+	}
+
+	loc, err := parseFreezeLocation(fields[len(dates):])
+	if err != nil {
+		return FreezeRule{}, err
+	}
+
+	since, err := time.ParseInLocation(freezeDateFormat, dates[0], loc)
+	if err != nil {
+		return FreezeRule{}, fmt.Errorf("malformed date %q, expected YYYY-MM-DD", dates[0])
+	}
+
+	until := since
+	if len(dates) > 1 {
+		if until, err = time.ParseInLocation(freezeDateFormat, dates[1], loc); err != nil {
+			return FreezeRule{}, fmt.Errorf("malformed date %q, expected YYYY-MM-DD", dates[1])
+		}
+	}
+
+	if until.Before(since) {
+		return FreezeRule{}, fmt.Errorf("freeze should not end before it starts")
+	}
+
+	return FreezeRule{
+		Since:    since.UTC(),
+		Until:    until.AddDate(0, 0, 1).UTC(),
+		Location: loc.String(),
+	}, nil
+}
+
+// Active returns true if deploys are frozen at time t.
+func (r FreezeRule) Active(t time.Time) bool {
+	if r.Weekly == nil {
+		return !t.Before(r.Since) && t.Before(r.Until)
+	}
+
+	t = t.In(r.location())
+	offset := time.Duration(t.Weekday())*24*time.Hour +
+		time.Duration(t.Hour())*time.Hour +
+		time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+
+	if r.Weekly.Start < r.Weekly.End {
+		return offset >= r.Weekly.Start && offset < r.Weekly.End
+	}
+
+	// the window spans over the end of the week, i.e. Fri - Mon
+	return offset >= r.Weekly.Start || offset < r.Weekly.End
+}
+
+// Expired returns true for fixed date range rules that are over by the time t.
+func (r FreezeRule) Expired(t time.Time) bool {
+	return r.Weekly == nil && !t.Before(r.Until)
+}
+
+func (r FreezeRule) String() string {
+	loc := r.location()
+
+	if r.Weekly != nil {
+		return formatWeekOffset(r.Weekly.Start) + " – " + formatWeekOffset(r.Weekly.End) + " " + loc.String()
+	}
+
+	since, until := r.Since.In(loc), r.Until.In(loc).AddDate(0, 0, -1)
+	if since.Equal(until) {
+		return since.Format(freezeDateFormat) + " " + loc.String()
+	}
+
+	return since.Format(freezeDateFormat) + " – " + until.Format(freezeDateFormat) + " " + loc.String()
+}
+
+func (r FreezeRule) location() *time.Location {
+	loc, err := time.LoadLocation(r.Location)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+func parseFreezeLocation(fields []string) (*time.Location, error) {
+	if len(fields) == 0 {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", fields[0])
+	}
+
+	return loc, nil
+}
+
+func parseWeekOffset(day, clock string) (time.Duration, error) {
+	weekday, ok := parseWeekday(day)
+	if !ok {
+		return 0, fmt.Errorf("malformed weekday %q, expected Mon, Tue, etc.", day)
+	}
+
+	t, err := time.Parse(freezeTimeFormat, clock)
+	if err != nil {
+		return 0, fmt.Errorf("malformed time %q, expected HH:MM", clock)
+	}
+
+	return time.Duration(weekday)*24*time.Hour + time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func formatWeekOffset(offset time.Duration) string {
+	offset %= week
+	weekday := time.Weekday(offset / (24 * time.Hour))
+	offset %= 24 * time.Hour
+
+	return fmt.Sprintf("%s %02d:%02d", weekday.String()[:3], offset/time.Hour, offset%time.Hour/time.Minute)
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	if len(s) < 3 {
+		return 0, false
+	}
+
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.HasPrefix(strings.ToLower(d.String()), s) {
+			return d, true
+		}
+	}
+
+	return 0, false
+}
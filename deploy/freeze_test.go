@@ -0,0 +1,91 @@
+package deploy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFreezeRule_Weekly(t *testing.T) {
+	rule, err := deploy.ParseFreezeRule("Fri 15:00 - Mon 08:00 Europe/Berlin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Fri 15:00 – Mon 08:00 Europe/Berlin", rule.String())
+
+	loc, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	examples := map[string]bool{
+		"2016-08-05 14:59": false, // Friday
+		"2016-08-05 15:00": true,
+		"2016-08-07 12:00": true, // Sunday
+		"2016-08-08 07:59": true, // Monday
+		"2016-08-08 08:00": false,
+		"2016-08-10 12:00": false, // Wednesday
+	}
+
+	for s, active := range examples {
+		ts, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		require.NoError(t, err)
+
+		assert.Equal(t, active, rule.Active(ts.UTC()), s)
+		assert.False(t, rule.Expired(ts))
+	}
+}
+
+func TestParseFreezeRule_WeeklyWithinWeek(t *testing.T) {
+	rule, err := deploy.ParseFreezeRule("tuesday 10:00 to thursday 18:30")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Tue 10:00 – Thu 18:30 UTC", rule.String())
+
+	assert.False(t, rule.Active(time.Date(2016, time.August, 9, 9, 59, 0, 0, time.UTC)))
+	assert.True(t, rule.Active(time.Date(2016, time.August, 9, 10, 0, 0, 0, time.UTC)))
+	assert.True(t, rule.Active(time.Date(2016, time.August, 11, 18, 29, 0, 0, time.UTC)))
+	assert.False(t, rule.Active(time.Date(2016, time.August, 11, 18, 30, 0, 0, time.UTC)))
+}
+
+func TestParseFreezeRule_DateRange(t *testing.T) {
+	rule, err := deploy.ParseFreezeRule("2016-12-24 - 2017-01-02 Europe/Berlin")
+	require.NoError(t, err)
+
+	assert.Equal(t, "2016-12-24 – 2017-01-02 Europe/Berlin", rule.String())
+
+	assert.False(t, rule.Active(time.Date(2016, time.December, 23, 22, 59, 0, 0, time.UTC)))
+	assert.True(t, rule.Active(time.Date(2016, time.December, 23, 23, 0, 0, 0, time.UTC)))
+	assert.True(t, rule.Active(time.Date(2017, time.January, 2, 22, 59, 0, 0, time.UTC)))
+	assert.False(t, rule.Active(time.Date(2017, time.January, 2, 23, 0, 0, 0, time.UTC)))
+
+	assert.False(t, rule.Expired(time.Date(2017, time.January, 2, 22, 59, 0, 0, time.UTC)))
+	assert.True(t, rule.Expired(time.Date(2017, time.January, 2, 23, 0, 0, 0, time.UTC)))
+}
+
+func TestParseFreezeRule_SingleDate(t *testing.T) {
+	rule, err := deploy.ParseFreezeRule("2016-12-31")
+	require.NoError(t, err)
+
+	assert.Equal(t, "2016-12-31 UTC", rule.String())
+	assert.True(t, rule.Active(time.Date(2016, time.December, 31, 23, 59, 0, 0, time.UTC)))
+	assert.False(t, rule.Active(time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestParseFreezeRule_Malformed(t *testing.T) {
+	examples := []string{
+		"",
+		"Fri 15:00",
+		"Fri 15:00 - Mon 8am",
+		"Fri 15:00 - Fri 15:00",
+		"Fri 15:00 - Mon 08:00 Mars/Olympus",
+		"2016-12-24 - 2016-12-23",
+		"2016-12-24 - 2017-01-02 Europe/Berlin extra",
+		"tomorrow",
+	}
+
+	for _, s := range examples {
+		_, err := deploy.ParseFreezeRule(s)
+		assert.Error(t, err, s)
+	}
+}
@@ -1,21 +1,40 @@
 package deploy
 
 import (
+	"sort"
 	"sync"
 	"time"
+
+	"github.com/adjust/michaelbot/scheduler"
+	"github.com/adjust/michaelbot/slack"
 )
 
 type InMemoryStore struct {
 	qmu sync.RWMutex
 	hmu sync.RWMutex
+	smu sync.RWMutex
+	tmu sync.RWMutex
+	umu sync.RWMutex
+	imu sync.RWMutex
+	wmu sync.RWMutex
 	m   map[string]Queue
 	h   map[string][]Deploy
+	s   map[string]ChannelSettings
+	t   map[string]scheduler.Timer
+	u   map[string]UserSettings
+	i   map[string]slack.Installation
+	w   map[string]WorkspaceSettings
 }
 
 func NewInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
 		m: make(map[string]Queue),
 		h: make(map[string][]Deploy),
+		s: make(map[string]ChannelSettings),
+		t: make(map[string]scheduler.Timer),
+		u: make(map[string]UserSettings),
+		i: make(map[string]slack.Installation),
+		w: make(map[string]WorkspaceSettings),
 	}
 }
 
@@ -58,12 +77,12 @@ func (s *InMemoryStore) Since(key string, startTime time.Time) []Deploy {
 
 	history, ok := s.h[key]
 
+	s.hmu.RUnlock()
+
 	if !ok {
 		return nil
 	}
 
-	s.hmu.RUnlock()
-
 	i := len(history)
 
 	for ; i > 0; i-- {
@@ -93,3 +112,117 @@ func (s *InMemoryStore) AddToHistory(key string, d Deploy) {
 
 	s.hmu.Unlock()
 }
+
+func (s *InMemoryStore) GetSettings(key string) ChannelSettings {
+	s.smu.RLock()
+	defer s.smu.RUnlock()
+
+	return s.s[key]
+}
+
+func (s *InMemoryStore) SetSettings(key string, settings ChannelSettings) {
+	s.smu.Lock()
+	defer s.smu.Unlock()
+
+	s.s[key] = settings
+}
+
+func (s *InMemoryStore) ChannelKeys() []string {
+	seen := make(map[string]struct{})
+
+	s.qmu.RLock()
+	for key := range s.m {
+		seen[key] = struct{}{}
+	}
+	s.qmu.RUnlock()
+
+	s.hmu.RLock()
+	for key := range s.h {
+		seen[key] = struct{}{}
+	}
+	s.hmu.RUnlock()
+
+	s.smu.RLock()
+	for key := range s.s {
+		seen[key] = struct{}{}
+	}
+	s.smu.RUnlock()
+
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (s *InMemoryStore) GetUserSettings(userID string) UserSettings {
+	s.umu.RLock()
+	defer s.umu.RUnlock()
+
+	return s.u[userID]
+}
+
+func (s *InMemoryStore) SetUserSettings(userID string, settings UserSettings) {
+	s.umu.Lock()
+	defer s.umu.Unlock()
+
+	s.u[userID] = settings
+}
+
+func (s *InMemoryStore) GetInstallation(teamID string) (slack.Installation, bool) {
+	s.imu.RLock()
+	defer s.imu.RUnlock()
+
+	inst, ok := s.i[teamID]
+
+	return inst, ok
+}
+
+func (s *InMemoryStore) SetInstallation(inst slack.Installation) {
+	s.imu.Lock()
+	defer s.imu.Unlock()
+
+	s.i[inst.TeamID] = inst
+}
+
+func (s *InMemoryStore) GetWorkspaceSettings(teamID string) WorkspaceSettings {
+	s.wmu.RLock()
+	defer s.wmu.RUnlock()
+
+	return s.w[teamID]
+}
+
+func (s *InMemoryStore) SetWorkspaceSettings(teamID string, settings WorkspaceSettings) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+
+	s.w[teamID] = settings
+}
+
+func (s *InMemoryStore) GetTimers() []scheduler.Timer {
+	s.tmu.RLock()
+	defer s.tmu.RUnlock()
+
+	timers := make([]scheduler.Timer, 0, len(s.t))
+	for _, t := range s.t {
+		timers = append(timers, t)
+	}
+
+	return timers
+}
+
+func (s *InMemoryStore) SetTimer(t scheduler.Timer) {
+	s.tmu.Lock()
+	defer s.tmu.Unlock()
+
+	s.t[t.ID] = t
+}
+
+func (s *InMemoryStore) RemoveTimer(id string) {
+	s.tmu.Lock()
+	defer s.tmu.Unlock()
+
+	delete(s.t, id)
+}
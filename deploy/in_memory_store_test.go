@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/scheduler"
+	"github.com/adjust/michaelbot/slack"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -19,3 +21,27 @@ func TestInMemoryStore_AsRepository(t *testing.T) {
 		return r, r.AddToHistory, nil, nil
 	}})
 }
+
+func TestInMemoryStore_AsTimerStore(t *testing.T) {
+	suite.Run(t, &TimerStoreSuite{Setup: func() (store scheduler.Store, teardownFn func(), err error) {
+		return deploy.NewInMemoryStore(), nil, nil
+	}})
+}
+
+func TestInMemoryStore_AsUserSettingsStore(t *testing.T) {
+	suite.Run(t, &UserSettingsStoreSuite{Setup: func() (store deploy.UserSettingsStore, teardownFn func(), err error) {
+		return deploy.NewInMemoryStore(), nil, nil
+	}})
+}
+
+func TestInMemoryStore_AsWorkspaceSettingsStore(t *testing.T) {
+	suite.Run(t, &WorkspaceSettingsStoreSuite{Setup: func() (store deploy.WorkspaceSettingsStore, teardownFn func(), err error) {
+		return deploy.NewInMemoryStore(), nil, nil
+	}})
+}
+
+func TestInMemoryStore_AsInstallationStore(t *testing.T) {
+	suite.Run(t, &InstallationStoreSuite{Setup: func() (store slack.InstallationStore, teardownFn func(), err error) {
+		return deploy.NewInMemoryStore(), nil, nil
+	}})
+}
@@ -0,0 +1,48 @@
+package deploy_test
+
+import (
+	"time"
+
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type InstallationStoreSuite struct {
+	suite.Suite
+	Setup func() (store slack.InstallationStore, teardownFn func(), err error)
+}
+
+func (suite *InstallationStoreSuite) TestGetSet() {
+	store, teardown, err := suite.Setup()
+	if teardown != nil {
+		defer teardown()
+	}
+	require.NoError(suite.T(), err)
+
+	_, ok := store.GetInstallation("T1")
+	assert.False(suite.T(), ok)
+
+	inst := slack.Installation{
+		TeamID:      "T1",
+		TeamName:    "Team 1",
+		BotUserID:   "U0BOT",
+		AccessToken: "xoxb-1",
+		InstalledAt: time.Now().Round(0).UTC(),
+	}
+
+	store.SetInstallation(inst)
+	if stored, ok := store.GetInstallation("T1"); assert.True(suite.T(), ok) {
+		assert.Equal(suite.T(), inst, stored)
+	}
+
+	_, ok = store.GetInstallation("T2")
+	assert.False(suite.T(), ok)
+
+	inst.AccessToken = "xoxb-2"
+	store.SetInstallation(inst)
+	if stored, ok := store.GetInstallation("T1"); assert.True(suite.T(), ok) {
+		assert.Equal(suite.T(), "xoxb-2", stored.AccessToken)
+	}
+}
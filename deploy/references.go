@@ -2,8 +2,13 @@ package deploy
 
 import (
 	"bufio"
+	"fmt"
+	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+
+	"github.com/adjust/michaelbot/slack"
 )
 
 var (
@@ -11,13 +16,17 @@ var (
 		regexp.MustCompile("^(?P<repository>[A-Za-z0-9\\._-]+/[A-Za-z0-9\\._-]+)#(?P<number>\\d+)[^A-Za-z]?$"),    // octocat/helloworld#12
 		regexp.MustCompile("^<?https?://github.com/(?P<repository>\\S+/\\S+)/pull/(?P<number>\\d+)(?:[\\?#>]|$)"), // https://github.com/octocat/helloworld/pull/12
 	}
+	channelReferenceRegexes = []*regexp.Regexp{
+		regexp.MustCompile("^<#(?P<channelid>[A-Z0-9]+)(?:\\|(?P<channelname>[A-Za-z0-9\\._-]*))?>"), // escaped, i.e. <#C1|general>
+	}
 	userReferenceRegexes = []*regexp.Regexp{
 		// Usernames can be up to 21 characters long. They can contain lowercase letters a to z (without accents),
 		// numbers 0 to 9, hyphens, periods, and underscores.
 		//
 		// See https://get.slack.help/hc/en-us/articles/216360827-Change-your-username
-		regexp.MustCompile("^@(?P<username>[A-Za-z0-9\\._-]+)"),                           // unescaped, i.e. @user1
-		regexp.MustCompile("^<@(?P<userid>[A-Z0-9]+)\\|(?P<username>[A-Za-z0-9\\._-]+)>"), // escaped, i.e. <@U1|user1>
+		regexp.MustCompile("^@(?P<username>[A-Za-z0-9\\._-]+)"),                                 // unescaped, i.e. @user1
+		regexp.MustCompile("^<@(?P<userid>[A-Z0-9]+)\\|(?P<username>[A-Za-z0-9\\._-]+)>"),       // escaped, i.e. <@U1|user1>
+		regexp.MustCompile("^<!subteam\\^(?P<groupid>[A-Z0-9]+)(?:\\|@?(?P<username>[^>]*))?>"), // user group, i.e. <!subteam^S1|@group1>
 	}
 )
 
@@ -35,15 +44,71 @@ func FindPullRequestReferences(s string) []PullRequestReference {
 	return refs
 }
 
-type UserReference struct {
+// ChannelReference is a mention of a Slack channel. Name is only set if Slack has included it into the mention.
+type ChannelReference struct {
 	ID   string
 	Name string
 }
 
+func FindChannelReferences(s string) []ChannelReference {
+	var refs []ChannelReference
+	findReferences(s, channelReferenceRegexes, func(matches map[string]string) {
+		refs = append(refs, ChannelReference{ID: matches["channelid"], Name: matches["channelname"]})
+	})
+
+	return refs
+}
+
+// ParsePullRequestURL returns the reference to a GitHub pull request, i.e. https://github.com/octocat/helloworld/pull/12
+func ParsePullRequestURL(s string) (PullRequestReference, error) {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host != "github.com" {
+		return PullRequestReference{}, fmt.Errorf("%q is not a GitHub URL", s)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || parts[0] == "" || parts[1] == "" || parts[2] != "pull" {
+		return PullRequestReference{}, fmt.Errorf("%q is not a pull request URL", s)
+	}
+
+	if _, err := strconv.ParseUint(parts[3], 10, 64); err != nil {
+		return PullRequestReference{}, fmt.Errorf("%q is not a pull request URL", s)
+	}
+
+	return PullRequestReference{Repository: parts[0] + "/" + parts[1], ID: parts[3]}, nil
+}
+
+// UserReference is a mention of a user or of a user group. Group references have GroupID set and Name
+// contains the group handle.
+type UserReference struct {
+	ID      string
+	Name    string
+	GroupID string `json:",omitempty"`
+}
+
+// IsGroup returns true if ref points to a user group.
+func (ref UserReference) IsGroup() bool {
+	return ref.GroupID != ""
+}
+
+// Matches returns true if ref points to user u. References without user ID are matched by name. Group
+// references never match a user.
+func (ref UserReference) Matches(u slack.User) bool {
+	if ref.IsGroup() {
+		return false
+	}
+
+	if ref.ID != "" {
+		return ref.ID == u.ID
+	}
+
+	return ref.Name == u.Name
+}
+
 func FindUserReferences(s string) []UserReference {
 	var refs []UserReference
 	findReferences(s, userReferenceRegexes, func(matches map[string]string) {
-		refs = append(refs, UserReference{ID: matches["userid"], Name: matches["username"]})
+		refs = append(refs, UserReference{ID: matches["userid"], Name: matches["username"], GroupID: matches["groupid"]})
 	})
 
 	return refs
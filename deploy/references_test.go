@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/slack"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,6 +58,39 @@ func TestFindPullRequestReferences_Mixed_Multiple(t *testing.T) {
 	}
 }
 
+func TestParsePullRequestURL(t *testing.T) {
+	for _, s := range []string{
+		"https://github.com/user/project/pull/1",
+		" https://github.com/user/project/pull/1/files?w=1#diff-123 ",
+	} {
+		ref, err := deploy.ParsePullRequestURL(s)
+		if assert.NoError(t, err, s) {
+			assert.Equal(t, deploy.PullRequestReference{ID: "1", Repository: "user/project"}, ref, s)
+		}
+	}
+
+	for _, s := range []string{
+		"user/project#1",
+		"https://github.com/user/project/issues/2",
+		"https://github.com/user/project/pull/abc",
+		"https://github.com/user/project/pulls",
+		"https://bitbucket.org/user/project/pull/3",
+	} {
+		_, err := deploy.ParsePullRequestURL(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestFindChannelReferences(t *testing.T) {
+	s := "deploying <#C1|payments> and <#C2>, see #general or <#c3|lowercase> <C4|notachannel>"
+
+	refs := deploy.FindChannelReferences(s)
+	if assert.Len(t, refs, 2) {
+		assert.Contains(t, refs, deploy.ChannelReference{ID: "C1", Name: "payments"})
+		assert.Contains(t, refs, deploy.ChannelReference{ID: "C2"})
+	}
+}
+
 func TestFindUserReferences_Short(t *testing.T) {
 	s := "" +
 		"hello @person_1, my email is writeme@gmail.com, see you @ the bar. " +
@@ -78,3 +112,17 @@ func TestFindUserReferences_Long(t *testing.T) {
 		assert.Contains(t, refs, deploy.UserReference{ID: "U1", Name: "user1"})
 	}
 }
+
+func TestFindUserReferences_Groups(t *testing.T) {
+	s := "deploying for <!subteam^S1|@backend> and <!subteam^S2>, cc <@U1|user1> <!here>"
+
+	refs := deploy.FindUserReferences(s)
+	if assert.Len(t, refs, 3) {
+		assert.Contains(t, refs, deploy.UserReference{GroupID: "S1", Name: "backend"})
+		assert.Contains(t, refs, deploy.UserReference{GroupID: "S2"})
+		assert.Contains(t, refs, deploy.UserReference{ID: "U1", Name: "user1"})
+	}
+
+	assert.True(t, refs[0].IsGroup())
+	assert.False(t, refs[0].Matches(slack.User{Name: "backend"}))
+}
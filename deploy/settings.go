@@ -0,0 +1,101 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adjust/michaelbot/slack"
+)
+
+// ChannelSettings holds the channel configuration shared by all its environments.
+type ChannelSettings struct {
+	Lock        *Lock         `json:",omitempty"`
+	FreezeRules []FreezeRule  `json:",omitempty"`
+	Expiry      *ExpiryPolicy `json:",omitempty"`
+	// Subscribers are notified about every deploy started, completed or aborted in the channel
+	Subscribers []slack.User `json:",omitempty"`
+	// Locale is the language of bot messages posted in the channel, i.e. de. The workspace locale is used if empty.
+	Locale string `json:",omitempty"`
+}
+
+// Lock prevents new deploys from being started in a channel.
+type Lock struct {
+	User     slack.User
+	Reason   string
+	LockedAt time.Time
+	// TeamID is the Slack workspace of the locked channel if the app has been installed into multiple workspaces
+	TeamID string `json:",omitempty"`
+}
+
+// Deploy outcomes a user mentioned in the deploy subject is notified about
+const (
+	NotifyOnDone  = "done"
+	NotifyOnAbort = "abort"
+	NotifyOnBoth  = "both"
+)
+
+// UserSettings holds personal preferences of a Slack user.
+type UserSettings struct {
+	// MuteTurnNotifications disables direct messages sent when a queued deploy starts
+	MuteTurnNotifications bool `json:",omitempty"`
+	// MentionNotifications maps channel settings keys to the deploy outcomes the user is notified about if mentioned
+	// in the deploy subject, i.e. NotifyOnDone
+	MentionNotifications map[string]string `json:",omitempty"`
+}
+
+// NotifyOn returns the deploy outcomes the user wants to be notified about in channel. Users are notified about both
+// completed and aborted deploys unless they have chosen otherwise.
+func (s UserSettings) NotifyOn(ch Channel) string {
+	if v, ok := s.MentionNotifications[ch.SettingsKey()]; ok {
+		return v
+	}
+
+	return NotifyOnBoth
+}
+
+// SetNotifyOn sets the deploy outcomes the user wants to be notified about in all environments of the channel.
+func (s *UserSettings) SetNotifyOn(ch Channel, v string) {
+	if v == NotifyOnBoth {
+		delete(s.MentionNotifications, ch.SettingsKey())
+		if len(s.MentionNotifications) == 0 {
+			s.MentionNotifications = nil
+		}
+
+		return
+	}
+
+	if s.MentionNotifications == nil {
+		s.MentionNotifications = make(map[string]string)
+	}
+
+	s.MentionNotifications[ch.SettingsKey()] = v
+}
+
+// ParseNotifyOn validates the deploy outcomes to be notified about.
+func ParseNotifyOn(s string) (string, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case NotifyOnDone, NotifyOnAbort, NotifyOnBoth:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown notification option %q, expected one of %s, %s or %s", s, NotifyOnDone, NotifyOnAbort, NotifyOnBoth)
+	}
+}
+
+type UserSettingsStore interface {
+	GetUserSettings(userID string) UserSettings
+	SetUserSettings(userID string, s UserSettings)
+}
+
+// WorkspaceSettings holds the configuration shared by all channels of a Slack workspace.
+type WorkspaceSettings struct {
+	// Locale is the language of bot messages in channels that have no locale of their own
+	Locale string `json:",omitempty"`
+}
+
+// WorkspaceSettingsStore keeps workspace settings. The team ID is empty if the app has been installed
+// into a single workspace.
+type WorkspaceSettingsStore interface {
+	GetWorkspaceSettings(teamID string) WorkspaceSettings
+	SetWorkspaceSettings(teamID string, s WorkspaceSettings)
+}
@@ -0,0 +1,41 @@
+package deploy_test
+
+import (
+	"testing"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserSettings_NotifyOn(t *testing.T) {
+	var settings deploy.UserSettings
+
+	staging := deploy.Channel{ID: "C1", Environment: "staging"}
+	assert.Equal(t, deploy.NotifyOnBoth, settings.NotifyOn(staging))
+
+	settings.SetNotifyOn(staging, deploy.NotifyOnAbort)
+	assert.Equal(t, deploy.NotifyOnAbort, settings.NotifyOn(staging))
+	assert.Equal(t, deploy.NotifyOnAbort, settings.NotifyOn(deploy.Channel{ID: "C1"}))
+	assert.Equal(t, deploy.NotifyOnBoth, settings.NotifyOn(deploy.Channel{ID: "C2"}))
+	assert.Equal(t, deploy.NotifyOnBoth, settings.NotifyOn(deploy.Channel{TeamID: "T1", ID: "C1"}))
+
+	settings.SetNotifyOn(staging, deploy.NotifyOnBoth)
+	assert.Equal(t, deploy.UserSettings{}, settings)
+}
+
+func TestParseNotifyOn(t *testing.T) {
+	for _, s := range []string{"done", "abort", "both"} {
+		v, err := deploy.ParseNotifyOn(s)
+		if assert.NoError(t, err) {
+			assert.Equal(t, s, v)
+		}
+	}
+
+	v, err := deploy.ParseNotifyOn(" Done ")
+	if assert.NoError(t, err) {
+		assert.Equal(t, deploy.NotifyOnDone, v)
+	}
+
+	_, err = deploy.ParseNotifyOn("never")
+	assert.EqualError(t, err, `unknown notification option "never", expected one of done, abort or both`)
+}
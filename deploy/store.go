@@ -1,7 +1,12 @@
 package deploy
 
 type Store interface {
+	Repository
 	GetQueue(key string) Queue
 	SetQueue(key string, q Queue)
 	AddToHistory(key string, d Deploy)
+	GetSettings(key string) ChannelSettings
+	SetSettings(key string, s ChannelSettings)
+	// ChannelKeys returns the keys of all channels that have a queue, history or settings stored
+	ChannelKeys() []string
 }
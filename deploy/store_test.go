@@ -49,3 +49,54 @@ func (suite *StoreSuite) TestGetSet() {
 
 	assert.Equal(suite.T(), queue, q)
 }
+
+func (suite *StoreSuite) TestGetSetSettings() {
+	store, teardown, err := suite.Setup()
+	if teardown != nil {
+		defer teardown()
+	}
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), deploy.ChannelSettings{}, store.GetSettings("key1"))
+
+	settings := deploy.ChannelSettings{
+		Lock: &deploy.Lock{
+			User:     slack.User{ID: "1", Name: "Test User"},
+			Reason:   "incident",
+			LockedAt: time.Now().Round(0).Add(-5 * time.Minute).UTC(),
+		},
+		FreezeRules: []deploy.FreezeRule{
+			{Weekly: &deploy.WeeklyFreeze{Start: 5*24*time.Hour + 15*time.Hour, End: 24*time.Hour + 8*time.Hour}, Location: "Europe/Berlin"},
+			{Since: time.Date(2016, time.December, 24, 0, 0, 0, 0, time.UTC), Until: time.Date(2017, time.January, 3, 0, 0, 0, 0, time.UTC), Location: "UTC"},
+		},
+		Expiry:      &deploy.ExpiryPolicy{Warn: 2 * time.Hour, Abort: 8 * time.Hour},
+		Subscribers: []slack.User{{ID: "U2", Name: "subscriber"}},
+		Locale:      "de",
+	}
+
+	store.SetSettings("key1", settings)
+	assert.Equal(suite.T(), settings, store.GetSettings("key1"))
+	assert.Equal(suite.T(), deploy.ChannelSettings{}, store.GetSettings("key2"))
+
+	store.SetSettings("key1", deploy.ChannelSettings{})
+	assert.Equal(suite.T(), deploy.ChannelSettings{}, store.GetSettings("key1"))
+}
+
+func (suite *StoreSuite) TestChannelKeys() {
+	store, teardown, err := suite.Setup()
+	if teardown != nil {
+		defer teardown()
+	}
+	require.NoError(suite.T(), err)
+
+	assert.Empty(suite.T(), store.ChannelKeys())
+
+	queue := deploy.NewEmptyQueue()
+	queue.Add(deploy.New(slack.User{ID: "1", Name: "Test User"}, "Test subject"))
+
+	store.SetQueue("C1/staging", queue)
+	store.AddToHistory("C2", deploy.New(slack.User{ID: "1", Name: "Test User"}, "Test subject"))
+	store.SetSettings("C3", deploy.ChannelSettings{Expiry: &deploy.ExpiryPolicy{Warn: time.Hour}})
+
+	assert.Equal(suite.T(), []string{"C1/staging", "C2", "C3"}, store.ChannelKeys())
+}
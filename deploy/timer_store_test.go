@@ -0,0 +1,44 @@
+package deploy_test
+
+import (
+	"time"
+
+	"github.com/adjust/michaelbot/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TimerStoreSuite struct {
+	suite.Suite
+	Setup func() (store scheduler.Store, teardownFn func(), err error)
+}
+
+func (suite *TimerStoreSuite) TestGetSetRemove() {
+	store, teardown, err := suite.Setup()
+	if teardown != nil {
+		defer teardown()
+	}
+	require.NoError(suite.T(), err)
+
+	assert.Empty(suite.T(), store.GetTimers())
+
+	t1 := scheduler.Timer{ID: "timer1", Kind: "kind1", FireAt: time.Now().Round(0).Add(time.Hour).UTC(), Data: []byte(`{"key":"value"}`)}
+	t2 := scheduler.Timer{ID: "timer2", Kind: "kind2", FireAt: time.Now().Round(0).Add(2 * time.Hour).UTC()}
+
+	store.SetTimer(t1)
+	store.SetTimer(t2)
+
+	timers := store.GetTimers()
+	if assert.Len(suite.T(), timers, 2) {
+		assert.Contains(suite.T(), timers, t1)
+		assert.Contains(suite.T(), timers, t2)
+	}
+
+	t1.FireAt = t1.FireAt.Add(time.Hour)
+	store.SetTimer(t1)
+	store.RemoveTimer(t2.ID)
+	store.RemoveTimer("timer3")
+
+	assert.Equal(suite.T(), []scheduler.Timer{t1}, store.GetTimers())
+}
@@ -0,0 +1,35 @@
+package deploy_test
+
+import (
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type UserSettingsStoreSuite struct {
+	suite.Suite
+	Setup func() (store deploy.UserSettingsStore, teardownFn func(), err error)
+}
+
+func (suite *UserSettingsStoreSuite) TestGetSet() {
+	store, teardown, err := suite.Setup()
+	if teardown != nil {
+		defer teardown()
+	}
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), deploy.UserSettings{}, store.GetUserSettings("U1"))
+
+	settings := deploy.UserSettings{
+		MuteTurnNotifications: true,
+		MentionNotifications:  map[string]string{"C1": deploy.NotifyOnAbort},
+	}
+
+	store.SetUserSettings("U1", settings)
+	assert.Equal(suite.T(), settings, store.GetUserSettings("U1"))
+	assert.Equal(suite.T(), deploy.UserSettings{}, store.GetUserSettings("U2"))
+
+	store.SetUserSettings("U1", deploy.UserSettings{})
+	assert.Equal(suite.T(), deploy.UserSettings{}, store.GetUserSettings("U1"))
+}
@@ -0,0 +1,42 @@
+package deploy_test
+
+import (
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type WorkspaceSettingsStoreSuite struct {
+	suite.Suite
+	Setup func() (store deploy.WorkspaceSettingsStore, teardownFn func(), err error)
+}
+
+func (suite *WorkspaceSettingsStoreSuite) TestGetSet() {
+	store, teardown, err := suite.Setup()
+	if teardown != nil {
+		defer teardown()
+	}
+	require.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), deploy.WorkspaceSettings{}, store.GetWorkspaceSettings("T1"))
+
+	store.SetWorkspaceSettings("T1", deploy.WorkspaceSettings{Locale: "de"})
+	assert.Equal(suite.T(), deploy.WorkspaceSettings{Locale: "de"}, store.GetWorkspaceSettings("T1"))
+	assert.Equal(suite.T(), deploy.WorkspaceSettings{}, store.GetWorkspaceSettings("T2"))
+
+	store.SetWorkspaceSettings("T1", deploy.WorkspaceSettings{})
+	assert.Equal(suite.T(), deploy.WorkspaceSettings{}, store.GetWorkspaceSettings("T1"))
+}
+
+func (suite *WorkspaceSettingsStoreSuite) TestGetSet_SingleWorkspace() {
+	store, teardown, err := suite.Setup()
+	if teardown != nil {
+		defer teardown()
+	}
+	require.NoError(suite.T(), err)
+
+	store.SetWorkspaceSettings("", deploy.WorkspaceSettings{Locale: "es"})
+	assert.Equal(suite.T(), deploy.WorkspaceSettings{Locale: "es"}, store.GetWorkspaceSettings(""))
+	assert.Equal(suite.T(), deploy.WorkspaceSettings{}, store.GetWorkspaceSettings("T1"))
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"expvar"
 	"flag"
 	"fmt"
 	"log"
@@ -8,22 +9,41 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // deploy freeze rules need time zone info that is missing in the container image
 
 	"github.com/adjust/michaelbot/auth"
 	"github.com/adjust/michaelbot/bot"
 	"github.com/adjust/michaelbot/dashboard"
 	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/github"
+	"github.com/adjust/michaelbot/scheduler"
 	"github.com/adjust/michaelbot/server"
 	"github.com/adjust/michaelbot/slack"
 )
 
 const (
-	DefaultHost = "0.0.0.0"
-	DefaultPort = 8081
+	DefaultHost      = "0.0.0.0"
+	DefaultPort      = 8081
+	DefaultDebugHost = "127.0.0.1"
 )
 
+// slackBotScopes are the permissions requested when the app is installed into a workspace via OAuth
+var slackBotScopes = []string{
+	"commands",
+	"chat:write",
+	"app_mentions:read",
+	"channels:read",
+	"channels:manage",
+	"groups:read",
+	"groups:write",
+	"im:write",
+	"usergroups:read",
+	"users:read",
+}
+
 var (
 	binPath        = os.Args[0]
 	version        = "n/a"
@@ -35,6 +55,8 @@ var (
 	args struct {
 		host         string
 		port         int
+		debugHost    string
+		debugPort    int
 		printVersion bool
 	}
 )
@@ -43,6 +65,8 @@ func init() {
 	flag.BoolVar(&args.printVersion, "version", false, "Print version and exit")
 	flag.StringVar(&args.host, "h", DefaultHost, "Host or address to listen on")
 	flag.IntVar(&args.port, "p", DefaultPort, "Port to listen on")
+	flag.StringVar(&args.debugHost, "debug-h", DefaultDebugHost, "Host or address to serve runtime stats on")
+	flag.IntVar(&args.debugPort, "debug-p", 0, "Port to serve runtime stats on, disabled if not set")
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\nOptions:\n", binPath)
 		flag.PrintDefaults()
@@ -63,9 +87,9 @@ func main() {
 		printVersion()
 	}
 
-	slackToken := os.Getenv("SLACK_TOKEN")
-	if slackToken == "" {
-		log.Fatal("Missing SLACK_TOKEN env variable")
+	slackSigningSecret, slackToken := os.Getenv("SLACK_SIGNING_SECRET"), os.Getenv("SLACK_TOKEN")
+	if slackSigningSecret == "" && slackToken == "" {
+		log.Fatal("Missing SLACK_SIGNING_SECRET env variable")
 	}
 
 	log.SetOutput(os.Stderr)
@@ -79,6 +103,10 @@ func main() {
 	var (
 		slackBot        *bot.Bot
 		deployDashboard *dashboard.Dashboard
+		sched           *scheduler.Scheduler
+		deployStore     deploy.Store
+		userSettings    deploy.UserSettingsStore
+		installations   slack.InstallationStore
 	)
 	if boltDBPath := os.Getenv("BOLTDB_PATH"); boltDBPath != "" {
 		log.Printf("writing deploy history into a BoltDB in %s", boltDBPath)
@@ -89,25 +117,82 @@ func main() {
 		}
 
 		deployDashboard = dashboard.New(store)
-		slackBot = bot.New(slackToken, githubToken, store)
+		slackBot = bot.New("", githubToken, store)
+		sched = scheduler.New(store)
+		deployStore = store
+		userSettings = store
+		installations = store
 	} else {
 		log.Println("BOLTDB_PATH env variable not set, keeping deploy history in memory")
 
 		store := deploy.NewInMemoryStore()
 		deployDashboard = dashboard.New(store)
-		slackBot = bot.New(slackToken, githubToken, store)
+		slackBot = bot.New("", githubToken, store)
+		sched = scheduler.New(store)
+		deployStore = store
+		userSettings = store
+		installations = store
+	}
+
+	if envs := os.Getenv("DEPLOY_ENVIRONMENTS"); envs != "" {
+		slackBot.SetEnvironments(strings.Split(envs, ",")...)
+	}
+
+	var (
+		api          slack.WebAPIClients
+		oauthHandler *slack.OAuthHandler
+	)
+	if slackClientID := os.Getenv("SLACK_CLIENT_ID"); slackClientID != "" {
+		redirectURL := os.Getenv("SLACK_OAUTH_REDIRECT_URL")
+		if redirectURL == "" {
+			log.Fatal("Missing SLACK_OAUTH_REDIRECT_URL env variable")
+		}
+
+		log.Printf("SLACK_CLIENT_ID is set, the app can be installed into multiple workspaces via /slack/install")
+
+		// Use the bot token issued for the workspace of each request
+		clients := slack.NewInstallations(installations, nil)
+		oauthHandler = slack.NewOAuthHandler(slackClientID, os.Getenv("SLACK_CLIENT_SECRET"), redirectURL, slackBotScopes, clients)
+		slackBot.SeparateWorkspaces()
+
+		api = clients
+	} else if slackWebAPIToken := os.Getenv("SLACK_WEBAPI_TOKEN"); slackWebAPIToken != "" {
+		api = slack.NewWebAPI(slackWebAPIToken, nil)
 	}
 
-	if slackWebAPIToken := os.Getenv("SLACK_WEBAPI_TOKEN"); slackWebAPIToken != "" {
-		api := slack.NewWebAPI(slackWebAPIToken, nil)
+	if api != nil {
+		// Notifications are sent in the language chosen for the channel or workspace with /deploy locale
+		locales := bot.NewLocalizer(deployStore)
+
 		// Update channel topic to reflect current deploy status
 		slackBot.AddDeployEventHandler(bot.NewSlackTopicManager(api))
 		// Send direct messages to users mentioned in deploy subject
-		slackBot.AddDeployEventHandler(bot.NewSlackIMNotifier(api, 2*time.Hour))
+		slackBot.AddDeployEventHandler(bot.NewSlackIMNotifier(api, sched, userSettings, locales, 2*time.Hour))
+		// Let users know when their queued deploys start
+		slackBot.AddDeployEventHandler(bot.NewSlackTurnNotifier(api, userSettings, locales))
+		// Send direct messages about all deploys in a channel to users subscribed with /deploy subscribe
+		slackBot.AddDeployEventHandler(bot.NewSlackSubscriptionNotifier(api, deployStore, locales))
+		// Mirror deploy announcements into channels mentioned in deploy subject
+		slackBot.AddDeployEventHandler(bot.NewSlackCrossPoster(api, bot.NewResponseBuilder(github.NewClient(githubToken, nil)), locales))
+		// Look up users mentioned by name in commands, such as /deploy handover @user
+		slackBot.EnableTeamDirectory(api)
+		// Remind about, and eventually abort deploys that have been running for too long
+		slackBot.EnableDeployExpiry(sched, api)
+		// Accept commands sent by mentioning the bot, such as @michael status
+		slackBot.EnableMentions(api)
+		// Keep deploy events in the thread of the deploy announcement
+		slackBot.EnableDeployThreads(api)
+		// Open a modal to start a deploy with /deploy without arguments
+		slackBot.EnableDeployModal(api)
+		// Show deploys in all channels of a user in the app home tab
+		slackBot.EnableAppHome(api)
 	} else {
-		log.Printf("SLACK_WEBAPI_TOKEN env variable not set, channel topic notifications are disabled")
+		log.Printf("neither SLACK_CLIENT_ID nor SLACK_WEBAPI_TOKEN env variable is set, channel topic notifications are disabled")
 	}
 
+	// Pick up the timers scheduled before restart
+	sched.Restore()
+
 	tokenSource := auth.RandomTokenSource{Src: rand.NewSource(time.Now().UnixNano())}
 	authenticator := auth.NewOneTimeTokenAuthenticator(&tokenSource)
 
@@ -127,7 +212,24 @@ func main() {
 		}
 		fmt.Fprintf(w, "Michaelbot is running!")
 	})
-	mux.Handle("/deploy", slackBot)
+	if slackSigningSecret == "" {
+		log.Printf("SLACK_SIGNING_SECRET env variable not set, falling back to the legacy SLACK_TOKEN verification")
+	} else if slackToken != "" {
+		log.Printf("SLACK_TOKEN env variable is set, unsigned requests containing this token will be accepted")
+	}
+
+	// Make sure that requests to Slack endpoints are sent by Slack
+	slackVerifier := func(h http.Handler) http.Handler {
+		return slack.RequestVerificationMiddleware(h, []byte(slackSigningSecret), slackToken)
+	}
+
+	mux.Handle("/deploy", slackVerifier(slackBot))
+	mux.Handle("/interactions", slackVerifier(http.HandlerFunc(slackBot.ServeInteraction)))
+	mux.Handle("/events", slackVerifier(http.HandlerFunc(slackBot.ServeEvents)))
+	if oauthHandler != nil {
+		mux.HandleFunc("/slack/install", oauthHandler.ServeInstall)
+		mux.HandleFunc("/slack/oauth/callback", oauthHandler.ServeCallback)
+	}
 	mux.Handle("/", auth.TokenAuthenticationMiddleware(auth.ChannelAuthorizerMiddleware(deployDashboard, []byte(authSecret)), authenticator, []byte(authSecret)))
 
 	srv := server.New(args.host, args.port)
@@ -137,6 +239,21 @@ func main() {
 
 	log.Printf("Michael Buffer v%s is listening on %s", version, srv.Addr)
 
+	// Runtime stats and the number of requests throttled by Slack Web API are served on a separate listener,
+	// so that they are not exposed along with the public endpoints
+	if args.debugPort != 0 {
+		debugMux := http.NewServeMux()
+		debugMux.Handle("/debug/vars", expvar.Handler())
+
+		debugSrv := server.New(args.debugHost, args.debugPort)
+		if err := debugSrv.Start(debugMux); err != nil {
+			log.Fatal(err)
+		}
+		defer debugSrv.Shutdown()
+
+		log.Printf("runtime stats are available at http://%s/debug/vars", debugSrv.Addr)
+	}
+
 	signals := make(chan os.Signal)
 	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
 
@@ -0,0 +1,137 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Timer is a job scheduled to run at certain time. Timers are persisted in the store, so they survive
+// service restarts.
+type Timer struct {
+	// ID is a unique timer key, scheduling another timer with the same ID replaces the existing one
+	ID string
+	// Kind is the name of a handler that is called when the timer fires
+	Kind   string
+	FireAt time.Time
+	// Data is an arbitrary handler-specific payload
+	Data json.RawMessage `json:",omitempty"`
+}
+
+type Store interface {
+	GetTimers() []Timer
+	SetTimer(t Timer)
+	RemoveTimer(id string)
+}
+
+type HandlerFunc func(t Timer)
+
+type pendingTimer struct {
+	Timer
+	timer *time.Timer
+}
+
+type Scheduler struct {
+	store Store
+
+	mu       sync.Mutex
+	handlers map[string]HandlerFunc
+	timers   map[string]*pendingTimer
+}
+
+func New(store Store) *Scheduler {
+	return &Scheduler{
+		store:    store,
+		handlers: make(map[string]HandlerFunc),
+		timers:   make(map[string]*pendingTimer),
+	}
+}
+
+// Handle registers a handler for timers of given kind. All handlers should be registered before
+// calling Restore().
+func (s *Scheduler) Handle(kind string, h HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.handlers[kind] = h
+}
+
+// Schedule stores the timer and arms it. A pending timer with the same ID is replaced.
+func (s *Scheduler) Schedule(t Timer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store.SetTimer(t)
+	s.arm(t)
+}
+
+// Cancel stops and removes a pending timer. It is safe to cancel timers that have already fired.
+func (s *Scheduler) Cancel(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pending, ok := s.timers[id]; ok {
+		pending.timer.Stop()
+		delete(s.timers, id)
+	}
+
+	s.store.RemoveTimer(id)
+}
+
+// Pending returns the timer with given ID if it has not fired yet.
+func (s *Scheduler) Pending(id string) (Timer, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, ok := s.timers[id]
+	if !ok {
+		return Timer{}, false
+	}
+
+	return pending.Timer, true
+}
+
+// Restore arms all timers kept in the store. Timers that were due while the service has been down fire immediately.
+func (s *Scheduler) Restore() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.store.GetTimers() {
+		s.arm(t)
+	}
+}
+
+func (s *Scheduler) arm(t Timer) {
+	if pending, ok := s.timers[t.ID]; ok {
+		pending.timer.Stop()
+	}
+
+	pending := &pendingTimer{Timer: t}
+	pending.timer = time.AfterFunc(time.Until(t.FireAt), func() { s.fire(pending) })
+
+	s.timers[t.ID] = pending
+}
+
+func (s *Scheduler) fire(pending *pendingTimer) {
+	s.mu.Lock()
+
+	// the timer might have been replaced or cancelled while waiting for the lock
+	if s.timers[pending.ID] != pending {
+		s.mu.Unlock()
+		return
+	}
+
+	delete(s.timers, pending.ID)
+	s.store.RemoveTimer(pending.ID)
+
+	h, ok := s.handlers[pending.Kind]
+	s.mu.Unlock()
+
+	if !ok {
+		log.Printf("scheduler: no handler for %s timer %s, skipping", pending.Kind, pending.ID)
+		return
+	}
+
+	h(pending.Timer)
+}
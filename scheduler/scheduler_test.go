@@ -0,0 +1,126 @@
+package scheduler_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/deploy"
+	"github.com/adjust/michaelbot/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type firedTimers struct {
+	mu     sync.Mutex
+	timers []scheduler.Timer
+}
+
+func (f *firedTimers) Handle(t scheduler.Timer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.timers = append(f.timers, t)
+}
+
+func (f *firedTimers) IDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var ids []string
+	for _, t := range f.timers {
+		ids = append(ids, t.ID)
+	}
+
+	return ids
+}
+
+func TestScheduler_Schedule(t *testing.T) {
+	store := deploy.NewInMemoryStore()
+
+	var fired firedTimers
+
+	sched := scheduler.New(store)
+	sched.Handle("test", fired.Handle)
+
+	sched.Schedule(scheduler.Timer{ID: "timer1", Kind: "test", FireAt: time.Now().Add(10 * time.Millisecond), Data: []byte(`{"key":"value"}`)})
+	sched.Schedule(scheduler.Timer{ID: "timer2", Kind: "test", FireAt: time.Now().Add(time.Hour)})
+	assert.Len(t, store.GetTimers(), 2)
+
+	if timer, ok := sched.Pending("timer1"); assert.True(t, ok) {
+		assert.Equal(t, "test", timer.Kind)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+
+	assert.Equal(t, []string{"timer1"}, fired.IDs())
+	assert.Equal(t, `{"key":"value"}`, string(fired.timers[0].Data))
+
+	_, ok := sched.Pending("timer1")
+	assert.False(t, ok)
+
+	if timers := store.GetTimers(); assert.Len(t, timers, 1) {
+		assert.Equal(t, "timer2", timers[0].ID)
+	}
+
+	sched.Cancel("timer2")
+}
+
+func TestScheduler_Schedule_Replace(t *testing.T) {
+	var fired firedTimers
+
+	sched := scheduler.New(deploy.NewInMemoryStore())
+	sched.Handle("test", fired.Handle)
+
+	sched.Schedule(scheduler.Timer{ID: "timer1", Kind: "test", FireAt: time.Now().Add(10 * time.Millisecond), Data: []byte(`1`)})
+	sched.Schedule(scheduler.Timer{ID: "timer1", Kind: "test", FireAt: time.Now().Add(20 * time.Millisecond), Data: []byte(`2`)})
+
+	time.Sleep(40 * time.Millisecond)
+
+	fired.mu.Lock()
+	defer fired.mu.Unlock()
+
+	if assert.Len(t, fired.timers, 1) {
+		assert.Equal(t, `2`, string(fired.timers[0].Data))
+	}
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	store := deploy.NewInMemoryStore()
+
+	var fired firedTimers
+
+	sched := scheduler.New(store)
+	sched.Handle("test", fired.Handle)
+
+	sched.Schedule(scheduler.Timer{ID: "timer1", Kind: "test", FireAt: time.Now().Add(10 * time.Millisecond)})
+	sched.Cancel("timer1")
+	sched.Cancel("timer2")
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Empty(t, fired.IDs())
+	assert.Empty(t, store.GetTimers())
+}
+
+func TestScheduler_Restore(t *testing.T) {
+	store := deploy.NewInMemoryStore()
+	store.SetTimer(scheduler.Timer{ID: "overdue", Kind: "test", FireAt: time.Now().Add(-time.Hour)})
+	store.SetTimer(scheduler.Timer{ID: "pending", Kind: "test", FireAt: time.Now().Add(10 * time.Millisecond)})
+	store.SetTimer(scheduler.Timer{ID: "unknown", Kind: "unknown", FireAt: time.Now()})
+
+	var fired firedTimers
+
+	sched := scheduler.New(store)
+	sched.Handle("test", fired.Handle)
+	sched.Restore()
+
+	time.Sleep(30 * time.Millisecond)
+
+	ids := fired.IDs()
+	require.Len(t, ids, 2)
+	assert.Contains(t, ids, "overdue")
+	assert.Contains(t, ids, "pending")
+
+	assert.Empty(t, store.GetTimers())
+}
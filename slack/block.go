@@ -0,0 +1,136 @@
+package slack
+
+// Block is a Block Kit layout block. Only the section, divider, actions and input blocks are supported.
+// See https://api.slack.com/reference/block-kit/blocks for details.
+type Block struct {
+	Type     string         `json:"type"`
+	BlockID  string         `json:"block_id,omitempty"`
+	Text     *TextObject    `json:"text,omitempty"`
+	Elements []BlockElement `json:"elements,omitempty"`
+	// Label, Element and Optional are only used by input blocks
+	Label    *TextObject   `json:"label,omitempty"`
+	Element  *BlockElement `json:"element,omitempty"`
+	Optional bool          `json:"optional,omitempty"`
+}
+
+// TextObject is a Block Kit composition object used to display text in blocks and elements.
+type TextObject struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// BlockElement is an interactive element of an actions or an input block. Buttons, plain text inputs, static
+// selects and multi-user selects are supported.
+type BlockElement struct {
+	Type          string      `json:"type"`
+	ActionID      string      `json:"action_id"`
+	Text          *TextObject `json:"text,omitempty"`
+	Value         string      `json:"value,omitempty"`
+	Style         string      `json:"style,omitempty"`
+	Placeholder   *TextObject `json:"placeholder,omitempty"`
+	InitialValue  string      `json:"initial_value,omitempty"`
+	Multiline     bool        `json:"multiline,omitempty"`
+	Options       []Option    `json:"options,omitempty"`
+	InitialOption *Option     `json:"initial_option,omitempty"`
+}
+
+// Option is an item of a select menu.
+type Option struct {
+	Text  *TextObject `json:"text"`
+	Value string      `json:"value"`
+}
+
+// Button styles
+const (
+	ButtonStylePrimary = "primary"
+	ButtonStyleDanger  = "danger"
+)
+
+func NewSectionBlock(text string) Block {
+	return Block{
+		Type: "section",
+		Text: &TextObject{Type: "mrkdwn", Text: text},
+	}
+}
+
+// NewDividerBlock returns a block that visually separates other blocks.
+func NewDividerBlock() Block {
+	return Block{Type: "divider"}
+}
+
+func NewActionsBlock(blockID string, elements ...BlockElement) Block {
+	return Block{
+		Type:     "actions",
+		BlockID:  blockID,
+		Elements: elements,
+	}
+}
+
+// NewButton returns a button element. The actionID and value are sent back to the app within
+// the interaction payload once the button is clicked.
+func NewButton(actionID, text, value, style string) BlockElement {
+	return BlockElement{
+		Type:     "button",
+		ActionID: actionID,
+		Text:     &TextObject{Type: "plain_text", Text: text},
+		Value:    value,
+		Style:    style,
+	}
+}
+
+// NewInputBlock returns a block that collects user input in modals.
+func NewInputBlock(blockID, label string, element BlockElement, optional bool) Block {
+	return Block{
+		Type:     "input",
+		BlockID:  blockID,
+		Label:    &TextObject{Type: "plain_text", Text: label},
+		Element:  &element,
+		Optional: optional,
+	}
+}
+
+// NewTextInput returns a plain text input element. The placeholder is omitted if empty.
+func NewTextInput(actionID, placeholder string, multiline bool) BlockElement {
+	el := BlockElement{
+		Type:      "plain_text_input",
+		ActionID:  actionID,
+		Multiline: multiline,
+	}
+
+	if placeholder != "" {
+		el.Placeholder = &TextObject{Type: "plain_text", Text: placeholder}
+	}
+
+	return el
+}
+
+// NewStaticSelect returns a select menu element with given options, the first one is selected by default.
+func NewStaticSelect(actionID string, options ...Option) BlockElement {
+	el := BlockElement{
+		Type:     "static_select",
+		ActionID: actionID,
+		Options:  options,
+	}
+
+	if len(options) > 0 {
+		el.InitialOption = &options[0]
+	}
+
+	return el
+}
+
+// NewUsersSelect returns an element to select multiple users of the team.
+func NewUsersSelect(actionID, placeholder string) BlockElement {
+	return BlockElement{
+		Type:        "multi_users_select",
+		ActionID:    actionID,
+		Placeholder: &TextObject{Type: "plain_text", Text: placeholder},
+	}
+}
+
+func NewOption(text, value string) Option {
+	return Option{
+		Text:  &TextObject{Type: "plain_text", Text: text},
+		Value: value,
+	}
+}
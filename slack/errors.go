@@ -1,9 +1,14 @@
 package slack
 
 type NoSuchUserError struct {
+	ID   string
 	Name string
 }
 
 func (e NoSuchUserError) Error() string {
+	if e.Name == "" {
+		return "there is no user with ID '" + e.ID + "' in the team"
+	}
+
 	return "there is no user with username '" + e.Name + "' in the team"
 }
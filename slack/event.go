@@ -0,0 +1,83 @@
+package slack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Events API callback types
+const (
+	EventTypeURLVerification = "url_verification"
+	EventTypeCallback        = "event_callback"
+)
+
+// Inner event types
+const (
+	// EventTypeAppMention is sent when a user mentions the app in a message
+	EventTypeAppMention = "app_mention"
+	// EventTypeAppHomeOpened is sent when a user opens the app home
+	EventTypeAppHomeOpened = "app_home_opened"
+)
+
+// EventCallback is a request sent by Slack to the Events API endpoint.
+// See https://api.slack.com/apis/connections/events-api#receiving_events for details.
+type EventCallback struct {
+	Type  string `json:"type"`
+	Token string `json:"token"`
+	// Challenge is sent within url_verification requests and needs to be sent back
+	Challenge string `json:"challenge"`
+	TeamID    string `json:"team_id"`
+	EventID   string `json:"event_id"`
+	// Event is the inner event, its structure depends on the event type
+	Event json.RawMessage `json:"event"`
+}
+
+// EventType returns the type of inner event.
+func (c EventCallback) EventType() string {
+	var v struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(c.Event, &v)
+
+	return v.Type
+}
+
+// AppMentionEvent is sent when a user mentions the app in a channel or a thread.
+type AppMentionEvent struct {
+	User    string `json:"user"`
+	Text    string `json:"text"`
+	Channel string `json:"channel"`
+	TS      string `json:"ts"`
+	// ThreadTS is set if the app has been mentioned in a thread
+	ThreadTS string `json:"thread_ts,omitempty"`
+}
+
+// AppHomeOpenedEvent is sent when a user navigates to the app home.
+type AppHomeOpenedEvent struct {
+	User string `json:"user"`
+	// Tab is either "home" or "messages"
+	Tab string `json:"tab"`
+}
+
+// ParseEventCallback reads the Events API request body.
+func ParseEventCallback(r *http.Request) (EventCallback, error) {
+	var c EventCallback
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return c, fmt.Errorf("failed to read event callback (%s)", err)
+	}
+
+	if len(body) == 0 {
+		return c, errors.New("empty event callback")
+	}
+
+	if err := json.Unmarshal(body, &c); err != nil {
+		return c, fmt.Errorf("malformed event callback (%s)", err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,66 @@
+package slack_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEventCallback_URLVerification(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"token":"token1","challenge":"challenge1","type":"url_verification"}`))
+
+	c, err := slack.ParseEventCallback(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, slack.EventTypeURLVerification, c.Type)
+	assert.Equal(t, "token1", c.Token)
+	assert.Equal(t, "challenge1", c.Challenge)
+}
+
+func TestParseEventCallback_AppMention(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{
+		"token": "token1",
+		"team_id": "T1",
+		"type": "event_callback",
+		"event_id": "Ev1",
+		"event": {
+			"type": "app_mention",
+			"user": "U1",
+			"text": "<@U0BOT> status",
+			"ts": "1515449522.000016",
+			"channel": "C1",
+			"thread_ts": "1515449438.000011"
+		}
+	}`))
+
+	c, err := slack.ParseEventCallback(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, slack.EventTypeCallback, c.Type)
+	assert.Equal(t, "T1", c.TeamID)
+	assert.Equal(t, "Ev1", c.EventID)
+	assert.Equal(t, slack.EventTypeAppMention, c.EventType())
+
+	var e slack.AppMentionEvent
+	require.NoError(t, json.Unmarshal(c.Event, &e))
+	assert.Equal(t, slack.AppMentionEvent{
+		User:     "U1",
+		Text:     "<@U0BOT> status",
+		Channel:  "C1",
+		TS:       "1515449522.000016",
+		ThreadTS: "1515449438.000011",
+	}, e)
+}
+
+func TestParseEventCallback_Malformed(t *testing.T) {
+	for _, body := range []string{"", "{"} {
+		_, err := slack.ParseEventCallback(httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(body)))
+		assert.Error(t, err, body)
+	}
+}
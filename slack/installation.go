@@ -0,0 +1,105 @@
+package slack
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Installation is a bot token issued for a Slack workspace once the app has been installed into it via OAuth.
+type Installation struct {
+	TeamID      string
+	TeamName    string
+	BotUserID   string
+	AccessToken string
+	InstalledAt time.Time
+}
+
+// InstallationStore keeps the app installations.
+type InstallationStore interface {
+	GetInstallation(teamID string) (Installation, bool)
+	SetInstallation(inst Installation)
+}
+
+// WebAPIClients provides Web API clients authorized to act in a Slack workspace.
+type WebAPIClients interface {
+	// ForTeam returns the client for the workspace identified by teamID. The second value is false if the app
+	// has not been installed into this workspace.
+	ForTeam(teamID string) (*WebAPI, bool)
+}
+
+// ForTeam returns the client itself for any team. This lets a client created with a single token be used
+// wherever WebAPIClients are expected.
+func (api *WebAPI) ForTeam(string) (*WebAPI, bool) {
+	return api, true
+}
+
+// Installations provides Web API clients for the workspaces the app has been installed into.
+type Installations struct {
+	store InstallationStore
+	c     *http.Client
+
+	BaseURL string
+
+	mu        sync.Mutex
+	clients   map[string]*WebAPI
+	onReplace []func(teamID string)
+}
+
+func NewInstallations(store InstallationStore, httpClient *http.Client) *Installations {
+	return &Installations{
+		store:   store,
+		c:       httpClient,
+		BaseURL: SlackWebAPIEndpoint,
+		clients: make(map[string]*WebAPI),
+	}
+}
+
+func (inst *Installations) ForTeam(teamID string) (*WebAPI, bool) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if api, ok := inst.clients[teamID]; ok {
+		return api, true
+	}
+
+	installation, ok := inst.store.GetInstallation(teamID)
+	if !ok || installation.AccessToken == "" {
+		return nil, false
+	}
+
+	api := inst.newClient(installation.AccessToken)
+	inst.clients[teamID] = api
+
+	return api, true
+}
+
+// Add saves the installation replacing the previous one for the same workspace, if any. The functions registered
+// with OnReplace are called once the installation is saved.
+func (inst *Installations) Add(installation Installation) {
+	inst.mu.Lock()
+	inst.store.SetInstallation(installation)
+	delete(inst.clients, installation.TeamID)
+	onReplace := inst.onReplace
+	inst.mu.Unlock()
+
+	for _, f := range onReplace {
+		f(installation.TeamID)
+	}
+}
+
+// OnReplace registers f to be called with the team ID whenever an installation is added, so that anything created
+// with the previous client of the workspace can be dropped.
+func (inst *Installations) OnReplace(f func(teamID string)) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	inst.onReplace = append(inst.onReplace, f)
+}
+
+func (inst *Installations) newClient(token string) *WebAPI {
+	api := NewWebAPI(token, inst.c)
+	api.BaseURL = inst.BaseURL
+
+	return api
+}
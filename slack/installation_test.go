@@ -0,0 +1,81 @@
+package slack_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type installationStore map[string]slack.Installation
+
+func (s installationStore) GetInstallation(teamID string) (slack.Installation, bool) {
+	inst, ok := s[teamID]
+	return inst, ok
+}
+
+func (s installationStore) SetInstallation(inst slack.Installation) {
+	s[inst.TeamID] = inst
+}
+
+func TestWebAPI_ForTeam(t *testing.T) {
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+
+	client, ok := api.ForTeam("T1")
+	require.True(t, ok)
+	assert.True(t, api == client)
+}
+
+func TestInstallations_ForTeam(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var tokens []string
+	mux.HandleFunc("/conversations.setTopic", func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	store := installationStore{"T1": {TeamID: "T1", AccessToken: "xoxb-1"}}
+
+	installations := slack.NewInstallations(store, nil)
+	installations.BaseURL = baseURL
+
+	_, ok := installations.ForTeam("T2")
+	assert.False(t, ok)
+
+	api, ok := installations.ForTeam("T1")
+	require.True(t, ok)
+	require.NoError(t, api.SetConversationTopic("C1", "topic"))
+
+	cached, ok := installations.ForTeam("T1")
+	require.True(t, ok)
+	assert.True(t, api == cached)
+
+	// reinstalling the app replaces the token
+	installations.Add(slack.Installation{TeamID: "T1", AccessToken: "xoxb-2"})
+	assert.Equal(t, "xoxb-2", store["T1"].AccessToken)
+
+	api, ok = installations.ForTeam("T1")
+	require.True(t, ok)
+	require.NoError(t, api.SetConversationTopic("C1", "topic"))
+
+	assert.Equal(t, []string{"Bearer xoxb-1", "Bearer xoxb-2"}, tokens)
+}
+
+func TestInstallations_OnReplace(t *testing.T) {
+	store := installationStore{"T1": {TeamID: "T1", AccessToken: "xoxb-1"}}
+	installations := slack.NewInstallations(store, nil)
+
+	var replaced []string
+	installations.OnReplace(func(teamID string) {
+		replaced = append(replaced, teamID)
+	})
+
+	installations.Add(slack.Installation{TeamID: "T1", AccessToken: "xoxb-2"})
+	installations.Add(slack.Installation{TeamID: "T2", AccessToken: "xoxb-3"})
+
+	assert.Equal(t, []string{"T1", "T2"}, replaced)
+}
@@ -1,10 +1,13 @@
 package slack
 
-import "sync"
+import (
+	"context"
+	"sync"
+)
 
 type messageSender interface {
-	OpenIMChannel(User) (string, error)
-	PostMessage(string, Message) error
+	OpenIMChannelContext(context.Context, User) (string, error)
+	PostMessageContext(context.Context, string, Message) error
 }
 
 type InstantMessenger struct {
@@ -22,17 +25,22 @@ func NewInstantMessenger(api messageSender) *InstantMessenger {
 }
 
 func (im *InstantMessenger) SendMessage(user User, message Message) error {
-	channelID, err := im.openChannel(user)
+	return im.SendMessageContext(context.Background(), user, message)
+}
+
+// SendMessageContext sends a direct message to user. The IM channel is opened once and reused for later messages.
+func (im *InstantMessenger) SendMessageContext(ctx context.Context, user User, message Message) error {
+	channelID, err := im.openChannel(ctx, user)
 	if err != nil {
 		return err
 	}
 
-	return im.api.PostMessage(channelID, message)
+	return im.api.PostMessageContext(ctx, channelID, message)
 }
 
-func (im *InstantMessenger) openChannel(user User) (string, error) {
+func (im *InstantMessenger) openChannel(ctx context.Context, user User) (string, error) {
 	im.mu.RLock()
-	channelID, ok := im.channels[user.Name]
+	channelID, ok := im.channels[user.ID]
 	im.mu.RUnlock()
 
 	if ok {
@@ -42,17 +50,17 @@ func (im *InstantMessenger) openChannel(user User) (string, error) {
 	im.mu.Lock()
 	defer im.mu.Unlock()
 
-	channelID, ok = im.channels[user.Name]
+	channelID, ok = im.channels[user.ID]
 	if ok {
 		return channelID, nil
 	}
 
-	channelID, err := im.api.OpenIMChannel(user)
+	channelID, err := im.api.OpenIMChannelContext(ctx, user)
 	if err != nil {
 		return "", err
 	}
 
-	im.channels[user.Name] = channelID
+	im.channels[user.ID] = channelID
 
-	return im.channels[user.Name], nil
+	return im.channels[user.ID], nil
 }
@@ -1,6 +1,7 @@
 package slack_test
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -15,19 +16,19 @@ func TestInstantMessenger_SendMessage(t *testing.T) {
 	message := slack.Message{Text: "text message"}
 
 	api := new(messageSenderMock)
-	api.On("OpenIMChannel", user).Return("channel1", nil)
-	api.On("PostMessage", "channel1", message).Return(nil)
+	api.On("OpenIMChannelContext", mock.Anything, user).Return("channel1", nil)
+	api.On("PostMessageContext", mock.Anything, "channel1", message).Return(nil)
 
 	im := slack.NewInstantMessenger(api)
 	require.NoError(t, im.SendMessage(user, message))
 
 	api.AssertExpectations(t)
-	api.AssertNumberOfCalls(t, "OpenIMChannel", 1)
-	api.AssertNumberOfCalls(t, "PostMessage", 1)
+	api.AssertNumberOfCalls(t, "OpenIMChannelContext", 1)
+	api.AssertNumberOfCalls(t, "PostMessageContext", 1)
 
 	require.NoError(t, im.SendMessage(user, message))
-	api.AssertNumberOfCalls(t, "OpenIMChannel", 1)
-	api.AssertNumberOfCalls(t, "PostMessage", 2)
+	api.AssertNumberOfCalls(t, "OpenIMChannelContext", 1)
+	api.AssertNumberOfCalls(t, "PostMessageContext", 2)
 }
 
 func TestInstantMessenger_SendMessage_OpenIMChannelError(t *testing.T) {
@@ -35,16 +36,16 @@ func TestInstantMessenger_SendMessage_OpenIMChannelError(t *testing.T) {
 	message := slack.Message{Text: "text message"}
 
 	api := new(messageSenderMock)
-	api.On("OpenIMChannel", user).Return("", errors.New("open_im_error"))
+	api.On("OpenIMChannelContext", mock.Anything, user).Return("", errors.New("open_im_error"))
 
 	im := slack.NewInstantMessenger(api)
 	assert.Error(t, im.SendMessage(user, message))
 
 	api.AssertExpectations(t)
-	api.AssertNumberOfCalls(t, "OpenIMChannel", 1)
+	api.AssertNumberOfCalls(t, "OpenIMChannelContext", 1)
 
 	assert.Error(t, im.SendMessage(user, message))
-	api.AssertNumberOfCalls(t, "OpenIMChannel", 2)
+	api.AssertNumberOfCalls(t, "OpenIMChannelContext", 2)
 }
 
 func TestInstantMessenger_SendMessage_PostMessageError(t *testing.T) {
@@ -52,31 +53,31 @@ func TestInstantMessenger_SendMessage_PostMessageError(t *testing.T) {
 	message := slack.Message{Text: "text message"}
 
 	api := new(messageSenderMock)
-	api.On("OpenIMChannel", user).Return("channel1", nil)
-	api.On("PostMessage", "channel1", message).Return(errors.New("post_message_error"))
+	api.On("OpenIMChannelContext", mock.Anything, user).Return("channel1", nil)
+	api.On("PostMessageContext", mock.Anything, "channel1", message).Return(errors.New("post_message_error"))
 
 	im := slack.NewInstantMessenger(api)
 	assert.Error(t, im.SendMessage(user, message))
 
 	api.AssertExpectations(t)
-	api.AssertNumberOfCalls(t, "OpenIMChannel", 1)
-	api.AssertNumberOfCalls(t, "PostMessage", 1)
+	api.AssertNumberOfCalls(t, "OpenIMChannelContext", 1)
+	api.AssertNumberOfCalls(t, "PostMessageContext", 1)
 
 	assert.Error(t, im.SendMessage(user, message))
-	api.AssertNumberOfCalls(t, "OpenIMChannel", 1)
-	api.AssertNumberOfCalls(t, "PostMessage", 2)
+	api.AssertNumberOfCalls(t, "OpenIMChannelContext", 1)
+	api.AssertNumberOfCalls(t, "PostMessageContext", 2)
 }
 
 type messageSenderMock struct {
 	mock.Mock
 }
 
-func (m *messageSenderMock) OpenIMChannel(user slack.User) (string, error) {
-	args := m.Called(user)
+func (m *messageSenderMock) OpenIMChannelContext(ctx context.Context, user slack.User) (string, error) {
+	args := m.Called(ctx, user)
 
 	return args.String(0), args.Error(1)
 }
 
-func (m *messageSenderMock) PostMessage(channelID string, message slack.Message) error {
-	return m.Called(channelID, message).Error(0)
+func (m *messageSenderMock) PostMessageContext(ctx context.Context, channelID string, message slack.Message) error {
+	return m.Called(ctx, channelID, message).Error(0)
 }
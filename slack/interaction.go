@@ -0,0 +1,107 @@
+package slack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Interaction payload types
+const (
+	// InteractionTypeBlockActions is the type of payload sent when a user clicks a Block Kit button
+	InteractionTypeBlockActions = "block_actions"
+	// InteractionTypeViewSubmission is the type of payload sent when a user submits a modal
+	InteractionTypeViewSubmission = "view_submission"
+)
+
+// Action is a user interaction with a Block Kit element.
+type Action struct {
+	ActionID string
+	BlockID  string
+	Value    string
+}
+
+// Interaction is a payload sent by Slack when a user interacts with a message.
+// See https://api.slack.com/reference/interaction-payloads/block-actions for details.
+type Interaction struct {
+	Type        string
+	Token       string
+	User        User
+	TeamID      string
+	ChannelID   string
+	ResponseURL string
+	// TriggerID can be used to open a modal in response to the interaction
+	TriggerID string
+	Actions   []Action
+	// View is the submitted modal
+	View View
+}
+
+type internalInteraction struct {
+	Type  string `json:"type"`
+	Token string `json:"token"`
+	User  struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Locale   string `json:"locale"`
+	} `json:"user"`
+	Team struct {
+		ID string `json:"id"`
+	} `json:"team"`
+	Channel struct {
+		ID string `json:"id"`
+	} `json:"channel"`
+	ResponseURL string `json:"response_url"`
+	TriggerID   string `json:"trigger_id"`
+	View        View   `json:"view"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		BlockID  string `json:"block_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+func (i *Interaction) UnmarshalJSON(data []byte) error {
+	var v internalInteraction
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	*i = Interaction{
+		Type:        v.Type,
+		Token:       v.Token,
+		User:        User{ID: v.User.ID, Name: v.User.Username, Locale: v.User.Locale},
+		TeamID:      v.Team.ID,
+		ChannelID:   v.Channel.ID,
+		ResponseURL: v.ResponseURL,
+		TriggerID:   v.TriggerID,
+		View:        v.View,
+	}
+
+	for _, a := range v.Actions {
+		i.Actions = append(i.Actions, Action{
+			ActionID: a.ActionID,
+			BlockID:  a.BlockID,
+			Value:    a.Value,
+		})
+	}
+
+	return nil
+}
+
+// ParseInteraction reads the interaction payload sent by Slack as a form value.
+func ParseInteraction(r *http.Request) (Interaction, error) {
+	var i Interaction
+
+	payload := r.PostFormValue("payload")
+	if payload == "" {
+		return i, errors.New("missing interaction payload")
+	}
+
+	if err := json.Unmarshal([]byte(payload), &i); err != nil {
+		return i, fmt.Errorf("malformed interaction payload (%s)", err)
+	}
+
+	return i, nil
+}
@@ -0,0 +1,101 @@
+package slack_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInteraction(t *testing.T) {
+	payload := `{
+		"type": "block_actions",
+		"token": "verification-token",
+		"user": {"id": "U123", "username": "user1", "name": "User One", "locale": "de-DE"},
+		"team": {"id": "T123", "domain": "team1"},
+		"channel": {"id": "C123", "name": "deploys"},
+		"response_url": "https://hooks.slack.com/actions/T1/1/xxx",
+		"actions": [{"action_id": "deploy_done", "block_id": "deploy", "value": "{}", "type": "button"}]
+	}`
+
+	req := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(url.Values{"payload": {payload}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	i, err := slack.ParseInteraction(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, slack.Interaction{
+		Type:        slack.InteractionTypeBlockActions,
+		Token:       "verification-token",
+		User:        slack.User{ID: "U123", Name: "user1", Locale: "de-DE"},
+		TeamID:      "T123",
+		ChannelID:   "C123",
+		ResponseURL: "https://hooks.slack.com/actions/T1/1/xxx",
+		Actions: []slack.Action{
+			{ActionID: "deploy_done", BlockID: "deploy", Value: "{}"},
+		},
+	}, i)
+}
+
+func TestParseInteraction_ViewSubmission(t *testing.T) {
+	payload := `{
+		"type": "view_submission",
+		"token": "verification-token",
+		"trigger_id": "trigger1",
+		"user": {"id": "U123", "username": "user1"},
+		"view": {
+			"type": "modal",
+			"callback_id": "modal1",
+			"private_metadata": "metadata",
+			"blocks": [],
+			"state": {
+				"values": {
+					"block1": {"action1": {"type": "plain_text_input", "value": "text"}},
+					"block2": {"action2": {"type": "static_select", "selected_option": {"text": {"type": "plain_text", "text": "Option"}, "value": "option1"}}},
+					"block3": {"action3": {"type": "multi_users_select", "selected_users": ["U1", "U2"]}}
+				}
+			}
+		}
+	}`
+
+	req := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(url.Values{"payload": {payload}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	i, err := slack.ParseInteraction(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, slack.InteractionTypeViewSubmission, i.Type)
+	assert.Equal(t, "trigger1", i.TriggerID)
+	assert.Equal(t, "modal1", i.View.CallbackID)
+	assert.Equal(t, "metadata", i.View.PrivateMetadata)
+
+	if v, ok := i.View.State.Value("block1", "action1"); assert.True(t, ok) {
+		assert.Equal(t, "text", v.Value)
+	}
+
+	if v, ok := i.View.State.Value("block2", "action2"); assert.True(t, ok) && assert.NotNil(t, v.SelectedOption) {
+		assert.Equal(t, "option1", v.SelectedOption.Value)
+	}
+
+	if v, ok := i.View.State.Value("block3", "action3"); assert.True(t, ok) {
+		assert.Equal(t, []string{"U1", "U2"}, v.SelectedUsers)
+	}
+
+	_, ok := i.View.State.Value("block1", "action2")
+	assert.False(t, ok)
+}
+
+func TestParseInteraction_Malformed(t *testing.T) {
+	for _, form := range []url.Values{{}, {"payload": {"{"}}} {
+		req := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		_, err := slack.ParseInteraction(req)
+		assert.Error(t, err, form.Encode())
+	}
+}
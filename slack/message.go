@@ -3,4 +3,8 @@ package slack
 type Message struct {
 	Text        string       `json:"text"`
 	Attachments []Attachment `json:"attachments,omitempty"`
+	// Blocks are shown instead of the message text, which is then used for notifications
+	Blocks []Block `json:"blocks,omitempty"`
+	// ThreadTS is the timestamp of a parent message to reply in thread
+	ThreadTS string `json:"thread_ts,omitempty"`
 }
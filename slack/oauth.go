@@ -0,0 +1,157 @@
+package slack
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SlackOAuthAuthorizeURL is the page where users approve the app installation into their workspace.
+const SlackOAuthAuthorizeURL = "https://slack.com/oauth/v2/authorize"
+
+const (
+	oauthStateCookie = "slack_oauth_state"
+	oauthStateMaxAge = 10 * time.Minute
+)
+
+// OAuthAccess exchanges the temporary code received by the OAuth callback for a bot token.
+func (api *WebAPI) OAuthAccess(clientID, clientSecret, code, redirectURL string) (Installation, error) {
+	const method = "oauth.v2.access"
+
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("client_secret", clientSecret)
+	params.Set("code", code)
+	params.Set("redirect_uri", redirectURL)
+
+	resp, requestURL, err := api.Call(method, params)
+	if err != nil {
+		return Installation{}, err
+	}
+
+	var v struct {
+		AccessToken string `json:"access_token"`
+		BotUserID   string `json:"bot_user_id"`
+		Team        struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"team"`
+	}
+	if err := json.Unmarshal(resp, &v); err != nil {
+		return Installation{}, wrapError(fmt.Errorf("failed to decode response body %q (%s)", resp, err), method, requestURL)
+	}
+
+	return Installation{
+		TeamID:      v.Team.ID,
+		TeamName:    v.Team.Name,
+		BotUserID:   v.BotUserID,
+		AccessToken: v.AccessToken,
+		InstalledAt: time.Now().UTC(),
+	}, nil
+}
+
+// OAuthHandler installs the app into Slack workspaces using the OAuth v2 flow. Users are sent to the install
+// endpoint, which redirects them to Slack to approve the installation. Slack then redirects them back to the callback
+// endpoint with a code that is exchanged for a bot token. See https://api.slack.com/authentication/oauth-v2 for details.
+type OAuthHandler struct {
+	clientID, clientSecret string
+	redirectURL            string
+	scopes                 []string
+	installations          *Installations
+
+	// AuthorizeURL is the Slack page users are redirected to by the install endpoint
+	AuthorizeURL string
+}
+
+// NewOAuthHandler returns a handler that saves bot tokens to installations. The redirectURL is the public URL
+// of the callback endpoint, i.e. https://michael.example.com/slack/oauth/callback, and should match the one
+// configured in the Slack app settings.
+func NewOAuthHandler(clientID, clientSecret, redirectURL string, scopes []string, installations *Installations) *OAuthHandler {
+	return &OAuthHandler{
+		clientID:      clientID,
+		clientSecret:  clientSecret,
+		redirectURL:   redirectURL,
+		scopes:        scopes,
+		installations: installations,
+		AuthorizeURL:  SlackOAuthAuthorizeURL,
+	}
+}
+
+// ServeInstall redirects user to Slack to approve the app installation.
+func (h *OAuthHandler) ServeInstall(w http.ResponseWriter, r *http.Request) {
+	state, err := newOAuthState()
+	if err != nil {
+		log.Printf("failed to generate OAuth state: %s", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	// the state is sent back to the callback by Slack and compared with the cookie to prevent CSRF
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/",
+		MaxAge:   int(oauthStateMaxAge / time.Second),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	params := url.Values{}
+	params.Set("client_id", h.clientID)
+	params.Set("scope", strings.Join(h.scopes, ","))
+	params.Set("redirect_uri", h.redirectURL)
+	params.Set("state", state)
+
+	http.Redirect(w, r, h.AuthorizeURL+"?"+params.Encode(), http.StatusFound)
+}
+
+// ServeCallback completes the installation and saves the bot token issued for the workspace.
+func (h *OAuthHandler) ServeCallback(w http.ResponseWriter, r *http.Request) {
+	if errCode := r.FormValue("error"); errCode != "" {
+		http.Error(w, "The app has not been installed: "+errCode, http.StatusForbidden)
+		return
+	}
+
+	cookie, err := r.Cookie(oauthStateCookie)
+	if err != nil || cookie.Value == "" || cookie.Value != r.FormValue("state") {
+		http.Error(w, "Invalid OAuth state, please start the installation again", http.StatusBadRequest)
+		return
+	}
+
+	code := r.FormValue("code")
+	if code == "" {
+		http.Error(w, "Missing OAuth code", http.StatusBadRequest)
+		return
+	}
+
+	installation, err := h.installations.newClient("").OAuthAccess(h.clientID, h.clientSecret, code, h.redirectURL)
+	if err != nil {
+		log.Printf("failed to complete the app installation: %s", err)
+		http.Error(w, "Failed to complete the installation", http.StatusBadGateway)
+		return
+	}
+
+	h.installations.Add(installation)
+	log.Printf("the app has been installed into %s (%s)", installation.TeamName, installation.TeamID)
+
+	// the state can only be used once
+	http.SetCookie(w, &http.Cookie{Name: oauthStateCookie, Path: "/", MaxAge: -1})
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "Michael has been installed into %s, type /deploy help in any channel to get started", installation.TeamName)
+}
+
+func newOAuthState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,116 @@
+package slack_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebAPI_OAuthAccess(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/oauth.v2.access", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "client1", r.FormValue("client_id"))
+		assert.Equal(t, "secret1", r.FormValue("client_secret"))
+		assert.Equal(t, "code1", r.FormValue("code"))
+		assert.Equal(t, "https://example.com/slack/oauth/callback", r.FormValue("redirect_uri"))
+		assert.Empty(t, r.Header.Get("Authorization"))
+
+		requestNum++
+		w.Write([]byte(`{"ok":true,"access_token":"xoxb-1","token_type":"bot","bot_user_id":"U0BOT","team":{"id":"T1","name":"Team 1"}}`))
+	})
+
+	api := slack.NewWebAPI("", nil)
+	api.BaseURL = baseURL
+
+	inst, err := api.OAuthAccess("client1", "secret1", "code1", "https://example.com/slack/oauth/callback")
+	require.NoError(t, err)
+	assert.Equal(t, 1, requestNum)
+
+	assert.Equal(t, "T1", inst.TeamID)
+	assert.Equal(t, "Team 1", inst.TeamName)
+	assert.Equal(t, "U0BOT", inst.BotUserID)
+	assert.Equal(t, "xoxb-1", inst.AccessToken)
+	assert.False(t, inst.InstalledAt.IsZero())
+}
+
+func TestOAuthHandler(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/oauth.v2.access", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "code1" {
+			w.Write([]byte(`{"ok":false,"error":"invalid_code"}`))
+			return
+		}
+
+		w.Write([]byte(`{"ok":true,"access_token":"xoxb-1","bot_user_id":"U0BOT","team":{"id":"T1","name":"Team 1"}}`))
+	})
+
+	store := installationStore{}
+
+	installations := slack.NewInstallations(store, nil)
+	installations.BaseURL = baseURL
+
+	h := slack.NewOAuthHandler("client1", "secret1", "https://example.com/slack/oauth/callback", []string{"commands", "chat:write"}, installations)
+
+	rec := httptest.NewRecorder()
+	h.ServeInstall(rec, httptest.NewRequest(http.MethodGet, "/slack/install", nil))
+	require.Equal(t, http.StatusFound, rec.Code)
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "slack.com", location.Host)
+	assert.Equal(t, "client1", location.Query().Get("client_id"))
+	assert.Equal(t, "commands,chat:write", location.Query().Get("scope"))
+	assert.Equal(t, "https://example.com/slack/oauth/callback", location.Query().Get("redirect_uri"))
+
+	state := location.Query().Get("state")
+	require.NotEmpty(t, state)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	callback := func(query string, cookie *http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/slack/oauth/callback?"+query, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeCallback(rec, req)
+
+		return rec
+	}
+
+	t.Run("state mismatch", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, callback("code=code1&state=another", cookies[0]).Code)
+		assert.Equal(t, http.StatusBadRequest, callback("code=code1&state="+state, nil).Code)
+	})
+
+	t.Run("installation denied", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, callback("error=access_denied&state="+state, cookies[0]).Code)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadGateway, callback("code=code2&state="+state, cookies[0]).Code)
+	})
+
+	assert.Empty(t, store)
+
+	rec = callback("code=code1&state="+state, cookies[0])
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.Contains(rec.Body.String(), "Team 1"), rec.Body.String())
+
+	if inst, ok := store["T1"]; assert.True(t, ok) {
+		assert.Equal(t, "xoxb-1", inst.AccessToken)
+	}
+}
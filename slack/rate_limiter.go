@@ -0,0 +1,144 @@
+package slack
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Slack Web API rate limit tiers, see https://api.slack.com/docs/rate-limits. The value is the number of
+// requests per minute allowed for a method.
+const (
+	RateTier1 = 1
+	RateTier2 = 20
+	RateTier3 = 50
+	RateTier4 = 100
+	// RateTierPostMessage is the limit of chat.postMessage that allows roughly one message per second
+	RateTierPostMessage = 60
+)
+
+// MethodRateTiers maps Web API methods to their rate limit tier. Methods that are not listed here are
+// limited as RateTier3.
+var MethodRateTiers = map[string]int{
+	"chat.postMessage":       RateTierPostMessage,
+	"chat.postEphemeral":     RateTier4,
+	"chat.update":            RateTier3,
+	"conversations.info":     RateTier3,
+	"conversations.open":     RateTier3,
+	"conversations.setTopic": RateTier2,
+	"oauth.v2.access":        RateTier4,
+	"usergroups.users.list":  RateTier2,
+	"users.conversations":    RateTier3,
+	"users.info":             RateTier4,
+	"users.list":             RateTier2,
+	"views.open":             RateTier4,
+	"views.publish":          RateTier4,
+}
+
+// rateLimiter is a per-method token bucket limiter. Each bucket holds up to a minute worth of requests, so
+// short bursts are sent right away while the sustained rate stays within the method tier.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+}
+
+type rateBucket struct {
+	limit        int
+	tokens       float64
+	updatedAt    time.Time
+	blockedUntil time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets: make(map[string]*rateBucket),
+	}
+}
+
+// Wait blocks until a request to method is allowed or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context, method string) error {
+	for {
+		delay := l.reserve(method, time.Now())
+		if delay <= 0 {
+			return nil
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// Pause holds all requests to method for duration d, i.e. for the time requested by Slack in Retry-After header.
+func (l *rateLimiter) Pause(method string, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	b := l.bucket(method, now)
+	if until := now.Add(d); until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+}
+
+// reserve takes a token from the method bucket and returns zero, or returns the time to wait until
+// the next token is available.
+func (l *rateLimiter) reserve(method string, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b := l.bucket(method, now)
+	if now.Before(b.blockedUntil) {
+		return b.blockedUntil.Sub(now)
+	}
+
+	interval := time.Minute / time.Duration(b.limit)
+
+	b.tokens += float64(now.Sub(b.updatedAt)) / float64(interval)
+	if b.tokens > float64(b.limit) {
+		b.tokens = float64(b.limit)
+	}
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) * float64(interval))
+	}
+
+	b.tokens--
+
+	return 0
+}
+
+func (l *rateLimiter) bucket(method string, now time.Time) *rateBucket {
+	if b, ok := l.buckets[method]; ok {
+		return b
+	}
+
+	limit, ok := MethodRateTiers[method]
+	if !ok {
+		limit = RateTier3
+	}
+
+	b := &rateBucket{
+		limit:     limit,
+		tokens:    float64(limit),
+		updatedAt: now,
+	}
+	l.buckets[method] = b
+
+	return b
+}
+
+// sleep pauses the current goroutine for duration d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
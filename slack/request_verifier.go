@@ -0,0 +1,140 @@
+package slack
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultMaxRequestAge is the maximum age of a signed request accepted by RequestVerifier
+	DefaultMaxRequestAge = 5 * time.Minute
+
+	signatureHeader          = "X-Slack-Signature"
+	signatureTimestampHeader = "X-Slack-Request-Timestamp"
+	signatureVersion         = "v0"
+
+	maxRequestBodySize = 1 << 20
+)
+
+// RequestVerifier is an http.Handler that checks whether the request has been sent by Slack before passing it to the
+// underlying handler.
+type RequestVerifier struct {
+	handler           http.Handler
+	signingSecret     []byte
+	verificationToken string
+
+	// MaxRequestAge limits the time frame in which a signed request can be replayed
+	MaxRequestAge time.Duration
+}
+
+// RequestVerificationMiddleware returns a handler that calls h only for requests signed with signingSecret. If
+// verificationToken is not empty, unsigned requests that contain this legacy token are accepted as well. See
+// https://api.slack.com/authentication/verifying-requests-from-slack for details.
+func RequestVerificationMiddleware(h http.Handler, signingSecret []byte, verificationToken string) *RequestVerifier {
+	return &RequestVerifier{
+		handler:           h,
+		signingSecret:     signingSecret,
+		verificationToken: verificationToken,
+		MaxRequestAge:     DefaultMaxRequestAge,
+	}
+}
+
+func (v *RequestVerifier) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodySize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
+	r.Body.Close()
+
+	// let the underlying handler read the body again
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	switch {
+	case len(v.signingSecret) > 0 && r.Header.Get(signatureHeader) != "":
+		if err := v.verifySignature(r.Header, body, time.Now()); err != nil {
+			log.Printf("rejected %s request to %s: %s", r.Method, r.URL.Path, err)
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+	case v.verificationToken != "":
+		if requestToken(body) != v.verificationToken {
+			http.Error(w, "Invalid token", http.StatusForbidden)
+			return
+		}
+	default:
+		http.Error(w, "Missing signature", http.StatusUnauthorized)
+		return
+	}
+
+	v.handler.ServeHTTP(w, r)
+}
+
+func (v *RequestVerifier) verifySignature(header http.Header, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(header.Get(signatureTimestampHeader), 10, 64)
+	if err != nil {
+		return errors.New("malformed request timestamp")
+	}
+
+	if age := now.Sub(time.Unix(ts, 0)); age > v.MaxRequestAge || age < -v.MaxRequestAge {
+		return errors.New("request timestamp is too far from current time")
+	}
+
+	signature, err := hex.DecodeString(strings.TrimPrefix(header.Get(signatureHeader), signatureVersion+"="))
+	if err != nil {
+		return errors.New("malformed signature")
+	}
+
+	if !hmac.Equal(signature, SignRequest(v.signingSecret, ts, body)) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
+
+// SignRequest returns the signature that Slack would send along with a request body sent at ts.
+func SignRequest(signingSecret []byte, ts int64, body []byte) []byte {
+	mac := hmac.New(sha256.New, signingSecret)
+	mac.Write([]byte(signatureVersion + ":" + strconv.FormatInt(ts, 10) + ":"))
+	mac.Write(body)
+
+	return mac.Sum(nil)
+}
+
+// requestToken returns the legacy verification token sent within the form body of a slash command,
+// inside of an interaction payload or an event callback.
+func requestToken(body []byte) string {
+	var payload struct {
+		Token string `json:"token"`
+	}
+
+	if err := json.Unmarshal(body, &payload); err == nil {
+		return payload.Token
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ""
+	}
+
+	if token := form.Get("token"); token != "" {
+		return token
+	}
+
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		return ""
+	}
+
+	return payload.Token
+}
@@ -0,0 +1,96 @@
+package slack_test
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adjust/michaelbot/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestVerifier_ServeHTTP(t *testing.T) {
+	signingSecret := []byte("signing-secret")
+	body := url.Values{"token": {"legacy-token"}, "text": {"status"}}.Encode()
+
+	signedRequest := func(ts time.Time, secret []byte) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("X-Slack-Request-Timestamp", strconv.FormatInt(ts.Unix(), 10))
+		req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(slack.SignRequest(secret, ts.Unix(), []byte(body))))
+
+		return req
+	}
+
+	examples := map[string]struct {
+		Request           *http.Request
+		VerificationToken string
+		ExpectedCode      int
+	}{
+		"valid signature": {
+			Request:      signedRequest(time.Now(), signingSecret),
+			ExpectedCode: http.StatusOK,
+		},
+		"invalid signature": {
+			Request:           signedRequest(time.Now(), []byte("another-secret")),
+			VerificationToken: "legacy-token",
+			ExpectedCode:      http.StatusUnauthorized,
+		},
+		"replayed request": {
+			Request:      signedRequest(time.Now().Add(-10*time.Minute), signingSecret),
+			ExpectedCode: http.StatusUnauthorized,
+		},
+		"unsigned request": {
+			Request:      httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(body)),
+			ExpectedCode: http.StatusUnauthorized,
+		},
+		"unsigned request with legacy token": {
+			Request:           httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(body)),
+			VerificationToken: "legacy-token",
+			ExpectedCode:      http.StatusOK,
+		},
+		"unsigned request with invalid legacy token": {
+			Request:           httptest.NewRequest(http.MethodPost, "/deploy", strings.NewReader(body)),
+			VerificationToken: "another-token",
+			ExpectedCode:      http.StatusForbidden,
+		},
+		"unsigned interaction with legacy token": {
+			Request: httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(url.Values{
+				"payload": {`{"type":"block_actions","token":"legacy-token"}`},
+			}.Encode())),
+			VerificationToken: "legacy-token",
+			ExpectedCode:      http.StatusOK,
+		},
+		"unsigned event with legacy token": {
+			Request:           httptest.NewRequest(http.MethodPost, "/events", strings.NewReader(`{"type":"event_callback","token":"legacy-token"}`)),
+			VerificationToken: "legacy-token",
+			ExpectedCode:      http.StatusOK,
+		},
+	}
+
+	for name, example := range examples {
+		t.Run(name, func(t *testing.T) {
+			var receivedBody string
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := ioutil.ReadAll(r.Body)
+				receivedBody = string(data)
+			})
+
+			rec := httptest.NewRecorder()
+			slack.RequestVerificationMiddleware(h, signingSecret, example.VerificationToken).ServeHTTP(rec, example.Request)
+
+			assert.Equal(t, example.ExpectedCode, rec.Code)
+			if example.ExpectedCode == http.StatusOK {
+				assert.NotEmpty(t, receivedBody)
+			} else {
+				assert.Empty(t, receivedBody)
+			}
+		})
+	}
+}
@@ -1,55 +1,269 @@
 package slack
 
 import (
+	"context"
 	"fmt"
 	"sync"
+	"time"
+)
+
+const (
+	// DefaultTeamDirectoryTTL is the time after which the team directory fetches the user list again
+	DefaultTeamDirectoryTTL = time.Hour
+	// DefaultTeamDirectoryMissRefreshInterval is the minimum time between user list fetches caused by lookups
+	// of unknown users
+	DefaultTeamDirectoryMissRefreshInterval = time.Minute
+	// DefaultTeamDirectoryFetchTimeout limits the time spent on fetching the user list or user group members
+	DefaultTeamDirectoryFetchTimeout = 30 * time.Second
 )
 
 type userLister interface {
-	ListUsers() ([]User, error)
+	ListUsersContext(ctx context.Context) ([]User, error)
+	ListUserGroupMembersContext(ctx context.Context, groupID string) ([]string, error)
+}
+
+type userGroup struct {
+	members   []string
+	fetchedAt time.Time
 }
 
+// TeamDirectory is a cache of workspace members that allows to look users up by ID and by username. The user list is
+// fetched again once TTL is over, so that renamed and deactivated users are eventually picked up. Known users are
+// looked up in the outdated list while it is being fetched. User group memberships are cached for the same time.
 type TeamDirectory struct {
 	api userLister
 
-	mu    sync.RWMutex
-	cache map[string]User
+	// TTL is the time after which the user list is fetched again
+	TTL time.Duration
+	// MissRefreshInterval limits how often a lookup of an unknown user causes the user list to be fetched again.
+	// Fetching the user list of a big workspace takes several requests, so it should not be done for every typo.
+	MissRefreshInterval time.Duration
+	// FetchTimeout limits the time spent on fetching the user list or user group members
+	FetchTimeout time.Duration
+
+	mu        sync.RWMutex
+	byID      map[string]User
+	byName    map[string]User
+	fetchedAt time.Time
+
+	rmu        sync.Mutex
+	refreshing *directoryRefresh
+
+	gmu    sync.Mutex
+	groups map[string]userGroup
 }
 
 func NewTeamDirectory(api userLister) *TeamDirectory {
-	return &TeamDirectory{api: api, cache: make(map[string]User)}
+	return &TeamDirectory{
+		api:                 api,
+		TTL:                 DefaultTeamDirectoryTTL,
+		MissRefreshInterval: DefaultTeamDirectoryMissRefreshInterval,
+		FetchTimeout:        DefaultTeamDirectoryFetchTimeout,
+		byID:                make(map[string]User),
+		byName:              make(map[string]User),
+		groups:              make(map[string]userGroup),
+	}
 }
 
+// Fetch returns an active team member by username. Deactivated users are reported as NoSuchUserError.
 func (dir *TeamDirectory) Fetch(username string) (User, error) {
-	dir.mu.RLock()
-	user, ok := dir.cache[username]
-	dir.mu.RUnlock()
+	user, ok, err := dir.lookup(usersByName, username)
+	if err != nil {
+		return User{}, err
+	}
 
-	if ok {
-		return user, nil
+	if !ok {
+		return User{}, NoSuchUserError{Name: username}
 	}
 
-	dir.mu.Lock()
-	defer dir.mu.Unlock()
+	return user, nil
+}
+
+// FetchByID returns an active team member by user ID with their current username. Deactivated users are
+// reported as NoSuchUserError.
+func (dir *TeamDirectory) FetchByID(userID string) (User, error) {
+	user, ok, err := dir.lookup(usersByID, userID)
+	if err != nil {
+		return User{}, err
+	}
 
-	user, ok = dir.cache[username]
 	if !ok {
-		users, err := dir.api.ListUsers()
+		return User{}, NoSuchUserError{ID: userID}
+	}
+
+	return user, nil
+}
+
+// GroupMembers returns active members of the user group identified by groupID.
+func (dir *TeamDirectory) GroupMembers(groupID string) ([]User, error) {
+	memberIDs, err := dir.groupMemberIDs(groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []User
+	for _, id := range memberIDs {
+		user, err := dir.FetchByID(id)
 		if err != nil {
-			return User{}, fmt.Errorf("failed to fetch team users: %s", err)
+			if _, ok := err.(NoSuchUserError); ok {
+				continue
+			}
+
+			return nil, err
 		}
 
-		dir.cache = make(map[string]User, len(users))
-		for _, user := range users {
-			dir.cache[user.Name] = user
+		members = append(members, user)
+	}
+
+	return members, nil
+}
+
+func (dir *TeamDirectory) groupMemberIDs(groupID string) ([]string, error) {
+	dir.gmu.Lock()
+	group, ok := dir.groups[groupID]
+	dir.gmu.Unlock()
+
+	now := time.Now()
+	if ok && (dir.TTL <= 0 || now.Sub(group.fetchedAt) < dir.TTL) {
+		return group.members, nil
+	}
+
+	ctx, cancel := dir.fetchContext()
+	defer cancel()
+
+	members, err := dir.api.ListUserGroupMembersContext(ctx, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch members of user group %s: %s", groupID, err)
+	}
+
+	dir.gmu.Lock()
+	dir.groups[groupID] = userGroup{members: members, fetchedAt: now}
+	dir.gmu.Unlock()
+
+	return members, nil
+}
+
+// lookup finds an active user by key in the directory index, refreshing the directory if its TTL is over
+// or if the user is missing. Users found in an expired index are returned right away while the directory
+// is being refreshed in background.
+func (dir *TeamDirectory) lookup(index func(dir *TeamDirectory) map[string]User, key string) (User, bool, error) {
+	now := time.Now()
+
+	dir.mu.RLock()
+	user, ok := index(dir)[key]
+	expired := dir.expired(now)
+	missRefreshDue := now.Sub(dir.fetchedAt) >= dir.MissRefreshInterval
+	dir.mu.RUnlock()
+
+	if ok {
+		if expired {
+			dir.refresh()
 		}
 
-		user, ok = dir.cache[username]
+		return user, !user.Deleted, nil
 	}
 
-	if !ok {
-		return User{}, NoSuchUserError{Name: username}
+	if !expired && !missRefreshDue {
+		return User{}, false, nil
 	}
 
-	return user, nil
+	r := dir.refresh()
+	<-r.done
+
+	if r.err != nil {
+		return User{}, false, r.err
+	}
+
+	dir.mu.RLock()
+	user, ok = index(dir)[key]
+	dir.mu.RUnlock()
+
+	return user, ok && !user.Deleted, nil
+}
+
+func (dir *TeamDirectory) expired(now time.Time) bool {
+	if dir.fetchedAt.IsZero() {
+		return true
+	}
+
+	return dir.TTL > 0 && now.Sub(dir.fetchedAt) >= dir.TTL
+}
+
+// directoryRefresh is a user list fetch shared by all lookups made while it is running
+type directoryRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// refresh starts fetching the user list unless it is already being fetched. The returned refresh is done once
+// the directory contents have been replaced with the current user list or the fetch has failed.
+func (dir *TeamDirectory) refresh() *directoryRefresh {
+	dir.rmu.Lock()
+	defer dir.rmu.Unlock()
+
+	if dir.refreshing != nil {
+		return dir.refreshing
+	}
+
+	r := &directoryRefresh{done: make(chan struct{})}
+	dir.refreshing = r
+
+	go func() {
+		r.err = dir.fetch()
+
+		dir.rmu.Lock()
+		dir.refreshing = nil
+		dir.rmu.Unlock()
+
+		close(r.done)
+	}()
+
+	return r
+}
+
+// fetch replaces the directory contents with the current user list. The directory is not locked while the list
+// is being fetched.
+func (dir *TeamDirectory) fetch() error {
+	now := time.Now()
+
+	ctx, cancel := dir.fetchContext()
+	defer cancel()
+
+	users, err := dir.api.ListUsersContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch team users: %s", err)
+	}
+
+	byID := make(map[string]User, len(users))
+	byName := make(map[string]User, len(users))
+	for _, user := range users {
+		byID[user.ID] = user
+
+		// the username of a deactivated user might have been taken by someone else
+		if existing, ok := byName[user.Name]; !ok || existing.Deleted {
+			byName[user.Name] = user
+		}
+	}
+
+	dir.mu.Lock()
+	dir.byID, dir.byName, dir.fetchedAt = byID, byName, now
+	dir.mu.Unlock()
+
+	return nil
+}
+
+func (dir *TeamDirectory) fetchContext() (context.Context, context.CancelFunc) {
+	if dir.FetchTimeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), dir.FetchTimeout)
+}
+
+func usersByID(dir *TeamDirectory) map[string]User {
+	return dir.byID
+}
+
+func usersByName(dir *TeamDirectory) map[string]User {
+	return dir.byName
 }
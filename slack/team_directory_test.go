@@ -1,8 +1,10 @@
 package slack_test
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/adjust/michaelbot/slack"
 	"github.com/stretchr/testify/assert"
@@ -17,7 +19,7 @@ func TestTeamDirectory_Fetch_ExistingUser(t *testing.T) {
 	}
 
 	api := new(apiMock)
-	api.On("ListUsers").Return(team, nil)
+	api.On("ListUsersContext", mock.Anything).Return(team, nil)
 
 	users := slack.NewTeamDirectory(api)
 
@@ -29,7 +31,7 @@ func TestTeamDirectory_Fetch_ExistingUser(t *testing.T) {
 	}
 
 	api.AssertExpectations(t)
-	api.AssertNumberOfCalls(t, "ListUsers", 1)
+	api.AssertNumberOfCalls(t, "ListUsersContext", 1)
 }
 
 func TestTeamDirectory_Fetch_NonExistingUser(t *testing.T) {
@@ -38,7 +40,7 @@ func TestTeamDirectory_Fetch_NonExistingUser(t *testing.T) {
 	}
 
 	api := new(apiMock)
-	api.On("ListUsers").Return(team, nil)
+	api.On("ListUsersContext", mock.Anything).Return(team, nil)
 
 	users := slack.NewTeamDirectory(api)
 
@@ -47,12 +49,12 @@ func TestTeamDirectory_Fetch_NonExistingUser(t *testing.T) {
 	assert.EqualError(t, err, "there is no user with username 'user2' in the team")
 
 	api.AssertExpectations(t)
-	api.AssertNumberOfCalls(t, "ListUsers", 1)
+	api.AssertNumberOfCalls(t, "ListUsersContext", 1)
 }
 
 func TestTeamDirectory_Fetch_WebAPIError(t *testing.T) {
 	api := new(apiMock)
-	api.On("ListUsers").Return(nil, errors.New("Slack Web API has returned an error"))
+	api.On("ListUsersContext", mock.Anything).Return(nil, errors.New("Slack Web API has returned an error"))
 
 	users := slack.NewTeamDirectory(api)
 
@@ -60,15 +62,164 @@ func TestTeamDirectory_Fetch_WebAPIError(t *testing.T) {
 	assert.EqualError(t, err, "failed to fetch team users: Slack Web API has returned an error")
 
 	api.AssertExpectations(t)
-	api.AssertNumberOfCalls(t, "ListUsers", 1)
+	api.AssertNumberOfCalls(t, "ListUsersContext", 1)
+}
+
+func TestTeamDirectory_FetchByID(t *testing.T) {
+	api := new(apiMock)
+	api.On("ListUsersContext", mock.Anything).Return([]slack.User{
+		{ID: "U1", Name: "user1"},
+		{ID: "U2", Name: "user2", Deleted: true},
+	}, nil)
+
+	users := slack.NewTeamDirectory(api)
+
+	user, err := users.FetchByID("U1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, slack.User{ID: "U1", Name: "user1"}, user)
+	}
+
+	_, err = users.FetchByID("U2")
+	assert.EqualError(t, err, "there is no user with ID 'U2' in the team")
+
+	_, err = users.Fetch("user2")
+	assert.IsType(t, slack.NoSuchUserError{}, err)
+
+	api.AssertExpectations(t)
+	api.AssertNumberOfCalls(t, "ListUsersContext", 1)
+}
+
+func TestTeamDirectory_Fetch_TTL(t *testing.T) {
+	api := new(apiMock)
+	api.On("ListUsersContext", mock.Anything).Return([]slack.User{{ID: "U1", Name: "user1"}}, nil).Once()
+	api.On("ListUsersContext", mock.Anything).Return([]slack.User{{ID: "U1", Name: "renamed1"}}, nil).Once()
+
+	users := slack.NewTeamDirectory(api)
+	users.TTL = 50 * time.Millisecond
+
+	_, err := users.Fetch("user1")
+	assert.NoError(t, err)
+
+	time.Sleep(60 * time.Millisecond)
+
+	// the outdated list is used until the new one is fetched
+	_, err = users.Fetch("user1")
+	assert.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = users.Fetch("user1")
+	assert.IsType(t, slack.NoSuchUserError{}, err)
+
+	user, err := users.FetchByID("U1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "renamed1", user.Name)
+	}
+
+	api.AssertExpectations(t)
+	api.AssertNumberOfCalls(t, "ListUsersContext", 2)
+}
+
+func TestTeamDirectory_Fetch_SlowRefresh(t *testing.T) {
+	release := make(chan time.Time)
+
+	api := new(apiMock)
+	api.On("ListUsersContext", mock.Anything).Return([]slack.User{{ID: "U1", Name: "user1"}}, nil).Once()
+	api.On("ListUsersContext", mock.Anything).Return([]slack.User{{ID: "U1", Name: "user1"}, {ID: "U2", Name: "user2"}}, nil).WaitUntil(release).Once()
+
+	users := slack.NewTeamDirectory(api)
+	users.TTL = 10 * time.Millisecond
+
+	_, err := users.Fetch("user1")
+	assert.NoError(t, err)
+
+	time.Sleep(20 * time.Millisecond)
+
+	// known users are looked up while the list is being fetched
+	for i := 0; i < 3; i++ {
+		user, err := users.FetchByID("U1")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "user1", user.Name)
+		}
+	}
+
+	// unknown users are looked up once the list has been fetched
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+	}()
+
+	user, err := users.Fetch("user2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "U2", user.ID)
+	}
+
+	api.AssertExpectations(t)
+	api.AssertNumberOfCalls(t, "ListUsersContext", 2)
+}
+
+func TestTeamDirectory_Fetch_MissRefreshInterval(t *testing.T) {
+	api := new(apiMock)
+	api.On("ListUsersContext", mock.Anything).Return([]slack.User{{ID: "U1", Name: "user1"}}, nil).Once()
+	api.On("ListUsersContext", mock.Anything).Return([]slack.User{{ID: "U1", Name: "user1"}, {ID: "U2", Name: "user2"}}, nil).Once()
+
+	users := slack.NewTeamDirectory(api)
+	users.MissRefreshInterval = 10 * time.Millisecond
+
+	_, err := users.Fetch("user2")
+	assert.IsType(t, slack.NoSuchUserError{}, err)
+
+	// the list has just been fetched
+	_, err = users.Fetch("user2")
+	assert.IsType(t, slack.NoSuchUserError{}, err)
+	api.AssertNumberOfCalls(t, "ListUsersContext", 1)
+
+	time.Sleep(20 * time.Millisecond)
+
+	user, err := users.Fetch("user2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "U2", user.ID)
+	}
+
+	api.AssertExpectations(t)
+	api.AssertNumberOfCalls(t, "ListUsersContext", 2)
+}
+
+func TestTeamDirectory_GroupMembers(t *testing.T) {
+	api := new(apiMock)
+	api.On("ListUsersContext", mock.Anything).Return([]slack.User{
+		{ID: "U1", Name: "user1"},
+		{ID: "U2", Name: "user2", Deleted: true},
+		{ID: "U3", Name: "user3"},
+	}, nil)
+	api.On("ListUserGroupMembersContext", mock.Anything, "S1").Return([]string{"U1", "U2", "U3"}, nil)
+	api.On("ListUserGroupMembersContext", mock.Anything, "S2").Return(nil, errors.New("Slack Web API has returned an error"))
+
+	users := slack.NewTeamDirectory(api)
+
+	members, err := users.GroupMembers("S1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []slack.User{{ID: "U1", Name: "user1"}, {ID: "U3", Name: "user3"}}, members)
+	}
+
+	// group members are cached
+	_, err = users.GroupMembers("S1")
+	assert.NoError(t, err)
+
+	_, err = users.GroupMembers("S2")
+	assert.EqualError(t, err, "failed to fetch members of user group S2: Slack Web API has returned an error")
+
+	api.AssertExpectations(t)
+	api.AssertNumberOfCalls(t, "ListUsersContext", 1)
+	api.AssertNumberOfCalls(t, "ListUserGroupMembersContext", 2)
 }
 
 type apiMock struct {
 	mock.Mock
 }
 
-func (m *apiMock) ListUsers() ([]slack.User, error) {
-	args := m.Called()
+func (m *apiMock) ListUsersContext(ctx context.Context) ([]slack.User, error) {
+	args := m.Called(ctx)
 
 	if err := args.Error(1); err != nil {
 		return nil, err
@@ -76,3 +227,13 @@ func (m *apiMock) ListUsers() ([]slack.User, error) {
 
 	return args.Get(0).([]slack.User), nil
 }
+
+func (m *apiMock) ListUserGroupMembersContext(ctx context.Context, groupID string) ([]string, error) {
+	args := m.Called(ctx, groupID)
+
+	if err := args.Error(1); err != nil {
+		return nil, err
+	}
+
+	return args.Get(0).([]string), nil
+}
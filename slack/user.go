@@ -3,8 +3,18 @@ package slack
 type User struct {
 	ID   string
 	Name string
+	// Deleted is set for deactivated users returned by users.list
+	Deleted bool `json:",omitempty"`
+	// Locale is the language the user has chosen in Slack, i.e. de-DE. It is only returned by users.list
+	// and users.info, and sent in interaction payloads.
+	Locale string `json:",omitempty"`
 }
 
+// String returns the user mention. The name is omitted if unknown, e.g. for users referenced in events.
 func (u User) String() string {
+	if u.Name == "" {
+		return "<@" + u.ID + ">"
+	}
+
 	return "<@" + u.ID + "|" + u.Name + ">"
 }
@@ -15,3 +15,7 @@ func TestUserString(t *testing.T) {
 
 	assert.Equal(t, "<@user1|Test User>", u.String())
 }
+
+func TestUserString_NoName(t *testing.T) {
+	assert.Equal(t, "<@user1>", slack.User{ID: "user1"}.String())
+}
@@ -7,10 +7,15 @@ import (
 )
 
 var (
-	replacer = strings.NewReplacer("<", "&lt;", ">", "&gt;", "&", "&amp;")
+	replacer   = strings.NewReplacer("<", "&lt;", ">", "&gt;", "&", "&amp;")
+	unreplacer = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&")
 
 	escapedLink          = regexp.MustCompile("&lt;(https?://\\S+?)&gt;")                 // escaped URL, i.e. <https://google.com?q=search+term>
 	escapedUserReference = regexp.MustCompile("&lt;(@[A-Z0-9]+\\|[A-Za-z0-9\\._-]+)&gt;") // escaped user reference, i.e. <@U123456|username>
+	// escaped user group reference, i.e. <!subteam^S123456|@group>
+	escapedGroupReference = regexp.MustCompile("&lt;(!subteam\\^[A-Z0-9]+(?:\\|@?[A-Za-z0-9\\._-]+)?)&gt;")
+	// escaped channel reference, i.e. <#C123456|general>
+	escapedChannelReference = regexp.MustCompile("&lt;(#[A-Z0-9]+(?:\\|[A-Za-z0-9\\._-]+)?)&gt;")
 )
 
 func EscapeMessage(s string) string {
@@ -18,6 +23,10 @@ func EscapeMessage(s string) string {
 
 	// restore escaped user references
 	escaped = escapedUserReference.ReplaceAll(escaped, []byte("<${1}>"))
+	escaped = escapedGroupReference.ReplaceAll(escaped, []byte("<${1}>"))
+
+	// restore escaped channel references
+	escaped = escapedChannelReference.ReplaceAll(escaped, []byte("<${1}>"))
 
 	// restore escaped URLs
 	escaped = escapedLink.ReplaceAllFunc(escaped, func(m []byte) []byte {
@@ -30,3 +39,8 @@ func EscapeMessage(s string) string {
 
 	return string(escaped)
 }
+
+// UnescapeMessage reverts the escaping of control characters that Slack applies to the text of received messages.
+func UnescapeMessage(s string) string {
+	return unreplacer.Replace(s)
+}
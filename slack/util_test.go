@@ -9,9 +9,11 @@ import (
 
 func TestEscapeMessage(t *testing.T) {
 	examples := map[string]struct{ Value, Expected string }{
-		"common":   {`"Hello' & <<world>>!`, `"Hello' &amp; &lt;&lt;world&gt;&gt;!`},
-		"user_ref": {"Hello <<@U123456|user1>>!", "Hello &lt;<@U123456|user1>&gt;!"},
-		"link":     {"Check <<https://google.com?q=search+term&source=Chrome>>", "Check &lt;<https://google.com?q=search+term&source=Chrome>&gt;"},
+		"common":      {`"Hello' & <<world>>!`, `"Hello' &amp; &lt;&lt;world&gt;&gt;!`},
+		"user_ref":    {"Hello <<@U123456|user1>>!", "Hello &lt;<@U123456|user1>&gt;!"},
+		"group_ref":   {"Hello <<!subteam^S123456|@group1>>!", "Hello &lt;<!subteam^S123456|@group1>&gt;!"},
+		"channel_ref": {"Hello <<#C123456|general>>!", "Hello &lt;<#C123456|general>&gt;!"},
+		"link":        {"Check <<https://google.com?q=search+term&source=Chrome>>", "Check &lt;<https://google.com?q=search+term&source=Chrome>&gt;"},
 	}
 
 	for name, example := range examples {
@@ -20,3 +22,8 @@ func TestEscapeMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestUnescapeMessage(t *testing.T) {
+	assert.Equal(t, `"Hello' & <<world>>! &lt;`, slack.UnescapeMessage(`"Hello' &amp; &lt;&lt;world&gt;&gt;! &amp;lt;`))
+	assert.Equal(t, "Hello <@U123456|user1> in <#C123456|general>", slack.UnescapeMessage("Hello <@U123456|user1> in <#C123456|general>"))
+}
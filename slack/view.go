@@ -0,0 +1,70 @@
+package slack
+
+// View is a modal dialog or an app home tab. See https://api.slack.com/reference/surfaces/views for details.
+type View struct {
+	Type       string      `json:"type"`
+	CallbackID string      `json:"callback_id,omitempty"`
+	Title      *TextObject `json:"title,omitempty"`
+	Submit     *TextObject `json:"submit,omitempty"`
+	Close      *TextObject `json:"close,omitempty"`
+	Blocks     []Block     `json:"blocks"`
+	// PrivateMetadata is an arbitrary string that is sent back within the view submission payload
+	PrivateMetadata string `json:"private_metadata,omitempty"`
+	// State holds the values of input blocks submitted by user
+	State *ViewState `json:"state,omitempty"`
+}
+
+// ViewState contains values of input elements grouped by block and action IDs.
+type ViewState struct {
+	Values map[string]map[string]InputValue `json:"values"`
+}
+
+// InputValue is the value of an input element submitted by user. Depending on the element type either Value,
+// SelectedOption or SelectedUsers is set.
+type InputValue struct {
+	Type           string   `json:"type"`
+	Value          string   `json:"value,omitempty"`
+	SelectedOption *Option  `json:"selected_option,omitempty"`
+	SelectedUsers  []string `json:"selected_users,omitempty"`
+}
+
+// NewModal returns a modal view with the submit button.
+func NewModal(callbackID, title, submit string, blocks ...Block) View {
+	return View{
+		Type:       "modal",
+		CallbackID: callbackID,
+		Title:      &TextObject{Type: "plain_text", Text: title},
+		Submit:     &TextObject{Type: "plain_text", Text: submit},
+		Blocks:     blocks,
+	}
+}
+
+// NewHomeView returns an app home tab view.
+func NewHomeView(blocks ...Block) View {
+	return View{
+		Type:   "home",
+		Blocks: blocks,
+	}
+}
+
+// Value returns the submitted value of an input element identified by block and action IDs.
+func (s *ViewState) Value(blockID, actionID string) (InputValue, bool) {
+	if s == nil {
+		return InputValue{}, false
+	}
+
+	v, ok := s.Values[blockID][actionID]
+
+	return v, ok
+}
+
+// ViewSubmissionErrors is a response to view submission that shows validation errors next to input blocks.
+type ViewSubmissionErrors struct {
+	ResponseAction string            `json:"response_action"`
+	Errors         map[string]string `json:"errors"`
+}
+
+// NewViewSubmissionErrors returns a response with errors, where keys are input block IDs.
+func NewViewSubmissionErrors(errs map[string]string) ViewSubmissionErrors {
+	return ViewSubmissionErrors{ResponseAction: "errors", Errors: errs}
+}
@@ -1,16 +1,36 @@
 package slack
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"expvar"
 	"fmt"
 	"io/ioutil"
+	"log"
+	"math/rand"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 )
 
 const SlackWebAPIEndpoint = "https://slack.com/api"
 
+const (
+	// DefaultMaxRetries is the number of times a rate-limited request is retried before giving up
+	DefaultMaxRetries = 3
+	// DefaultRetryBackoff is the initial delay before retrying a rate-limited request if Slack did not send Retry-After
+	DefaultRetryBackoff = time.Second
+
+	maxRetryBackoff = 30 * time.Second
+)
+
+// RateLimitedRequests holds the number of requests rejected by Slack with HTTP 429 per Web API method. The counters
+// are published via expvar as slack_rate_limited_requests.
+var RateLimitedRequests = expvar.NewMap("slack_rate_limited_requests")
+
 type WebAPIError struct {
 	Method, URL, Response string
 }
@@ -20,17 +40,26 @@ func (e *WebAPIError) Error() string {
 }
 
 type WebAPI struct {
-	c     *http.Client
-	token string
+	c       *http.Client
+	token   string
+	limiter *rateLimiter
 
 	BaseURL string
+	// MaxRetries is the number of times a request rejected with HTTP 429 is retried
+	MaxRetries int
+	// RetryBackoff is the initial delay between retries that is used if Slack did not send Retry-After header.
+	// The delay is doubled after each attempt and randomized to avoid retrying several requests at once.
+	RetryBackoff time.Duration
 }
 
 func NewWebAPI(token string, httpClient *http.Client) *WebAPI {
 	api := &WebAPI{
-		token:   token,
-		c:       httpClient,
-		BaseURL: SlackWebAPIEndpoint,
+		token:        token,
+		c:            httpClient,
+		limiter:      newRateLimiter(),
+		BaseURL:      SlackWebAPIEndpoint,
+		MaxRetries:   DefaultMaxRetries,
+		RetryBackoff: DefaultRetryBackoff,
 	}
 
 	if api.c == nil {
@@ -40,24 +69,34 @@ func NewWebAPI(token string, httpClient *http.Client) *WebAPI {
 	return api
 }
 
+// SetConversationTopic is like SetConversationTopicContext, but uses the background context.
 func (api *WebAPI) SetConversationTopic(channelID, topic string) error {
+	return api.SetConversationTopicContext(context.Background(), channelID, topic)
+}
+
+func (api *WebAPI) SetConversationTopicContext(ctx context.Context, channelID, topic string) error {
 	const method = "conversations.setTopic"
 
 	params := url.Values{}
 	params.Add("channel", channelID)
 	params.Add("topic", topic)
 
-	_, _, err := api.Call(method, params)
+	_, _, err := api.CallContext(ctx, method, params)
 	return err
 }
 
+// GetChannelTopic is like GetChannelTopicContext, but uses the background context.
 func (api *WebAPI) GetChannelTopic(channelID string) (string, error) {
+	return api.GetChannelTopicContext(context.Background(), channelID)
+}
+
+func (api *WebAPI) GetChannelTopicContext(ctx context.Context, channelID string) (string, error) {
 	const method = "conversations.info"
 
 	params := url.Values{}
 	params.Add("channel", channelID)
 
-	resp, requestURL, err := api.Call(method, params)
+	resp, requestURL, err := api.CallContext(ctx, method, params)
 	if err != nil {
 		return "", err
 	}
@@ -76,57 +115,352 @@ func (api *WebAPI) GetChannelTopic(channelID string) (string, error) {
 	return v.Channel.Topic.Value, nil
 }
 
+// IsPrivateChannel is like IsPrivateChannelContext, but uses the background context.
+func (api *WebAPI) IsPrivateChannel(channelID string) (bool, error) {
+	return api.IsPrivateChannelContext(context.Background(), channelID)
+}
+
+// IsPrivateChannelContext returns true if the channel is private. Private channels the app is not a member of are
+// reported as not found by Slack.
+func (api *WebAPI) IsPrivateChannelContext(ctx context.Context, channelID string) (bool, error) {
+	const method = "conversations.info"
+
+	params := url.Values{}
+	params.Add("channel", channelID)
+
+	resp, requestURL, err := api.CallContext(ctx, method, params)
+	if err != nil {
+		return false, err
+	}
+
+	var v struct {
+		Channel struct {
+			IsPrivate bool `json:"is_private"`
+		} `json:"channel"`
+	}
+	if err := json.Unmarshal(resp, &v); err != nil {
+		return false, wrapError(fmt.Errorf("failed to decode response body %q (%s)", resp, err), method, requestURL)
+	}
+
+	return v.Channel.IsPrivate, nil
+}
+
+// ListUsers is like ListUsersContext, but uses the background context.
 func (api *WebAPI) ListUsers() ([]User, error) {
+	return api.ListUsersContext(context.Background())
+}
+
+// ListUsersContext returns all members of the workspace including deactivated ones.
+func (api *WebAPI) ListUsersContext(ctx context.Context) ([]User, error) {
 	const method = "users.list"
 
-	resp, requestURL, err := api.Call(method, nil)
+	var (
+		users  []User
+		cursor string
+	)
+	for {
+		params := url.Values{}
+		params.Set("limit", "200")
+		params.Set("include_locale", "true")
+
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		resp, requestURL, err := api.CallContext(ctx, method, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var v struct {
+			Members          []User `json:"members"`
+			ResponseMetadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		if err := json.Unmarshal(resp, &v); err != nil {
+			return nil, wrapError(fmt.Errorf("failed to decode response body %q (%s)", resp, err), method, requestURL)
+		}
+
+		users = append(users, v.Members...)
+
+		if v.ResponseMetadata.NextCursor == "" {
+			return users, nil
+		}
+
+		cursor = v.ResponseMetadata.NextCursor
+	}
+}
+
+// ListUserGroupMembers is like ListUserGroupMembersContext, but uses the background context.
+func (api *WebAPI) ListUserGroupMembers(groupID string) ([]string, error) {
+	return api.ListUserGroupMembersContext(context.Background(), groupID)
+}
+
+// ListUserGroupMembersContext returns the IDs of users in the user group.
+func (api *WebAPI) ListUserGroupMembersContext(ctx context.Context, groupID string) ([]string, error) {
+	const method = "usergroups.users.list"
+
+	params := url.Values{}
+	params.Set("usergroup", groupID)
+
+	resp, requestURL, err := api.CallContext(ctx, method, params)
 	if err != nil {
 		return nil, err
 	}
 
 	var v struct {
-		Members []User `json:"members"`
+		Users []string `json:"users"`
 	}
 	if err := json.Unmarshal(resp, &v); err != nil {
 		return nil, wrapError(fmt.Errorf("failed to decode response body %q (%s)", resp, err), method, requestURL)
 	}
 
-	return v.Members, nil
+	return v.Users, nil
+}
+
+// GetUser is like GetUserContext, but uses the background context.
+func (api *WebAPI) GetUser(userID string) (User, error) {
+	return api.GetUserContext(context.Background(), userID)
+}
+
+func (api *WebAPI) GetUserContext(ctx context.Context, userID string) (User, error) {
+	const method = "users.info"
+
+	params := url.Values{}
+	params.Set("user", userID)
+	params.Set("include_locale", "true")
+
+	resp, requestURL, err := api.CallContext(ctx, method, params)
+	if err != nil {
+		return User{}, err
+	}
+
+	var v struct {
+		User User `json:"user"`
+	}
+	if err := json.Unmarshal(resp, &v); err != nil {
+		return User{}, wrapError(fmt.Errorf("failed to decode response body %q (%s)", resp, err), method, requestURL)
+	}
+
+	return v.User, nil
 }
 
+// PostMessage is like PostMessageContext, but uses the background context.
 func (api *WebAPI) PostMessage(channelID string, message Message) error {
+	return api.PostMessageContext(context.Background(), channelID, message)
+}
+
+func (api *WebAPI) PostMessageContext(ctx context.Context, channelID string, message Message) error {
+	_, err := api.PublishMessageContext(ctx, channelID, message)
+	return err
+}
+
+// PublishMessage is like PublishMessageContext, but uses the background context.
+func (api *WebAPI) PublishMessage(channelID string, message Message) (string, error) {
+	return api.PublishMessageContext(context.Background(), channelID, message)
+}
+
+// PublishMessageContext posts a message to channel and returns its timestamp, which can be used to update
+// the message later or to reply in its thread.
+func (api *WebAPI) PublishMessageContext(ctx context.Context, channelID string, message Message) (string, error) {
 	const method = "chat.postMessage"
 
-	params := url.Values{}
+	params, err := messageParams(message)
+	if err != nil {
+		return "", err
+	}
+
 	params.Set("channel", channelID)
-	params.Set("text", message.Text)
 	params.Set("link_names", "1")
 	params.Set("as_user", "true")
 
-	if len(message.Attachments) > 0 {
-		attachments, err := json.Marshal(message.Attachments)
-		if err != nil {
-			return fmt.Errorf("failed to encode attachments for message %s: %s", message.Text, err)
-		}
+	if message.ThreadTS != "" {
+		params.Set("thread_ts", message.ThreadTS)
+	}
 
-		params.Set("attachments", string(attachments))
+	resp, requestURL, err := api.CallContext(ctx, method, params)
+	if err != nil {
+		return "", wrapError(fmt.Errorf("failed to post message %v to channel %s: %s", message, channelID, err), method, requestURL)
+	}
+
+	var v struct {
+		TS string `json:"ts"`
+	}
+	if err := json.Unmarshal(resp, &v); err != nil {
+		return "", wrapError(fmt.Errorf("failed to decode response body %q (%s)", resp, err), method, requestURL)
+	}
+
+	return v.TS, nil
+}
+
+// PostEphemeralMessage is like PostEphemeralMessageContext, but uses the background context.
+func (api *WebAPI) PostEphemeralMessage(channelID string, user User, message Message) error {
+	return api.PostEphemeralMessageContext(context.Background(), channelID, user, message)
+}
+
+// PostEphemeralMessageContext posts a message visible only to user in channel.
+func (api *WebAPI) PostEphemeralMessageContext(ctx context.Context, channelID string, user User, message Message) error {
+	const method = "chat.postEphemeral"
+
+	params, err := messageParams(message)
+	if err != nil {
+		return err
+	}
+
+	params.Set("channel", channelID)
+	params.Set("user", user.ID)
+	params.Set("link_names", "1")
+	params.Set("as_user", "true")
+
+	if message.ThreadTS != "" {
+		params.Set("thread_ts", message.ThreadTS)
+	}
+
+	_, requestURL, err := api.CallContext(ctx, method, params)
+	if err != nil {
+		return wrapError(fmt.Errorf("failed to post ephemeral message %v to %s in channel %s: %s", message, user, channelID, err), method, requestURL)
+	}
+
+	return nil
+}
+
+// OpenView is like OpenViewContext, but uses the background context.
+func (api *WebAPI) OpenView(triggerID string, view View) error {
+	return api.OpenViewContext(context.Background(), triggerID, view)
+}
+
+// OpenViewContext opens a modal in response to the user interaction identified by triggerID.
+func (api *WebAPI) OpenViewContext(ctx context.Context, triggerID string, view View) error {
+	const method = "views.open"
+
+	payload := struct {
+		TriggerID string `json:"trigger_id"`
+		View      View   `json:"view"`
+	}{triggerID, view}
+
+	_, requestURL, err := api.CallJSON(ctx, method, payload)
+	if err != nil {
+		return wrapError(fmt.Errorf("failed to open view %s: %s", view.CallbackID, err), method, requestURL)
+	}
+
+	return nil
+}
+
+// PublishView is like PublishViewContext, but uses the background context.
+func (api *WebAPI) PublishView(userID string, view View) error {
+	return api.PublishViewContext(context.Background(), userID, view)
+}
+
+// PublishViewContext publishes the app home tab view for the user.
+func (api *WebAPI) PublishViewContext(ctx context.Context, userID string, view View) error {
+	const method = "views.publish"
+
+	payload := struct {
+		UserID string `json:"user_id"`
+		View   View   `json:"view"`
+	}{userID, view}
+
+	_, requestURL, err := api.CallJSON(ctx, method, payload)
+	if err != nil {
+		return wrapError(fmt.Errorf("failed to publish %s view for %s: %s", view.Type, userID, err), method, requestURL)
+	}
+
+	return nil
+}
+
+// UpdateMessage is like UpdateMessageContext, but uses the background context.
+func (api *WebAPI) UpdateMessage(channelID, ts string, message Message) error {
+	return api.UpdateMessageContext(context.Background(), channelID, ts, message)
+}
+
+// UpdateMessageContext replaces the text, attachments and blocks of a message posted at ts.
+func (api *WebAPI) UpdateMessageContext(ctx context.Context, channelID, ts string, message Message) error {
+	const method = "chat.update"
+
+	params, err := messageParams(message)
+	if err != nil {
+		return err
 	}
 
-	_, requestURL, err := api.Call(method, params)
+	params.Set("channel", channelID)
+	params.Set("ts", ts)
+	params.Set("link_names", "1")
+	params.Set("as_user", "true")
+
+	_, requestURL, err := api.CallContext(ctx, method, params)
 	if err != nil {
-		return wrapError(fmt.Errorf("failed to post message %v to channel %s: %s", message, channelID, err), method, requestURL)
+		return wrapError(fmt.Errorf("failed to update message %s in channel %s: %s", ts, channelID, err), method, requestURL)
 	}
 
 	return nil
 }
 
+// ListUserChannels is like ListUserChannelsContext, but uses the background context.
+func (api *WebAPI) ListUserChannels(userID string) ([]string, error) {
+	return api.ListUserChannelsContext(context.Background(), userID)
+}
+
+// ListUserChannelsContext returns the IDs of public and private channels the user is a member of.
+func (api *WebAPI) ListUserChannelsContext(ctx context.Context, userID string) ([]string, error) {
+	const method = "users.conversations"
+
+	var (
+		channelIDs []string
+		cursor     string
+	)
+	for {
+		params := url.Values{}
+		params.Set("user", userID)
+		params.Set("types", "public_channel,private_channel")
+		params.Set("exclude_archived", "true")
+		params.Set("limit", "200")
+
+		if cursor != "" {
+			params.Set("cursor", cursor)
+		}
+
+		resp, requestURL, err := api.CallContext(ctx, method, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var v struct {
+			Channels []struct {
+				ID string `json:"id"`
+			} `json:"channels"`
+			ResponseMetadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		if err := json.Unmarshal(resp, &v); err != nil {
+			return nil, wrapError(fmt.Errorf("failed to decode response body %q (%s)", resp, err), method, requestURL)
+		}
+
+		for _, ch := range v.Channels {
+			channelIDs = append(channelIDs, ch.ID)
+		}
+
+		if v.ResponseMetadata.NextCursor == "" {
+			return channelIDs, nil
+		}
+
+		cursor = v.ResponseMetadata.NextCursor
+	}
+}
+
+// OpenIMChannel is like OpenIMChannelContext, but uses the background context.
 func (api *WebAPI) OpenIMChannel(user User) (string, error) {
+	return api.OpenIMChannelContext(context.Background(), user)
+}
+
+func (api *WebAPI) OpenIMChannelContext(ctx context.Context, user User) (string, error) {
 	const method = "conversations.open"
 
 	params := url.Values{}
 	params.Set("users", user.ID)
 
-	resp, requestURL, err := api.Call(method, params)
+	resp, requestURL, err := api.CallContext(ctx, method, params)
 	if err != nil {
 		return "", fmt.Errorf("failed to open an IM message with %s: %s", user, err)
 	}
@@ -143,64 +477,183 @@ func (api *WebAPI) OpenIMChannel(user User) (string, error) {
 	return v.Channel.ID, nil
 }
 
+// Call sends the params of a Web API method as a form body.
 func (api *WebAPI) Call(method string, params url.Values) (response []byte, u *url.URL, err error) {
-	req, err := http.NewRequest("GET", api.BaseURL+"/"+method, nil)
+	return api.CallContext(context.Background(), method, params)
+}
+
+// CallContext calls a Web API method keeping the request rate within the method tier. Requests rejected by Slack
+// with HTTP 429 are retried after the time sent in Retry-After header up to api.MaxRetries times. Waiting for
+// the rate limit or a retry is interrupted once ctx is done.
+func (api *WebAPI) CallContext(ctx context.Context, method string, params url.Values) (response []byte, u *url.URL, err error) {
+	return api.send(ctx, method, "application/x-www-form-urlencoded", []byte(params.Encode()))
+}
+
+// CallJSON calls a Web API method sending payload encoded as JSON. Nested objects, such as views and blocks, are sent
+// as is without being encoded into a string parameter.
+func (api *WebAPI) CallJSON(ctx context.Context, method string, payload interface{}) (response []byte, u *url.URL, err error) {
+	body, err := json.Marshal(payload)
 	if err != nil {
-		return nil, &url.URL{Opaque: api.BaseURL + "/" + method}, wrapError(fmt.Errorf("failed to build WebAPI request (%s)", err), method, nil)
+		u := api.methodURL(method)
+		return nil, u, wrapError(fmt.Errorf("failed to encode request body (%s)", err), method, u)
 	}
 
-	if params == nil {
-		params = url.Values{}
+	return api.send(ctx, method, "application/json; charset=utf-8", body)
+}
+
+func (api *WebAPI) send(ctx context.Context, method, contentType string, body []byte) (response []byte, u *url.URL, err error) {
+	for attempt := 0; ; attempt++ {
+		if err := api.limiter.Wait(ctx, method); err != nil {
+			u := api.methodURL(method)
+			return nil, u, wrapError(fmt.Errorf("failed to call method (%s)", err), method, u)
+		}
+
+		response, requestURL, retryAfter, err := api.call(ctx, method, contentType, body)
+		if retryAfter < 0 {
+			return response, requestURL, err
+		}
+
+		if retryAfter == 0 {
+			retryAfter = api.backoff(attempt)
+		}
+		api.limiter.Pause(method, retryAfter)
+
+		if attempt >= api.MaxRetries {
+			return response, requestURL, err
+		}
+
+		log.Printf("slack: %s is rate limited, retrying in %s", method, retryAfter)
 	}
+}
 
-	params.Add("token", api.token)
-	req.URL.RawQuery = params.Encode()
+// call sends a single POST request to the Web API authenticating it with the bot token. If the request has been
+// rate limited, the returned duration is the delay requested by Slack or zero if there was none, otherwise it is negative.
+func (api *WebAPI) call(ctx context.Context, method, contentType string, body []byte) ([]byte, *url.URL, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.BaseURL+"/"+method, bytes.NewReader(body))
+	if err != nil {
+		u := api.methodURL(method)
+		return nil, u, -1, wrapError(fmt.Errorf("failed to build WebAPI request (%s)", err), method, u)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	if api.token != "" {
+		req.Header.Set("Authorization", "Bearer "+api.token)
+	}
 
 	resp, err := api.c.Do(req)
 	if err != nil {
-		return nil, req.URL, wrapError(fmt.Errorf("failed to call method (%s)", err), method, req.URL)
+		return nil, req.URL, -1, wrapError(fmt.Errorf("failed to call method (%s)", unwrapURLError(err)), method, req.URL)
 	}
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, req.URL, wrapError(fmt.Errorf("failed to read response body (%s)", err), method, req.URL)
+		return nil, req.URL, -1, wrapError(fmt.Errorf("failed to read response body (%s)", err), method, req.URL)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		RateLimitedRequests.Add(method, 1)
+
+		var retryAfter time.Duration
+		if sec, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && sec > 0 {
+			retryAfter = time.Duration(sec) * time.Second
+		}
+
+		return nil, req.URL, retryAfter, wrapError(fmt.Errorf("WebAPI responded with HTTP %d %q", resp.StatusCode, respBody), method, req.URL)
 	}
 
 	if resp.StatusCode >= http.StatusBadRequest {
-		return nil, req.URL, wrapError(fmt.Errorf("WebAPI responded with HTTP %d %q", resp.StatusCode, body), method, req.URL)
+		return nil, req.URL, -1, wrapError(fmt.Errorf("WebAPI responded with HTTP %d %q", resp.StatusCode, respBody), method, req.URL)
 	}
 
 	var v struct {
 		Ok    bool   `json:"ok"`
 		Error string `json:"error"`
 	}
-	if err := json.Unmarshal(body, &v); err != nil {
-		return nil, req.URL, wrapError(fmt.Errorf("failed to decode response body %q (%s)", body, err), method, req.URL)
+	if err := json.Unmarshal(respBody, &v); err != nil {
+		return nil, req.URL, -1, wrapError(fmt.Errorf("failed to decode response body %q (%s)", respBody, err), method, req.URL)
 	}
 
 	if !v.Ok {
 		if v.Error != "" {
-			return nil, req.URL, wrapError(fmt.Errorf("WebAPI returned error (%s)", v.Error), method, req.URL)
+			return nil, req.URL, -1, wrapError(fmt.Errorf("WebAPI returned error (%s)", v.Error), method, req.URL)
 		} else {
-			return nil, req.URL, wrapError(errors.New("WebAPI returned unknown error"), method, req.URL)
+			return nil, req.URL, -1, wrapError(errors.New("WebAPI returned unknown error"), method, req.URL)
+		}
+	}
+
+	return respBody, req.URL, -1, nil
+}
+
+func (api *WebAPI) methodURL(method string) *url.URL {
+	u, err := url.Parse(api.BaseURL + "/" + method)
+	if err != nil {
+		return &url.URL{Path: method}
+	}
+
+	return u
+}
+
+// backoff returns a randomized exponential delay before the next retry attempt.
+func (api *WebAPI) backoff(attempt int) time.Duration {
+	d := api.RetryBackoff << uint(attempt)
+	if d <= 0 || d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func messageParams(message Message) (url.Values, error) {
+	params := url.Values{}
+	params.Set("text", message.Text)
+
+	if len(message.Attachments) > 0 {
+		attachments, err := json.Marshal(message.Attachments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode attachments for message %s: %s", message.Text, err)
 		}
+
+		params.Set("attachments", string(attachments))
 	}
 
-	return body, req.URL, nil
+	if len(message.Blocks) > 0 {
+		blocks, err := json.Marshal(message.Blocks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode blocks for message %s: %s", message.Text, err)
+		}
+
+		params.Set("blocks", string(blocks))
+	}
+
+	return params, nil
 }
 
-func wrapError(err error, method string, url *url.URL) *WebAPIError {
+// wrapError builds a WebAPIError for a failed request. Since the bot token is sent in Authorization header,
+// the request URL does not contain any secrets apart from user credentials that might be part of api.BaseURL.
+func wrapError(err error, method string, u *url.URL) *WebAPIError {
 	e := &WebAPIError{
 		Method:   method,
-		URL:      url.String(),
 		Response: err.Error(),
 	}
 
-	if url != nil {
-		url.Query().Set("token", "[hidden]")
-		e.URL = url.String()
+	if u != nil {
+		redacted := *u
+		if redacted.User != nil {
+			redacted.User = url.User("REDACTED")
+		}
+
+		e.URL = redacted.String()
 	}
 
 	return e
 }
+
+// unwrapURLError strips the request URL from errors returned by http.Client, since it is added by wrapError anyway.
+func unwrapURLError(err error) error {
+	if urlErr, ok := err.(*url.Error); ok {
+		return urlErr.Err
+	}
+
+	return err
+}
@@ -1,11 +1,17 @@
 package slack_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"expvar"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/adjust/michaelbot/slack"
 	"github.com/stretchr/testify/assert"
@@ -20,7 +26,7 @@ func TestWebAPI_Call_WithParams(t *testing.T) {
 	mux.HandleFunc("/methodName", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "value1", r.FormValue("key1"))
 		assert.Equal(t, "value2", r.FormValue("key2"))
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 
 		requestNum++
 		w.Write([]byte(`{"ok":true}`))
@@ -45,7 +51,7 @@ func TestWebAPI_Call_WithoutParams(t *testing.T) {
 
 	var requestNum int
 	mux.HandleFunc("/methodName", func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 
 		requestNum++
 		w.Write([]byte(`{"ok":true}`))
@@ -66,7 +72,7 @@ func TestWebAPI_Call_WebAPIError(t *testing.T) {
 
 	var requestNum int
 	mux.HandleFunc("/methodName", func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 
 		requestNum++
 		w.Write([]byte(`{"ok":false,"error":"an error occurred"}`))
@@ -114,6 +120,180 @@ func TestWebAPI_Call_HTTPError(t *testing.T) {
 	}
 }
 
+func TestWebAPI_Call_RateLimited(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/rateLimited.retryAfter", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+
+		requestNum++
+		if requestNum == 1 {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "", http.StatusTooManyRequests)
+			return
+		}
+
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	start, rateLimited := time.Now(), rateLimitedRequests("rateLimited.retryAfter")
+
+	response, _, err := api.Call("rateLimited.retryAfter", url.Values{})
+	require.NoError(t, err)
+	require.Equal(t, 2, requestNum)
+	assert.Equal(t, `{"ok":true}`, string(response))
+
+	assert.True(t, time.Since(start) >= time.Second)
+	assert.Equal(t, int64(1), rateLimitedRequests("rateLimited.retryAfter")-rateLimited)
+}
+
+func TestWebAPI_Call_RateLimited_MaxRetries(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/rateLimited.backoff", func(w http.ResponseWriter, r *http.Request) {
+		requestNum++
+		http.Error(w, "", http.StatusTooManyRequests)
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+	api.MaxRetries = 2
+	api.RetryBackoff = time.Millisecond
+
+	rateLimited := rateLimitedRequests("rateLimited.backoff")
+
+	_, _, err := api.Call("rateLimited.backoff", url.Values{})
+	require.Equal(t, 3, requestNum)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "HTTP 429")
+	}
+	assert.Equal(t, int64(3), rateLimitedRequests("rateLimited.backoff")-rateLimited)
+}
+
+// rateLimitedRequests returns the number of rate-limited requests to method counted so far by all tests.
+func rateLimitedRequests(method string) int64 {
+	if v, ok := slack.RateLimitedRequests.Get(method).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
+
+func TestWebAPI_CallContext_Cancel(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/rateLimited.cancel", func(w http.ResponseWriter, r *http.Request) {
+		requestNum++
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "", http.StatusTooManyRequests)
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, _, err := api.CallContext(ctx, "rateLimited.cancel", url.Values{})
+	require.Equal(t, 1, requestNum)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+	}
+}
+
+func TestWebAPI_CallContext_RateTier(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/rateLimited.tier1", func(w http.ResponseWriter, r *http.Request) {
+		requestNum++
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	slack.MethodRateTiers["rateLimited.tier1"] = slack.RateTier1
+	defer delete(slack.MethodRateTiers, "rateLimited.tier1")
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	_, _, err := api.Call("rateLimited.tier1", url.Values{})
+	require.NoError(t, err)
+
+	// the second request within a minute has to wait
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, _, err = api.CallContext(ctx, "rateLimited.tier1", url.Values{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, requestNum)
+}
+
+func TestWebAPI_Call_SecretRedaction(t *testing.T) {
+	const token = "xxxx-token-12345"
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/method.httpError", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "An error occurred", http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/method.apiError", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+	})
+	mux.HandleFunc("/method.rateLimited", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "", http.StatusTooManyRequests)
+	})
+
+	api := slack.NewWebAPI(token, nil)
+	api.RetryBackoff = time.Millisecond
+
+	u, err := url.Parse(baseURL)
+	require.NoError(t, err)
+	u.User = url.UserPassword("proxy", "proxy-password")
+	api.BaseURL = u.String()
+
+	for _, method := range []string{"method.httpError", "method.apiError", "method.rateLimited"} {
+		_, requestURL, err := api.Call(method, url.Values{"channel": {"C1"}})
+		if assert.Error(t, err, method) {
+			log.Println(err)
+			assert.NotContains(t, err.Error(), token, method)
+			assert.NotContains(t, err.Error(), "proxy-password", method)
+		}
+
+		assert.Empty(t, requestURL.RawQuery, method)
+	}
+
+	// the request fails before a response is received
+	teardown()
+
+	_, _, err = api.Call("method.unreachable", nil)
+	if assert.Error(t, err) {
+		log.Println(err)
+		assert.NotContains(t, err.Error(), token)
+		assert.NotContains(t, err.Error(), "proxy-password")
+	}
+
+	assert.NotContains(t, logs.String(), token)
+	assert.NotContains(t, logs.String(), "proxy-password")
+	assert.Contains(t, logs.String(), "REDACTED@")
+}
+
 func TestWebAPI_ChannelsSetTopic(t *testing.T) {
 	mux, baseURL, teardown := setup()
 	defer teardown()
@@ -121,7 +301,7 @@ func TestWebAPI_ChannelsSetTopic(t *testing.T) {
 	var requestNum int
 	mux.HandleFunc("/conversations.setTopic", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "CHANNELID1", r.FormValue("channel"))
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 		assert.Equal(t, "Example topic", r.FormValue("topic"))
 
 		requestNum++
@@ -142,7 +322,7 @@ func TestWebAPI_ChannelsSetTopic_ErrorHandling(t *testing.T) {
 	var requestNum int
 	mux.HandleFunc("/conversations.setTopic", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "CHANNELID1", r.FormValue("channel"))
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 		assert.Equal(t, "Example topic", r.FormValue("topic"))
 
 		requestNum++
@@ -164,7 +344,7 @@ func TestWebAPI_ChannelsGetTopic(t *testing.T) {
 	var requestNum int
 	mux.HandleFunc("/conversations.info", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "CHANNELID1", r.FormValue("channel"))
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 
 		requestNum++
 		w.Write([]byte(`{"ok":true,"channel":{"topic":{"value":"Example topic"}}}`))
@@ -187,7 +367,7 @@ func TestWebAPI_ChannelsGetTopic_ErrorHandling(t *testing.T) {
 	var requestNum int
 	mux.HandleFunc("/conversations.info", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "CHANNELID1", r.FormValue("channel"))
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 
 		requestNum++
 		w.Write([]byte(`{"ok":false,"error":"channel not found"}`))
@@ -201,16 +381,57 @@ func TestWebAPI_ChannelsGetTopic_ErrorHandling(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestWebAPI_IsPrivateChannel(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	mux.HandleFunc("/conversations.info", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+
+		switch r.FormValue("channel") {
+		case "C1":
+			w.Write([]byte(`{"ok":true,"channel":{"id":"C1","is_private":false}}`))
+		case "G1":
+			w.Write([]byte(`{"ok":true,"channel":{"id":"G1","is_private":true}}`))
+		default:
+			w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+		}
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	private, err := api.IsPrivateChannel("C1")
+	require.NoError(t, err)
+	assert.False(t, private)
+
+	private, err = api.IsPrivateChannel("G1")
+	require.NoError(t, err)
+	assert.True(t, private)
+
+	_, err = api.IsPrivateChannel("G2")
+	assert.Error(t, err)
+}
+
 func TestWebAPI_ListUsers(t *testing.T) {
 	mux, baseURL, teardown := setup()
 	defer teardown()
 
 	var requestNum int
 	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+
+		assert.Equal(t, "true", r.FormValue("include_locale"))
 
 		requestNum++
-		w.Write([]byte(`{"ok":true,"members":[{"id":"U1","name":"user1"},{"id":"U2","name":"user2"}]}`))
+		switch r.FormValue("cursor") {
+		case "":
+			w.Write([]byte(`{"ok":true,"members":[{"id":"U1","name":"user1"},{"id":"U2","name":"user2","locale":"de-DE"}],"response_metadata":{"next_cursor":"page2"}}`))
+		case "page2":
+			w.Write([]byte(`{"ok":true,"members":[{"id":"U3","name":"user3","deleted":true}],"response_metadata":{"next_cursor":""}}`))
+		default:
+			t.Errorf("unexpected cursor %q", r.FormValue("cursor"))
+		}
 	})
 
 	api := slack.NewWebAPI("xxxx-token-12345", nil)
@@ -218,11 +439,12 @@ func TestWebAPI_ListUsers(t *testing.T) {
 
 	users, err := api.ListUsers()
 	require.NoError(t, err)
-	require.Equal(t, 1, requestNum)
+	require.Equal(t, 2, requestNum)
 
-	if assert.Len(t, users, 2) {
+	if assert.Len(t, users, 3) {
 		assert.Contains(t, users, slack.User{ID: "U1", Name: "user1"})
-		assert.Contains(t, users, slack.User{ID: "U2", Name: "user2"})
+		assert.Contains(t, users, slack.User{ID: "U2", Name: "user2", Locale: "de-DE"})
+		assert.Contains(t, users, slack.User{ID: "U3", Name: "user3", Deleted: true})
 	}
 }
 
@@ -232,7 +454,7 @@ func TestWebAPI_ListUsers_ErrorHandling(t *testing.T) {
 
 	var requestNum int
 	mux.HandleFunc("/users.list", func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 
 		requestNum++
 		w.Write([]byte(`{"ok":false,"error":"no users"`))
@@ -246,6 +468,72 @@ func TestWebAPI_ListUsers_ErrorHandling(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestWebAPI_ListUserGroupMembers(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/usergroups.users.list", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+		assert.Equal(t, "S1", r.FormValue("usergroup"))
+
+		requestNum++
+		w.Write([]byte(`{"ok":true,"users":["U1","U2"]}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	members, err := api.ListUserGroupMembers("S1")
+	require.NoError(t, err)
+	require.Equal(t, 1, requestNum)
+
+	assert.Equal(t, []string{"U1", "U2"}, members)
+}
+
+func TestWebAPI_GetUser(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/users.info", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+		assert.Equal(t, "U1", r.FormValue("user"))
+		assert.Equal(t, "true", r.FormValue("include_locale"))
+
+		requestNum++
+		w.Write([]byte(`{"ok":true,"user":{"id":"U1","name":"user1","real_name":"User One","locale":"es-ES"}}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	user, err := api.GetUser("U1")
+	require.NoError(t, err)
+	require.Equal(t, 1, requestNum)
+	assert.Equal(t, slack.User{ID: "U1", Name: "user1", Locale: "es-ES"}, user)
+}
+
+func TestWebAPI_PostMessage_InThread(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "channel1", r.FormValue("channel"))
+		assert.Equal(t, "1234567890.123456", r.FormValue("thread_ts"))
+
+		requestNum++
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	require.NoError(t, api.PostMessage("channel1", slack.Message{Text: "Test message", ThreadTS: "1234567890.123456"}))
+	assert.Equal(t, 1, requestNum)
+}
+
 func TestWebAPI_PostMessage_WithoutAttachments(t *testing.T) {
 	mux, baseURL, teardown := setup()
 	defer teardown()
@@ -254,7 +542,7 @@ func TestWebAPI_PostMessage_WithoutAttachments(t *testing.T) {
 
 	var requestNum int
 	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 		assert.Equal(t, "channel1", r.FormValue("channel"))
 		assert.Equal(t, "1", r.FormValue("link_names"))
 		assert.Equal(t, "true", r.FormValue("as_user"))
@@ -285,7 +573,7 @@ func TestWebAPI_PostMessage_WithAttachments(t *testing.T) {
 
 	var requestNum int
 	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 		assert.Equal(t, "channel1", r.FormValue("channel"))
 		assert.Equal(t, "1", r.FormValue("link_names"))
 		assert.Equal(t, "true", r.FormValue("as_user"))
@@ -308,6 +596,234 @@ func TestWebAPI_PostMessage_WithAttachments(t *testing.T) {
 	assert.Equal(t, 1, requestNum)
 }
 
+func TestWebAPI_PostMessage_WithBlocks(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	message := slack.Message{
+		Text: "Test message",
+		Blocks: []slack.Block{
+			slack.NewSectionBlock("Test *message*"),
+			slack.NewActionsBlock("block1", slack.NewButton("action1", "Click me", "value1", slack.ButtonStylePrimary)),
+		},
+	}
+
+	var requestNum int
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, message.Text, r.FormValue("text"))
+		assert.Empty(t, r.FormValue("attachments"))
+
+		if encodedBlocks := r.FormValue("blocks"); assert.NotEmpty(t, encodedBlocks) {
+			var blocks []slack.Block
+			require.NoError(t, json.Unmarshal([]byte(encodedBlocks), &blocks))
+			assert.Equal(t, message.Blocks, blocks)
+		}
+
+		requestNum++
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	require.NoError(t, api.PostMessage("channel1", message))
+	assert.Equal(t, 1, requestNum)
+}
+
+func TestWebAPI_PublishMessage(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "channel1", r.FormValue("channel"))
+		assert.Equal(t, "Test message", r.FormValue("text"))
+
+		requestNum++
+		w.Write([]byte(`{"ok":true,"channel":"channel1","ts":"1503435956.000247"}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	ts, err := api.PublishMessage("channel1", slack.Message{Text: "Test message"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, requestNum)
+	assert.Equal(t, "1503435956.000247", ts)
+}
+
+func TestWebAPI_PublishMessageContext_Cancel(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/chat.postMessage", func(w http.ResponseWriter, r *http.Request) {
+		requestNum++
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "", http.StatusTooManyRequests)
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := api.PublishMessageContext(ctx, "channel1", slack.Message{Text: "Test message"})
+	require.Equal(t, 1, requestNum)
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+	}
+}
+
+func TestWebAPI_UpdateMessage(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	message := slack.Message{
+		Text:   "Updated message",
+		Blocks: []slack.Block{slack.NewSectionBlock("Updated message")},
+	}
+
+	var requestNum int
+	mux.HandleFunc("/chat.update", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+		assert.Equal(t, "channel1", r.FormValue("channel"))
+		assert.Equal(t, "1503435956.000247", r.FormValue("ts"))
+		assert.Equal(t, message.Text, r.FormValue("text"))
+
+		if encodedBlocks := r.FormValue("blocks"); assert.NotEmpty(t, encodedBlocks) {
+			var blocks []slack.Block
+			require.NoError(t, json.Unmarshal([]byte(encodedBlocks), &blocks))
+			assert.Equal(t, message.Blocks, blocks)
+		}
+
+		requestNum++
+		w.Write([]byte(`{"ok":true,"channel":"channel1","ts":"1503435956.000247"}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	require.NoError(t, api.UpdateMessage("channel1", "1503435956.000247", message))
+	assert.Equal(t, 1, requestNum)
+}
+
+func TestWebAPI_PostEphemeralMessage(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/chat.postEphemeral", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+		assert.Equal(t, "channel1", r.FormValue("channel"))
+		assert.Equal(t, "U1", r.FormValue("user"))
+		assert.Equal(t, "Test message", r.FormValue("text"))
+		assert.Equal(t, "1502210682.580145", r.FormValue("thread_ts"))
+
+		requestNum++
+		w.Write([]byte(`{"ok":true,"message_ts":"1502210682.580145"}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	require.NoError(t, api.PostEphemeralMessage("channel1", slack.User{ID: "U1", Name: "user1"}, slack.Message{Text: "Test message", ThreadTS: "1502210682.580145"}))
+	assert.Equal(t, 1, requestNum)
+}
+
+func TestWebAPI_OpenView(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	view := slack.NewModal("modal1", "Title", "Submit", slack.NewInputBlock("block1", "Label", slack.NewTextInput("action1", "", false), false))
+
+	var requestNum int
+	mux.HandleFunc("/views.open", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json; charset=utf-8", r.Header.Get("Content-Type"))
+
+		var payload struct {
+			TriggerID string     `json:"trigger_id"`
+			View      slack.View `json:"view"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "trigger1", payload.TriggerID)
+		assert.Equal(t, view, payload.View)
+
+		requestNum++
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	require.NoError(t, api.OpenView("trigger1", view))
+	assert.Equal(t, 1, requestNum)
+}
+
+func TestWebAPI_PublishView(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	view := slack.NewHomeView(slack.NewSectionBlock("Hello"))
+
+	var requestNum int
+	mux.HandleFunc("/views.publish", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json; charset=utf-8", r.Header.Get("Content-Type"))
+
+		var payload struct {
+			UserID string     `json:"user_id"`
+			View   slack.View `json:"view"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "U1", payload.UserID)
+		assert.Equal(t, view, payload.View)
+
+		requestNum++
+		w.Write([]byte(`{"ok":true}`))
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	require.NoError(t, api.PublishView("U1", view))
+	assert.Equal(t, 1, requestNum)
+}
+
+func TestWebAPI_ListUserChannels(t *testing.T) {
+	mux, baseURL, teardown := setup()
+	defer teardown()
+
+	var requestNum int
+	mux.HandleFunc("/users.conversations", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
+		assert.Equal(t, "U1", r.FormValue("user"))
+		assert.Equal(t, "public_channel,private_channel", r.FormValue("types"))
+
+		requestNum++
+		switch r.FormValue("cursor") {
+		case "":
+			w.Write([]byte(`{"ok":true,"channels":[{"id":"C1"},{"id":"C2"}],"response_metadata":{"next_cursor":"page2"}}`))
+		case "page2":
+			w.Write([]byte(`{"ok":true,"channels":[{"id":"C3"}],"response_metadata":{"next_cursor":""}}`))
+		default:
+			t.Errorf("unexpected cursor %q", r.FormValue("cursor"))
+		}
+	})
+
+	api := slack.NewWebAPI("xxxx-token-12345", nil)
+	api.BaseURL = baseURL
+
+	channelIDs, err := api.ListUserChannels("U1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, requestNum)
+
+	assert.Equal(t, []string{"C1", "C2", "C3"}, channelIDs)
+}
+
 func TestWebAPI_OpenIMChannel(t *testing.T) {
 	mux, baseURL, teardown := setup()
 	defer teardown()
@@ -316,7 +832,7 @@ func TestWebAPI_OpenIMChannel(t *testing.T) {
 
 	var requestNum int
 	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 		assert.Equal(t, user.ID, r.FormValue("users"))
 
 		requestNum++
@@ -341,7 +857,7 @@ func TestWebAPI_OpenIMChannel_ErrorHandling(t *testing.T) {
 
 	var requestNum int
 	mux.HandleFunc("/conversations.open", func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "xxxx-token-12345", r.FormValue("token"))
+		assert.Equal(t, "Bearer xxxx-token-12345", r.Header.Get("Authorization"))
 		assert.Equal(t, user.ID, r.FormValue("users"))
 
 		requestNum++